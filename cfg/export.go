@@ -0,0 +1,164 @@
+package cfg
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Graph is written as text of the diagram, the file gets the extension of the format
+type Format struct {
+	Extension string
+	Export    func(graph *Graph) string
+}
+
+var formats = map[string]Format{
+	"dot":     {"dot", (*Graph).DOT},
+	"mermaid": {"mmd", (*Graph).Mermaid},
+}
+
+func GetFormat(name string) (Format, bool) {
+	format, status := formats[name]
+	return format, status
+}
+
+// Names of all formats in alphabetical order
+func Formats() []string {
+	var names []string
+
+	for name := range formats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Graphviz digraph, statements of a block are left-aligned lines of a box:
+//   digraph "max" {
+//     node [fontname="monospace"];
+//     b0 [shape=oval, label="max(a, b)"];
+//     b1 [shape=diamond, label="a > b"];
+//     b2 [shape=box, label="return a\l"];
+//     b0 -> b1;
+//     b1 -> b2 [label="true"];
+//     ...
+//   }
+func (graph *Graph) DOT() string {
+	var builder strings.Builder
+
+	builder.WriteString("digraph " + dotString(graph.Name) + " {\n")
+	builder.WriteString("  node [fontname=\"monospace\"];\n")
+
+	for _, block := range graph.Blocks {
+		var shape, label string
+
+		switch block.Kind {
+		case Entry, Exit:
+			shape, label = "oval", dotString(blockLabel(block))
+		case Condition:
+			shape, label = "diamond", dotString(blockLabel(block))
+		default:
+			shape, label = "box", "\""
+			for _, stmt := range block.Statements {
+				label += dotEscape(stmt) + "\\l"
+			}
+			label += "\""
+		}
+
+		builder.WriteString("  " + blockName(block) + " [shape=" + shape + ", label=" + label + "];\n")
+	}
+
+	for _, block := range graph.Blocks {
+		for _, edge := range block.Successors {
+			builder.WriteString("  " + blockName(block) + " -> " + blockName(edge.To))
+
+			if edge.Label != "" {
+				builder.WriteString(" [label=" + dotString(edge.Label) + "]")
+			}
+
+			builder.WriteString(";\n")
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+// Mermaid flowchart from top to bottom, lines of a block are separated with <br/>:
+//   flowchart TD
+//     b0(["max(a, b)"])
+//     b1{"a #gt; b"}
+//     b2["return a"]
+//     b0 --> b1
+//     b1 -->|true| b2
+//     ...
+func (graph *Graph) Mermaid() string {
+	var builder strings.Builder
+
+	builder.WriteString("flowchart TD\n")
+
+	for _, block := range graph.Blocks {
+		var lines []string
+
+		for _, line := range strings.Split(blockLabel(block), "\n") {
+			lines = append(lines, mermaidEscape(line))
+		}
+
+		label := "\"" + strings.Join(lines, "<br/>") + "\""
+
+		switch block.Kind {
+		case Entry, Exit:
+			label = "([" + label + "])"
+		case Condition:
+			label = "{" + label + "}"
+		default:
+			label = "[" + label + "]"
+		}
+
+		builder.WriteString("  " + blockName(block) + label + "\n")
+	}
+
+	for _, block := range graph.Blocks {
+		for _, edge := range block.Successors {
+			arrow := " --> "
+
+			if edge.Label != "" {
+				arrow = " -->|" + edge.Label + "| "
+			}
+
+			builder.WriteString("  " + blockName(block) + arrow + blockName(edge.To) + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+func blockName(block *Block) string {
+	return "b" + strconv.Itoa(block.Index)
+}
+
+// Exit has no statements, it's written as end
+func blockLabel(block *Block) string {
+	if block.Kind == Exit {
+		return "end"
+	}
+
+	return strings.Join(block.Statements, "\n")
+}
+
+func dotString(str string) string {
+	return "\"" + dotEscape(str) + "\""
+}
+
+// Strings of the code keep their escapes: "a\n" is written as "a\\n"
+func dotEscape(str string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(str)
+}
+
+// Characters which Mermaid reads as markup are written as entity codes
+func mermaidEscape(str string) string {
+	return strings.NewReplacer("#", "#35;", "\"", "#quot;", "<", "#lt;", ">", "#gt;", "&", "#amp;").Replace(str)
+}
@@ -0,0 +1,299 @@
+package cfg
+
+import (
+	"../parser"
+	"../printer"
+	"strings"
+)
+
+// Control-flow graph of a function, drawn as a flowchart:
+//   max(a, b) -> a > b -true-> return a -> end
+//                      -false-> return b -> end
+// Blocks are numbered in order of the code: entry is the first one, exit is the last one.
+// Blocks which can't be reached from entry aren't in the graph.
+type Graph struct {
+	Name   string
+	Blocks []*Block
+}
+
+type BlockKind int
+
+const (
+	Entry     BlockKind = iota // Header of the function
+	Exit                       // End of the function, returns lead there
+	Basic                      // Statements executed one after another
+	Condition                  // Branch on the condition, edges are labeled true and false
+)
+
+type Block struct {
+	Index      int
+	Kind       BlockKind
+	Statements []string // Go code of statements, the only one is the condition for Condition
+	Successors []Edge
+}
+
+// Label is true or false after condition, empty otherwise
+type Edge struct {
+	To    *Block
+	Label string
+}
+
+func (graph *Graph) Entry() *Block {
+	return graph.Blocks[0]
+}
+
+func (graph *Graph) Exit() *Block {
+	return graph.Blocks[len(graph.Blocks)-1]
+}
+
+func (graph *Graph) newBlock(kind BlockKind) *Block {
+	return graph.add(&Block{Kind: kind})
+}
+
+// Blocks which are jumped to before their code is reached are added later, so numbers follow the code
+func (graph *Graph) add(block *Block) *Block {
+	block.Index = len(graph.Blocks)
+	graph.Blocks = append(graph.Blocks, block)
+
+	return block
+}
+
+func (block *Block) addEdge(to *Block, label string) {
+	block.Successors = append(block.Successors, Edge{to, label})
+}
+
+// Graphs of all functions in declaration order
+func BuildAll(tree parser.File) []*Graph {
+	var graphs []*Graph
+
+	for _, declaration := range tree.Declarations {
+		if decl, status := declaration.(parser.FuncDeclaration); status {
+			graphs = append(graphs, Build(decl))
+		}
+	}
+
+	return graphs
+}
+
+// Function is built from the code without errors, since it's analyzed before
+func Build(decl parser.FuncDeclaration) *Graph {
+	graph := &Graph{Name: decl.Name.Name}
+	entry := graph.newBlock(Entry)
+	entry.Statements = []string{signature(decl)}
+
+	builder := &builder{graph: graph, current: graph.newBlock(Basic), exit: &Block{Kind: Exit}}
+	entry.addEdge(builder.current, "")
+	builder.buildStatement(decl.Body)
+	builder.jump(builder.exit)
+	graph.add(builder.exit)
+	graph.simplify()
+
+	return graph
+}
+
+// Entry is written as the call of the function: max(a, b)
+func signature(decl parser.FuncDeclaration) string {
+	var names []string
+
+	for _, param := range decl.Parameters {
+		for _, name := range param.Names {
+			names = append(names, name.Name)
+		}
+	}
+
+	return decl.Name.Name + "(" + strings.Join(names, ", ") + ")"
+}
+
+// Blocks are added while statements are visited. Block after return or branch
+// has no edges to it, so the statements after them are removed with it.
+type builder struct {
+	graph   *Graph
+	current *Block   // Block which statements are added to
+	exit    *Block
+	breaks  []*Block // Blocks after enclosing switches, break leads to the last one
+}
+
+func (builder *builder) buildStatement(statement parser.Statement) {
+	if stmt, status := statement.(parser.BlockStatement); status {               // Block
+		builder.buildStatement(stmt.Statements)
+	} else if stmts, status := statement.(parser.Statements); status {          // Statements
+		for _, stmt := range stmts {
+			builder.buildStatement(stmt)
+		}
+	} else if stmt, status := statement.(parser.IfStatement); status {          // If
+		builder.buildIfStatement(stmt)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {      // Switch
+		builder.buildSwitchStatement(stmt)
+	} else if stmt, status := statement.(parser.ReturnStatement); status {      // Return
+		builder.add(stmt)
+		builder.jump(builder.exit)
+	} else if stmt, status := statement.(parser.BranchStatement); status {      // Branch
+		// There are no loops, so both break and continue leave the enclosing switch.
+		// Branch outside of switch leaves the function.
+		target := builder.exit
+
+		if len(builder.breaks) > 0 {
+			target = builder.breaks[len(builder.breaks)-1]
+		}
+
+		builder.add(stmt)
+		builder.jump(target)
+	} else {
+		builder.add(statement)
+	}
+}
+
+//   if a > b {      a > b -true-> c = a -> ...
+//     c = a               -false-> c = b -> ...
+//   } else {
+//     c = b
+//   }
+func (builder *builder) buildIfStatement(stmt parser.IfStatement) {
+	after := &Block{Kind: Basic}
+	condition := builder.condition(stmt.Condition)
+
+	builder.current = builder.successor(condition, "true")
+	builder.buildStatement(stmt.IfBody)
+	builder.jump(after)
+
+	builder.current = builder.successor(condition, "false")
+	builder.buildStatement(stmt.ElseBody)
+	builder.jump(after)
+
+	builder.current = builder.graph.add(after)
+}
+
+// Cases are checked one after another, default is taken when none of them matches:
+//   switch x {          x == 1 -true-> a = 1 -> ...
+//   case 1: a = 1              -false-> x == 2 -true-> a = 2 -> ...
+//   case 2: a = 2                              -false-> a = 0 -> ...
+//   default: a = 0
+//   }
+// Switch without tag checks the cases as conditions.
+func (builder *builder) buildSwitchStatement(stmt parser.SwitchStatement) {
+	after := &Block{Kind: Basic}
+	builder.breaks = append(builder.breaks, after)
+
+	var defaultCase *parser.CaseStatement
+	next := builder.current
+
+	for i, caseStmt := range stmt.Body {
+		if isExpressionNil(caseStmt.Expression) {
+			defaultCase = &stmt.Body[i]
+			continue
+		}
+
+		builder.current = next
+		condition := builder.condition(caseCondition(stmt.Expression, caseStmt.Expression))
+
+		builder.current = builder.successor(condition, "true")
+		builder.buildStatement(caseStmt.Body)
+		builder.jump(after)
+
+		next = builder.successor(condition, "false")
+	}
+
+	builder.current = next
+
+	if defaultCase != nil {
+		builder.buildStatement(defaultCase.Body)
+	}
+
+	builder.jump(after)
+	builder.breaks = builder.breaks[:len(builder.breaks)-1]
+	builder.current = builder.graph.add(after)
+}
+
+func (builder *builder) add(statement parser.Statement) {
+	builder.current.Statements = append(builder.current.Statements, printer.Statement(statement))
+}
+
+// Current block is followed by the check of the condition
+func (builder *builder) condition(expression parser.Expression) *Block {
+	condition := builder.graph.newBlock(Condition)
+	condition.Statements = []string{printer.Expression(expression)}
+	builder.current.addEdge(condition, "")
+
+	return condition
+}
+
+// New block taken from the condition
+func (builder *builder) successor(condition *Block, label string) *Block {
+	block := builder.graph.newBlock(Basic)
+	condition.addEdge(block, label)
+
+	return block
+}
+
+// Current block ends with the jump, code after it is unreachable until another edge leads there
+func (builder *builder) jump(to *Block) {
+	builder.current.addEdge(to, "")
+	builder.current = builder.graph.newBlock(Basic)
+}
+
+// Case is compared with tag, switch without tag has conditions as cases
+func caseCondition(tag parser.Expression, expression parser.Expression) parser.Expression {
+	if isExpressionNil(tag) {
+		return expression
+	}
+
+	return parser.BinaryExpression{LeftOperand: tag, Operator: parser.GetType(parser.Eq), RightOperand: expression}
+}
+
+// Default case and switch without tag have no expression
+func isExpressionNil(expression parser.Expression) bool {
+	if expr, status := expression.(parser.UnaryExpression); status {
+		return expr.Operand == nil
+	}
+
+	return expression == nil
+}
+
+// Empty blocks which only lead to another one are skipped,
+// blocks which can't be reached are removed and the rest are numbered again.
+func (graph *Graph) simplify() {
+	for _, block := range graph.Blocks {
+		for i, edge := range block.Successors {
+			block.Successors[i] = skipEmpty(edge)
+		}
+	}
+
+	reached := map[*Block]bool{}
+	graph.reach(graph.Entry(), reached)
+
+	blocks := []*Block{}
+
+	for _, block := range graph.Blocks {
+		if reached[block] || block.Kind == Exit {
+			block.Index = len(blocks)
+			blocks = append(blocks, block)
+		}
+	}
+
+	graph.Blocks = blocks
+}
+
+// Edge to the block which is taken after the empty ones, it keeps its label
+func skipEmpty(edge Edge) Edge {
+	for isEmpty(edge.To) {
+		edge.To = edge.To.Successors[0].To
+	}
+
+	return edge
+}
+
+func isEmpty(block *Block) bool {
+	return block.Kind == Basic && len(block.Statements) == 0 && len(block.Successors) == 1
+}
+
+func (graph *Graph) reach(block *Block, reached map[*Block]bool) {
+	if reached[block] {
+		return
+	}
+
+	reached[block] = true
+
+	for _, edge := range block.Successors {
+		graph.reach(edge.To, reached)
+	}
+}
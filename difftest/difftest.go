@@ -0,0 +1,165 @@
+package difftest
+
+import (
+	"../evaluator"
+	"../interpreter"
+	"../lwiqa"
+	"../translator"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Result of running the code and its LWIQA translation with the same input
+type Result struct {
+	Input       string
+	Output      string   // Output of the code
+	Differences []string // Empty if both runs give the same output and variables
+	Stopped     bool     // Both runs stop with an error, it's the last difference
+}
+
+// Code is run by the reference evaluator and its translation is run by LWIQA interpreter.
+// Runs are compared by their output, errors which stop them
+// and final values of variables declared in the body of main.
+// LWIQA has variables of inner blocks of main as well, they aren't compared.
+// Errors of the code are returned the same way they are written by the translator.
+func Compare(code string, inputs []string, options translator.Options) ([]Result, string) {
+	ast, errors := translator.SyntaxTree(code, options)
+
+	if errors != "" {
+		return nil, errors
+	}
+
+	text, errors := translator.LWIQAProgram(code, options)
+
+	if errors != "" {
+		return nil, errors
+	}
+
+	program, parseErr := lwiqa.Parse(text)
+
+	if len(inputs) == 0 {
+		inputs = []string{""}
+	}
+
+	var results []Result
+
+	for _, input := range inputs {
+		result := Result{Input: input}
+
+		if parseErr != nil {
+			result.Differences = []string{"generated LWIQA can't be read: " + parseErr.Error()}
+			results = append(results, result)
+			continue
+		}
+
+		var goOut, lwiqaOut bytes.Buffer
+		goEvaluator := evaluator.NewEvaluator(ast, strings.NewReader(input), &goOut, evaluator.Options{})
+		goVariables, goErr := goEvaluator.Run()
+		lwiqaInterpreter := interpreter.NewInterpreter(program, strings.NewReader(input), &lwiqaOut, interpreter.Options{})
+		lwiqaVariables, lwiqaErr := lwiqaInterpreter.Run()
+
+		result.Output = goOut.String()
+
+		if line, goLine, lwiqaLine, status := firstDifference(goOut.String(), lwiqaOut.String()); status {
+			result.Differences = append(result.Differences,
+				fmt.Sprintf("output differs at line %d: Go %q, LWIQA %q", line, goLine, lwiqaLine))
+		}
+
+		if goErr != nil && lwiqaErr == nil {
+			result.Differences = append(result.Differences, "Go stops with error, LWIQA doesn't: "+goErr.Error())
+		} else if goErr == nil && lwiqaErr != nil {
+			result.Differences = append(result.Differences, "LWIQA stops with error, Go doesn't: "+lwiqaErr.Error())
+		} else if goErr != nil {
+			result.Stopped = true
+			result.Differences = append(result.Differences,
+				"both stop with error: Go "+goErr.Error()+", LWIQA "+lwiqaErr.Error())
+		} else {
+			result.Differences = append(result.Differences, compareVariables(goVariables, lwiqaVariables)...)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, ""
+}
+
+// Fixture is compared the same way as any code, its output is checked too, if it's given:
+//   output differs from expected at line 1: expected "3\n", Go "4\n"
+// Runs with inputs where the fixture stops with an error aren't different if both of them stop.
+func CompareFixture(fixture Fixture, code string, options translator.Options) ([]Result, string) {
+	results, errors := Compare(code, fixture.Inputs, options)
+
+	for i := range results {
+		if fixture.stops(results[i].Input) {
+			if results[i].Stopped {
+				results[i].Differences = results[i].Differences[:len(results[i].Differences)-1]
+			} else {
+				results[i].Differences = append(results[i].Differences, "both runs should stop with error")
+			}
+		}
+
+		if i >= len(fixture.Outputs) {
+			continue
+		}
+
+		if line, expected, output, status := firstDifference(fixture.Outputs[i], results[i].Output); status {
+			results[i].Differences = append(results[i].Differences,
+				fmt.Sprintf("output differs from expected at line %d: expected %q, Go %q", line, expected, output))
+		}
+	}
+
+	return results, errors
+}
+
+// Line numbers start from 1, missing line is shown as empty one
+func firstDifference(x string, y string) (int, string, string, bool) {
+	if x == y {
+		return 0, "", "", false
+	}
+
+	xLines, yLines := strings.SplitAfter(x, "\n"), strings.SplitAfter(y, "\n")
+
+	for i := 0; ; i++ {
+		var xLine, yLine string
+
+		if i < len(xLines) {
+			xLine = xLines[i]
+		}
+
+		if i < len(yLines) {
+			yLine = yLines[i]
+		}
+
+		if xLine != yLine {
+			return i + 1, xLine, yLine, true
+		}
+	}
+}
+
+// Variables are compared by the way they're printed, strings are quoted:
+//   x: Go 3, LWIQA 4
+func compareVariables(goVariables map[string]evaluator.Value, lwiqaVariables map[string]interpreter.Value) []string {
+	var names []string
+
+	for name := range goVariables {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	var differences []string
+
+	for _, name := range names {
+		goValue := interpreter.FormatValue(goVariables[name])
+		lwiqaValue, status := lwiqaVariables[name]
+
+		if !status {
+			differences = append(differences, "variable "+name+" is missing in LWIQA")
+		} else if formatted := interpreter.FormatValue(lwiqaValue); formatted != goValue {
+			differences = append(differences, fmt.Sprintf("%s: Go %s, LWIQA %s", name, goValue, formatted))
+		}
+	}
+
+	return differences
+}
@@ -0,0 +1,86 @@
+package difftest
+
+import (
+	"../translator"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// Fixtures are listed relative to the root of the repository
+func readFixture(t *testing.T, fixture Fixture) string {
+	code, err := ioutil.ReadFile(filepath.Join("..", fixture.File))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(code)
+}
+
+func TestExpectedOutputs(t *testing.T) {
+	for _, fixture := range Fixtures {
+		if fixture.Outputs == nil {
+			continue
+		}
+
+		results, errors := CompareFixture(fixture, readFixture(t, fixture), translator.Options{})
+
+		if errors != "" {
+			t.Errorf("%s has errors:\n%s", fixture.File, errors)
+		}
+
+		if len(results) != len(fixture.Outputs) {
+			t.Errorf("%s: %d runs for %d expected outputs", fixture.File, len(results), len(fixture.Outputs))
+		}
+
+		for _, result := range results {
+			for _, difference := range result.Differences {
+				t.Errorf("%s, input %q: %s", fixture.File, result.Input, difference)
+			}
+		}
+	}
+}
+
+// Programs with errors which should stay rejected, all other fixtures should give the same runs
+var rejected = map[string]bool{
+	"test4.notgo":  true,
+	"test5.notgo":  true,
+	"test18.notgo": true,
+}
+
+func TestFixtures(t *testing.T) {
+	for _, options := range []translator.Options{{}, {NoOptimization: true}} {
+		for _, fixture := range Fixtures {
+			results, errors := CompareFixture(fixture, readFixture(t, fixture), options)
+
+			if rejected[fixture.File] && errors == "" {
+				t.Errorf("%s isn't rejected", fixture.File)
+			} else if !rejected[fixture.File] && errors != "" {
+				t.Errorf("%s has errors:\n%s", fixture.File, errors)
+			}
+
+			for _, result := range results {
+				for _, difference := range result.Differences {
+					t.Errorf("%s, input %q, optimized %t: %s", fixture.File, result.Input, !options.NoOptimization, difference)
+				}
+			}
+		}
+	}
+}
+
+// Runs which both stop with an error are different unless the fixture should stop
+func TestBothStop(t *testing.T) {
+	code := "package main\n\nfunc main() {\n\tvar m map[string]int\n\tm[\"a\"] = 1\n}\n"
+	results, errors := Compare(code, nil, translator.Options{})
+
+	if errors != "" || len(results) != 1 || !results[0].Stopped || len(results[0].Differences) != 1 {
+		t.Errorf("runs which both stop aren't different: %+v %s", results, errors)
+	}
+
+	results, _ = CompareFixture(Fixture{Stops: []string{""}}, code, translator.Options{})
+
+	if len(results) != 1 || len(results[0].Differences) != 0 {
+		t.Errorf("fixture which should stop is different: %+v", results)
+	}
+}
@@ -0,0 +1,46 @@
+package difftest
+
+// Program of the repository with inputs it's compared with and outputs it should give for them.
+// Programs which don't read anything are run once with empty input.
+// Outputs aren't checked if there are none.
+type Fixture struct {
+	File    string
+	Inputs  []string
+	Outputs []string
+	Stops   []string // Inputs with which both runs should stop with an error
+}
+
+func (fixture Fixture) stops(input string) bool {
+	for _, stop := range fixture.Stops {
+		if stop == input {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Programs with errors are listed too, they aren't compared but they should stay rejected
+var Fixtures = []Fixture{
+	{"test1.notgo", nil, nil, nil},
+	{"test2.notgo", nil, nil, nil},
+	{"test3.notgo", nil, nil, nil},
+	{"test4.notgo", nil, nil, nil},
+	{"test5.notgo", nil, nil, nil},
+	{"test6.notgo", nil, nil, nil},
+	{"test7.notgo", nil, nil, nil},
+	{"test8.notgo", nil, nil, []string{""}},
+	{"test9.notgo", nil, nil, nil},
+	{"test10.notgo", []string{"3 2.5", "0 0", "40 2.75\n", "7", "x 1", ""}, nil, nil},
+	{"test11.notgo", []string{"1 -3 2", "1 2 5", "2.5 0 -10", "-1 4 0.5", ""}, nil, nil},
+	{"test12.notgo", nil, nil, nil},
+	{"test13.notgo", []string{"0", "1", "5", "10", "-3", ""}, nil, []string{"-3"}},
+	{"test14.notgo", nil, nil, nil},
+	{"test15.notgo", nil, nil, nil},
+	{"test16.notgo", nil, []string{"true 4 -4 -9 3 0\n"}, nil},
+	{"test17.notgo", nil, nil, nil},
+	{"test18.notgo", nil, nil, nil},
+	{"test19.notgo", nil, []string{"1.5 -4 4\n"}, nil},
+	{"test20.notgo", nil, []string{"0 1 3 s 3 7 5\n"}, nil},
+	{"test21.notgo", nil, []string{"2 1 0 ab 2\n"}, nil},
+}
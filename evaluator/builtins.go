@@ -0,0 +1,333 @@
+package evaluator
+
+import (
+	"../interpreter"
+	"../parser"
+	"../printer"
+	"../semantic"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Calls of functions of packages, conversions, declared functions and builtins.
+// Call with several results gives a tuple.
+func (evaluator *Evaluator) evaluateCall(call parser.CallExpression, frame *frame) Value {
+	if selector, status := call.Function.(parser.SelectorExpression); status {
+		if pkg, status := selector.Expression.(parser.Identifier); status && frame.lookup(pkg.Name) == nil {
+			return evaluator.evaluatePackageCall(pkg.Name, selector.Selector.Name, call, frame)
+		}
+	}
+
+	ident, status := call.Function.(parser.Identifier)
+
+	if !status {
+		fail(call.Position, "value is not a function")
+	}
+
+	var args []Value
+
+	for _, arg := range call.Arguments {
+		args = append(args, evaluator.evaluate(arg, frame))
+	}
+
+	if _, status := predeclaredTypes[ident.Name]; status || evaluator.declarations[ident.Name].Type != nil {
+		evaluator.arguments(call, args, 1)
+		return conversion(evaluator.resolveType(ident), args[0])
+	}
+
+	if function, status := evaluator.functions[ident.Name]; status {
+		return evaluator.call(function, call, args)
+	}
+
+	switch ident.Name {
+	case semantic.Len, semantic.Cap:
+		evaluator.arguments(call, args, 1)
+
+		switch x := args[0].(type) {
+		case *Array:
+			if ident.Name == semantic.Cap {
+				return int64(cap(x.Elements))
+			}
+
+			return int64(len(x.Elements))
+		case *Map:
+			return int64(len(x.Entries))
+		case string:
+			return int64(len(x))
+		}
+	case semantic.Append:
+		if array, status := args[0].(*Array); status {
+			result := &Array{array.Elements, array.Type}
+
+			for _, element := range args[1:] {
+				result.Elements = append(result.Elements, store(element, elementType(array.Type)))
+			}
+
+			return result
+		}
+	case semantic.Delete:
+		evaluator.arguments(call, args, 2)
+
+		if m, status := args[0].(*Map); status {
+			delete(m.Entries, m.key(store(args[1], underlying(m.Type).(semantic.Map).Key)))
+			return nil
+		}
+	default:
+		fail(call.Position, "%s is not declared", ident.Name)
+	}
+
+	fail(call.Position, "wrong arguments of %s", ident.Name)
+	return nil
+}
+
+func (evaluator *Evaluator) arguments(call parser.CallExpression, args []Value, count int) {
+	if len(args) != count {
+		fail(call.Position, "%s takes %d arguments, got %d", printer.Expression(call.Function), count, len(args))
+	}
+}
+
+// Arguments are copied into parameters, results start with zero values
+func (evaluator *Evaluator) call(function parser.FuncDeclaration, call parser.CallExpression, args []Value) Value {
+	if evaluator.depth >= evaluator.options.MaxDepth {
+		fail(call.Position, "stack overflow: calls are nested deeper than %d", evaluator.options.MaxDepth)
+	}
+
+	frame := &frame{function, []map[string]*variable{{}}, nil}
+	i := 0
+
+	for _, param := range function.Parameters {
+		t := evaluator.resolveType(param.Type)
+
+		for _, name := range param.Names {
+			if i >= len(args) {
+				fail(call.Position, "not enough arguments in call of %s", function.Name.Name)
+			}
+
+			frame.declare(name.Name, t, store(args[i], t))
+			i++
+		}
+	}
+
+	if i != len(args) {
+		fail(call.Position, "too many arguments in call of %s", function.Name.Name)
+	}
+
+	var results []semantic.Type
+
+	for _, result := range function.Results {
+		t := evaluator.resolveType(result.Type)
+
+		for _, name := range result.Names {
+			frame.declare(name.Name, t, zero(t))
+		}
+
+		// Unnamed result has no names
+		count := len(result.Names)
+
+		if count == 0 {
+			count = 1
+		}
+
+		for j := 0; j < count; j++ {
+			results = append(results, t)
+		}
+	}
+
+	position := evaluator.position
+	evaluator.depth++
+	evaluator.execute(function.Body.Statements, frame)
+	evaluator.depth--
+	evaluator.position = position
+
+	if len(frame.results) != len(results) {
+		fail(call.Position, "function %s ended without return", function.Name.Name)
+	}
+
+	for i, t := range results {
+		frame.results[i] = store(frame.results[i], t)
+	}
+
+	switch len(results) {
+	case 0:
+		return nil
+	case 1:
+		return frame.results[0]
+	}
+
+	return tuple(frame.results)
+}
+
+// Conversions work the same way as in Go: int(2.5) is 2, string(65) is "A"
+func conversion(t semantic.Type, value Value) Value {
+	switch underlying(t) {
+	case semantic.Int:
+		switch x := value.(type) {
+		case int64:
+			return x
+		case float64:
+			return int64(x)
+		}
+	case semantic.Float:
+		switch x := value.(type) {
+		case int64:
+			return float64(x)
+		case float64:
+			return x
+		}
+	case semantic.String:
+		switch x := value.(type) {
+		case int64:
+			return string(rune(x))
+		case string:
+			return x
+		}
+	case semantic.Bool:
+		return value
+	default:
+		value = copyValue(value)
+
+		switch v := value.(type) {
+		case *Array:
+			v.Type = t
+		case *Map:
+			v.Type = t
+		case *Struct:
+			v.Type = t
+		}
+
+		return value
+	}
+
+	fail(parser.Position{}, "%s can't be converted to %s", interpreter.FormatValue(value), t)
+	return nil
+}
+
+//   fmt.Println(a, b), fmt.Printf("%d\n", a), fmt.Scan(&a), math.Sqrt(x)
+func (evaluator *Evaluator) evaluatePackageCall(pkg string, name string, call parser.CallExpression, frame *frame) Value {
+	if pkg == semantic.Fmt && name == semantic.Scan {
+		evaluator.scan(call.Arguments, frame)
+		return nil
+	}
+
+	var args []Value
+
+	for _, arg := range call.Arguments {
+		args = append(args, evaluator.evaluate(arg, frame))
+	}
+
+	switch pkg + "." + name {
+	case "fmt.Print", "fmt.Println", "fmt.Printf":
+		var values []interface{}
+
+		for _, arg := range args {
+			values = append(values, arg)
+		}
+
+		var err error
+
+		if name == semantic.Println {
+			_, err = fmt.Fprintln(evaluator.out, values...)
+		} else if name == semantic.Print {
+			_, err = fmt.Fprint(evaluator.out, values...)
+		} else if format, status := args[0].(string); status {
+			_, err = fmt.Fprintf(evaluator.out, format, values[1:]...)
+		}
+
+		if err != nil {
+			fail(call.Position, "could not write output: %v", err)
+		}
+
+		return nil
+	case "math.Sqrt", "math.Abs", "math.Floor":
+		evaluator.arguments(call, args, 1)
+		x := toFloat(args[0])
+
+		return map[string]func(float64) float64{
+			semantic.Sqrt: math.Sqrt, semantic.Abs: math.Abs, semantic.Floor: math.Floor,
+		}[name](x)
+	case "math.Pow", "math.Max", "math.Min":
+		evaluator.arguments(call, args, 2)
+		x, y := toFloat(args[0]), toFloat(args[1])
+
+		return map[string]func(float64, float64) float64{
+			semantic.Pow: math.Pow, semantic.Max: math.Max, semantic.Min: math.Min,
+		}[name](x, y)
+	}
+
+	fail(call.Position, "%s.%s is not supported", pkg, name)
+	return nil
+}
+
+// Math functions take reals, integer constants are converted
+func toFloat(value Value) float64 {
+	if i, status := value.(int64); status {
+		return float64(i)
+	}
+
+	return value.(float64)
+}
+
+// Values are read the same way as with fmt.Scan, they are separated by spaces and new lines.
+// Reading stops at the end of input or at the value which can't be read.
+func (evaluator *Evaluator) scan(args parser.Expressions, frame *frame) {
+	for _, arg := range args {
+		address, status := arg.(parser.UnaryExpression)
+
+		if !status || address.Operator != "&" {
+			fail(parser.ExpressionPosition(arg), "fmt.Scan takes addresses of variables")
+		}
+
+		word := evaluator.readWord()
+
+		if word == "" {
+			return
+		}
+
+		var value Value
+		var err error
+
+		switch evaluator.evaluate(address.Operand, frame).(type) {
+		case int64:
+			value, err = strconv.ParseInt(word, 0, 64)
+		case float64:
+			value, err = strconv.ParseFloat(word, 64)
+		case string:
+			value = word
+		case bool:
+			value, err = strconv.ParseBool(word)
+		}
+
+		if err != nil {
+			return
+		}
+
+		evaluator.assign(address.Operand, value, frame)
+	}
+}
+
+// Next word of input, empty at the end of input
+func (evaluator *Evaluator) readWord() string {
+	var word strings.Builder
+
+	for {
+		r, _, err := evaluator.in.ReadRune()
+
+		if err != nil {
+			break
+		}
+
+		if strings.ContainsRune(" \t\r\n", r) {
+			if word.Len() > 0 {
+				break
+			}
+
+			continue
+		}
+
+		word.WriteRune(r)
+	}
+
+	return word.String()
+}
@@ -0,0 +1,351 @@
+package evaluator
+
+import (
+	"../parser"
+	"../semantic"
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Go code is run directly from its syntax tree, starting with function main.
+// The code should be checked by semantic analysis first, so only errors
+// which Go finds while the program runs are reported: index out of range,
+// division by zero, assignment to nil map.
+type Evaluator struct {
+	functions    map[string]parser.FuncDeclaration
+	declarations map[string]parser.TypeDeclaration
+	types        map[string]semantic.Type // Resolved declarations
+	in           *bufio.Reader
+	out          io.Writer
+	options      Options
+	position     parser.Position // Statement which is executed
+	depth        int             // Calls which are running
+}
+
+type Options struct {
+	MaxDepth int // Calls nested deeper stop the program, DefaultMaxDepth if it's zero
+}
+
+const DefaultMaxDepth = 10000
+
+// Error which stops the program, with the position of the statement it happens in
+type Error struct {
+	Position parser.Position
+	Message  string
+}
+
+func (err *Error) Error() string {
+	return err.Position.String() + ": " + err.Message
+}
+
+// Runtime errors are raised as panics with this type, so they stop the program at once
+type runtimeError struct {
+	position parser.Position // Zero if it's the position of the statement
+	message  string
+}
+
+func fail(position parser.Position, format string, args ...interface{}) {
+	panic(runtimeError{position, fmt.Sprintf(format, args...)})
+}
+
+type variable struct {
+	value Value
+	t     semantic.Type
+}
+
+// Variables of the running function. Each block has its own scope,
+// the first one has parameters and results.
+type frame struct {
+	function parser.FuncDeclaration
+	scopes   []map[string]*variable
+	results  []Value
+}
+
+// Result of executed statement
+type control int
+
+const (
+	proceed control = iota
+	returned
+	broken // break leaves the switch
+)
+
+func NewEvaluator(file parser.File, in io.Reader, out io.Writer, options Options) *Evaluator {
+	evaluator := &Evaluator{
+		map[string]parser.FuncDeclaration{},
+		map[string]parser.TypeDeclaration{},
+		map[string]semantic.Type{},
+		bufio.NewReader(in),
+		out,
+		options,
+		parser.Position{},
+		0,
+	}
+
+	for _, declaration := range file.Declarations {
+		if decl, status := declaration.(parser.FuncDeclaration); status {                 // Function
+			evaluator.functions[decl.Name.Name] = decl
+		} else if decl, status := declaration.(parser.TypeDeclaration); status {          // Type
+			evaluator.declarations[decl.Name.Name] = decl
+		}
+	}
+
+	if evaluator.options.MaxDepth == 0 {
+		evaluator.options.MaxDepth = DefaultMaxDepth
+	}
+
+	return evaluator
+}
+
+// Variables declared in the body of main are returned with the values they have at the end
+func (evaluator *Evaluator) Run() (variables map[string]Value, err error) {
+	main, status := evaluator.functions["main"]
+
+	if !status {
+		return nil, fmt.Errorf("function main is not declared")
+	}
+
+	defer func() {
+		recovered := recover()
+
+		if runtimeErr, status := recovered.(runtimeError); status {
+			position := runtimeErr.position
+
+			if position.Line == 0 {
+				position = evaluator.position
+			}
+
+			variables, err = nil, &Error{position, runtimeErr.message}
+		} else if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	evaluator.position = main.Position
+	frame := &frame{main, []map[string]*variable{{}}, nil}
+	evaluator.execute(main.Body.Statements, frame)
+
+	variables = map[string]Value{}
+
+	for name, variable := range frame.scopes[0] {
+		variables[name] = variable.value
+	}
+
+	return variables, nil
+}
+
+func (frame *frame) declare(name string, t semantic.Type, value Value) {
+	if name != semantic.Blank {
+		frame.scopes[len(frame.scopes)-1][name] = &variable{value, t}
+	}
+}
+
+// Nil if the variable isn't declared
+func (frame *frame) lookup(name string) *variable {
+	if frame == nil {
+		return nil
+	}
+
+	for i := len(frame.scopes) - 1; i >= 0; i-- {
+		if variable, status := frame.scopes[i][name]; status {
+			return variable
+		}
+	}
+
+	return nil
+}
+
+// Statements of the block are executed in a new scope
+func (evaluator *Evaluator) executeBlock(block parser.BlockStatement, frame *frame) control {
+	frame.scopes = append(frame.scopes, map[string]*variable{})
+	control := evaluator.execute(block.Statements, frame)
+	frame.scopes = frame.scopes[:len(frame.scopes)-1]
+
+	return control
+}
+
+func (evaluator *Evaluator) execute(stmts parser.Statements, frame *frame) control {
+	for _, stmt := range stmts {
+		if control := evaluator.executeStatement(stmt, frame); control != proceed {
+			return control
+		}
+	}
+
+	return proceed
+}
+
+func (evaluator *Evaluator) executeStatement(statement parser.Statement, frame *frame) control {
+	if stmt, status := statement.(parser.AssignStatement); status {                   // Assign
+		evaluator.position = stmt.Position
+		evaluator.executeAssignStatement(stmt, frame)
+	} else if stmt, status := statement.(parser.VarStatement); status {               // Var
+		evaluator.position = stmt.Position
+		var t semantic.Type
+		var value Value
+
+		if stmt.Type != nil {
+			t = evaluator.resolveType(stmt.Type)
+			value = zero(t)
+		}
+
+		if stmt.Expression != nil {
+			value = evaluator.evaluate(stmt.Expression, frame)
+
+			if t == nil {
+				t = typeOf(value)
+			}
+		}
+
+		frame.declare(stmt.Identifier.Name, t, store(value, t))
+	} else if stmt, status := statement.(parser.ExpressionStatement); status {        // Call
+		evaluator.position = stmt.Position
+		evaluator.evaluate(stmt.Expression, frame)
+	} else if stmt, status := statement.(parser.ReturnStatement); status {            // Return
+		evaluator.position = stmt.Position
+		evaluator.executeReturnStatement(stmt, frame)
+		return returned
+	} else if stmt, status := statement.(parser.BranchStatement); status {            // Branch
+		evaluator.position = stmt.Position
+
+		if stmt.Keyword != "break" {
+			fail(parser.Position{}, "%s is not in a loop", stmt.Keyword)
+		}
+
+		return broken
+	} else if stmt, status := statement.(parser.BlockStatement); status {             // Block
+		return evaluator.executeBlock(stmt, frame)
+	} else if stmt, status := statement.(parser.IfStatement); status {                // If
+		evaluator.position = stmt.Position
+		condition, status := evaluator.evaluate(stmt.Condition, frame).(bool)
+
+		if !status {
+			fail(parser.Position{}, "condition is not bool")
+		}
+
+		if condition {
+			return evaluator.executeBlock(stmt.IfBody, frame)
+		}
+
+		return evaluator.executeBlock(stmt.ElseBody, frame)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {            // Switch
+		evaluator.position = stmt.Position
+		return evaluator.executeSwitchStatement(stmt, frame)
+	} else if stmts, status := statement.(parser.Statements); status {                // Statements
+		return evaluator.execute(stmts, frame)
+	}
+
+	return proceed
+}
+
+// Several targets take the results of call, the value of map element and whether it's found,
+// or several values, which are all evaluated before assigning.
+// Variables which are already declared in the same scope are assigned by :=.
+func (evaluator *Evaluator) executeAssignStatement(stmt parser.AssignStatement, frame *frame) {
+	var values []Value
+
+	if exprs, status := stmt.Expression.(parser.Expressions); status {
+		for _, expr := range exprs {
+			values = append(values, evaluator.evaluate(expr, frame))
+		}
+	} else if len(stmt.Targets) == 1 {
+		values = []Value{evaluator.evaluate(stmt.Expression, frame)}
+	} else if index, status := stmt.Expression.(parser.IndexExpression); status {
+		value, found := evaluator.lookup(index, frame)
+		values = []Value{value, found}
+	} else if results, status := evaluator.evaluate(stmt.Expression, frame).(tuple); status {
+		values = results
+	}
+
+	if len(values) != len(stmt.Targets) {
+		fail(parser.Position{}, "%d values assigned to %d targets", len(values), len(stmt.Targets))
+	}
+
+	for i, target := range stmt.Targets {
+		ident, isIdent := target.(parser.Identifier)
+
+		if stmt.Operator == ":=" && isIdent && frame.scopes[len(frame.scopes)-1][ident.Name] == nil {
+			frame.declare(ident.Name, typeOf(values[i]), copyValue(values[i]))
+			continue
+		}
+
+		evaluator.assign(target, values[i], frame)
+	}
+}
+
+// Return without values gives the named results
+func (evaluator *Evaluator) executeReturnStatement(stmt parser.ReturnStatement, frame *frame) {
+	var results []Value
+
+	if len(stmt.Results) == 1 {
+		value := evaluator.evaluate(stmt.Results[0], frame)
+
+		if values, status := value.(tuple); status {
+			results = values
+		} else {
+			results = []Value{value}
+		}
+	} else {
+		for _, result := range stmt.Results {
+			results = append(results, evaluator.evaluate(result, frame))
+		}
+	}
+
+	if len(stmt.Results) == 0 {
+		for _, result := range frame.function.Results {
+			for _, name := range result.Names {
+				results = append(results, frame.lookup(name.Name).value)
+			}
+		}
+	}
+
+	frame.results = results
+}
+
+// Cases are checked in order, default is taken if none of them matches.
+// Switch without expression checks conditions of cases.
+func (evaluator *Evaluator) executeSwitchStatement(stmt parser.SwitchStatement, frame *frame) control {
+	var tag Value = true
+
+	if !isExpressionNil(stmt.Expression) {
+		tag = evaluator.evaluate(stmt.Expression, frame)
+	}
+
+	chosen := -1
+
+	for i, caseStmt := range stmt.Body {
+		if isExpressionNil(caseStmt.Expression) {
+			if chosen < 0 {
+				chosen = i
+			}
+
+			continue
+		}
+
+		evaluator.position = caseStmt.Position
+
+		if isEqual(tag, evaluator.evaluate(caseStmt.Expression, frame)) {
+			chosen = i
+			break
+		}
+	}
+
+	if chosen < 0 {
+		return proceed
+	}
+
+	if control := evaluator.executeBlock(stmt.Body[chosen].Body, frame); control == returned {
+		return returned
+	}
+
+	return proceed
+}
+
+// Switch without expression and default case have empty expressions
+func isExpressionNil(expression parser.Expression) bool {
+	if expr, status := expression.(parser.UnaryExpression); status {
+		return expr.Operand == nil
+	}
+
+	return expression == nil
+}
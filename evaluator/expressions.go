@@ -0,0 +1,434 @@
+package evaluator
+
+import (
+	"../interpreter"
+	"../parser"
+	"../semantic"
+	"go/constant"
+	"strconv"
+)
+
+func (evaluator *Evaluator) evaluate(expression parser.Expression, frame *frame) Value {
+	if expr, status := expression.(parser.Literal); status {                          // Literal
+		if str, status := expr.Value.(string); status {
+			return unquote(str)
+		}
+
+		return expr.Value
+	} else if expr, status := expression.(parser.Identifier); status {                // Identifier
+		variable := frame.lookup(expr.Name)
+
+		if variable == nil {
+			fail(expr.Position, "variable %s is not declared", expr.Name)
+		}
+
+		return variable.value
+	} else if expr, status := expression.(parser.UnaryExpression); status {           // Unary
+		if value := semantic.ConstantValue(expr); value != nil {
+			return constantValue(expr.Position, value)
+		}
+
+		return unaryOperation(expr, evaluator.evaluate(expr.Operand, frame))
+	} else if expr, status := expression.(parser.BinaryExpression); status {          // Binary
+		if value := semantic.ConstantValue(expr); value != nil {
+			return constantValue(expr.Position, value)
+		}
+
+		return evaluator.evaluateBinaryExpression(expr, frame)
+	} else if expr, status := expression.(parser.IndexExpression); status {           // Index
+		return evaluator.evaluateIndexExpression(expr, frame)
+	} else if expr, status := expression.(parser.SelectorExpression); status {        // Selector
+		s, index := evaluator.field(expr, frame)
+		return s.Fields[index]
+	} else if expr, status := expression.(parser.CallExpression); status {            // Call
+		return evaluator.evaluateCall(expr, frame)
+	} else if expr, status := expression.(parser.CompositeLiteral); status {          // Composite
+		return evaluator.evaluateCompositeLiteral(expr, frame)
+	}
+
+	fail(parser.ExpressionPosition(expression), "unexpected expression")
+	return nil
+}
+
+// Constant expressions are computed exactly, the same way Go compiler computes them,
+// only their results should fit into values: 1 << 70 >> 68 -> 4
+func constantValue(position parser.Position, value constant.Value) Value {
+	switch value.Kind() {
+	case constant.Int:
+		if i, exact := constant.Int64Val(value); exact {
+			return i
+		}
+
+		fail(position, "constant %s overflows int", value)
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return f
+	case constant.String:
+		return constant.StringVal(value)
+	case constant.Bool:
+		return constant.BoolVal(value)
+	}
+
+	fail(position, "unexpected constant %s", value)
+	return nil
+}
+
+// Text of string literal keeps its escapes: a\n
+func unquote(str string) string {
+	if value, err := strconv.Unquote(`"` + str + `"`); err == nil {
+		return value
+	}
+
+	return str
+}
+
+// && and || don't evaluate the right operand if the left one gives the result
+func (evaluator *Evaluator) evaluateBinaryExpression(expr parser.BinaryExpression, frame *frame) Value {
+	left := evaluator.evaluate(expr.LeftOperand, frame)
+
+	if expr.Operator == "&&" || expr.Operator == "||" {
+		if left.(bool) == (expr.Operator == "||") {
+			return left
+		}
+
+		return evaluator.evaluate(expr.RightOperand, frame).(bool)
+	}
+
+	right := evaluator.evaluate(expr.RightOperand, frame)
+
+	return binaryOperation(expr, constantOperand(expr.LeftOperand, left, right), constantOperand(expr.RightOperand, right, left))
+}
+
+// Constant written as float takes integer type of the other operand: i / 2.0 is i / 2
+func constantOperand(operand parser.Expression, value Value, other Value) Value {
+	if f, status := value.(float64); status && semantic.ConstantValue(operand) != nil {
+		if _, status := other.(int64); status {
+			return int64(f)
+		}
+	}
+
+	return value
+}
+
+// Missing key of map gives zero value, bytes of strings are integers
+func (evaluator *Evaluator) evaluateIndexExpression(expr parser.IndexExpression, frame *frame) Value {
+	container := evaluator.evaluate(expr.Expression, frame)
+
+	switch container := container.(type) {
+	case *Array:
+		return container.Elements[evaluator.index(expr, len(container.Elements), frame)]
+	case *Map:
+		value, _ := evaluator.lookup(expr, frame)
+		return value
+	case string:
+		return int64(container[evaluator.index(expr, len(container), frame)])
+	}
+
+	fail(expr.Position, "%s can't be indexed", interpreter.FormatValue(container))
+	return nil
+}
+
+// Element of map and whether it's found: v, ok := m[k]
+func (evaluator *Evaluator) lookup(expr parser.IndexExpression, frame *frame) (Value, bool) {
+	m, status := evaluator.evaluate(expr.Expression, frame).(*Map)
+
+	if !status {
+		fail(expr.Position, "value is not a map")
+	}
+
+	mapType := underlying(m.Type).(semantic.Map)
+	key := m.key(store(evaluator.evaluate(expr.Index, frame), mapType.Key))
+
+	if value, status := m.Entries[key]; status {
+		return value, true
+	}
+
+	return zero(mapType.Value), false
+}
+
+func (evaluator *Evaluator) index(expr parser.IndexExpression, length int, frame *frame) int {
+	i, status := evaluator.evaluate(expr.Index, frame).(int64)
+
+	if !status {
+		fail(expr.Position, "index is not an integer")
+	}
+
+	if i < 0 || i >= int64(length) {
+		fail(expr.Position, "index out of range [%d] with length %d", i, length)
+	}
+
+	return int(i)
+}
+
+// Struct and index of its field
+func (evaluator *Evaluator) field(expr parser.SelectorExpression, frame *frame) (*Struct, int) {
+	s, status := evaluator.evaluate(expr.Expression, frame).(*Struct)
+
+	if !status {
+		fail(expr.Position, "value has no field %s", expr.Selector.Name)
+	}
+
+	structType := underlying(s.Type).(*semantic.Struct)
+
+	for i, field := range structType.Fields {
+		if field.Name == expr.Selector.Name {
+			return s, i
+		}
+	}
+
+	fail(expr.Position, "value has no field %s", expr.Selector.Name)
+	return nil, 0
+}
+
+// Elements of arrays and slices can have indices as keys: [5]int{2: 1, 4: 3},
+// fields of structs can have names as keys: Point{X: 1}
+func (evaluator *Evaluator) evaluateCompositeLiteral(lit parser.CompositeLiteral, frame *frame) Value {
+	t := evaluator.resolveType(lit.Type)
+	value := zero(t)
+
+	switch u := underlying(t).(type) {
+	case semantic.Array, semantic.Slice:
+		array := value.(*Array)
+		element := elementType(t)
+
+		for i, elem := range lit.Elements {
+			if pair, status := elem.(parser.KeyValueExpression); status {
+				i = int(evaluator.evaluate(pair.Key, frame).(int64))
+				elem = pair.Value
+			}
+
+			for len(array.Elements) <= i {
+				array.Elements = append(array.Elements, zero(element))
+			}
+
+			array.Elements[i] = store(evaluator.evaluate(elem, frame), element)
+		}
+
+		if array.Elements == nil {
+			array.Elements = []Value{}
+		}
+	case semantic.Map:
+		m := value.(*Map)
+		m.Entries = map[Value]Value{}
+
+		for _, elem := range lit.Elements {
+			pair := elem.(parser.KeyValueExpression)
+			key := m.key(store(evaluator.evaluate(pair.Key, frame), u.Key))
+			m.Entries[key] = store(evaluator.evaluate(pair.Value, frame), u.Value)
+		}
+	case *semantic.Struct:
+		s := value.(*Struct)
+
+		for i, elem := range lit.Elements {
+			if pair, status := elem.(parser.KeyValueExpression); status {
+				i = fieldIndex(u, pair.Key.(parser.Identifier).Name)
+				elem = pair.Value
+			}
+
+			s.Fields[i] = store(evaluator.evaluate(elem, frame), u.Fields[i].Type)
+		}
+	default:
+		fail(lit.Position, "composite literal of type %s", t)
+	}
+
+	return value
+}
+
+func fieldIndex(t *semantic.Struct, name string) int {
+	for i, field := range t.Fields {
+		if field.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Value is stored into the variable, element or field with the type it's declared with.
+// Assignment to _ is skipped.
+func (evaluator *Evaluator) assign(target parser.Expression, value Value, frame *frame) {
+	if expr, status := target.(parser.Identifier); status {                           // Variable
+		if expr.Name == semantic.Blank {
+			return
+		}
+
+		variable := frame.lookup(expr.Name)
+
+		if variable == nil {
+			fail(expr.Position, "variable %s is not declared", expr.Name)
+		}
+
+		variable.value = store(value, variable.t)
+	} else if expr, status := target.(parser.IndexExpression); status {               // Element
+		container := evaluator.evaluate(expr.Expression, frame)
+
+		if array, status := container.(*Array); status {
+			element := evaluator.index(expr, len(array.Elements), frame)
+			array.Elements[element] = store(value, elementType(array.Type))
+		} else if m, status := container.(*Map); status {
+			mapType := underlying(m.Type).(semantic.Map)
+			key := m.key(store(evaluator.evaluate(expr.Index, frame), mapType.Key))
+
+			if m.Entries == nil {
+				fail(expr.Position, "assignment to entry in nil map")
+			}
+
+			m.Entries[key] = store(value, mapType.Value)
+		} else {
+			fail(expr.Position, "element of %s can't be assigned", interpreter.FormatValue(container))
+		}
+	} else if expr, status := target.(parser.SelectorExpression); status {            // Field
+		s, index := evaluator.field(expr, frame)
+		s.Fields[index] = store(value, underlying(s.Type).(*semantic.Struct).Fields[index].Type)
+	} else {
+		fail(parser.ExpressionPosition(target), "value can't be assigned to the expression")
+	}
+}
+
+func elementType(t semantic.Type) semantic.Type {
+	switch u := underlying(t).(type) {
+	case semantic.Array:
+		return u.Element
+	case semantic.Slice:
+		return u.Element
+	}
+
+	return semantic.Undefined
+}
+
+func unaryOperation(expr parser.UnaryExpression, operand Value) Value {
+	switch x := operand.(type) {
+	case int64:
+		switch expr.Operator {
+		case "+":
+			return x
+		case "-":
+			return -x
+		case "^":
+			return ^x
+		}
+	case float64:
+		switch expr.Operator {
+		case "+":
+			return x
+		case "-":
+			return -x
+		}
+	case bool:
+		if expr.Operator == "!" {
+			return !x
+		}
+	}
+
+	fail(expr.Position, "operator %s can't be used with %s", expr.Operator, interpreter.FormatValue(operand))
+	return nil
+}
+
+// Operands have the same type, except integer constant used with real
+func binaryOperation(expr parser.BinaryExpression, left Value, right Value) Value {
+	switch expr.Operator {
+	case "==":
+		return isEqual(left, right)
+	case "!=":
+		return !isEqual(left, right)
+	}
+
+	left, right = interpreter.Promote(left, right)
+
+	switch x := left.(type) {
+	case int64:
+		if y, status := right.(int64); status {
+			if value, status := integerOperation(expr, x, y); status {
+				return value
+			}
+		}
+	case float64:
+		if y, status := right.(float64); status {
+			switch expr.Operator {
+			case "+":
+				return x + y
+			case "-":
+				return x - y
+			case "*":
+				return x * y
+			case "/":
+				return x / y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	case string:
+		if y, status := right.(string); status {
+			switch expr.Operator {
+			case "+":
+				return x + y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+
+	fail(expr.Position, "operator %s can't be used with %s and %s", expr.Operator, interpreter.FormatValue(left), interpreter.FormatValue(right))
+	return nil
+}
+
+func integerOperation(expr parser.BinaryExpression, x int64, y int64) (Value, bool) {
+	switch expr.Operator {
+	case "+":
+		return x + y, true
+	case "-":
+		return x - y, true
+	case "*":
+		return x * y, true
+	case "/", "%":
+		if y == 0 {
+			fail(expr.Position, "integer divide by zero")
+		}
+
+		if expr.Operator == "%" {
+			return x % y, true
+		}
+
+		return x / y, true
+	case "&":
+		return x & y, true
+	case "|":
+		return x | y, true
+	case "^":
+		return x ^ y, true
+	case "&^":
+		return x &^ y, true
+	case "<<", ">>":
+		if y < 0 {
+			fail(expr.Position, "negative shift amount")
+		}
+
+		if expr.Operator == "<<" {
+			return x << uint64(y), true
+		}
+
+		return x >> uint64(y), true
+	case "<":
+		return x < y, true
+	case "<=":
+		return x <= y, true
+	case ">":
+		return x > y, true
+	case ">=":
+		return x >= y, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,340 @@
+package evaluator
+
+import (
+	"../interpreter"
+	"../parser"
+	"../semantic"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Values are int64, float64, string, bool, *Array, *Map and *Struct.
+// Values of named types are the values of their underlying types.
+// Values are printed by fmt the same way as the Go values they stand for,
+// basic values are the same as values of LWIQA interpreter, so its helpers are used for them.
+type Value interface{}
+
+// Arrays and slices. Arrays are copied when they're assigned,
+// slices share their elements, nil slice has nil elements.
+type Array struct {
+	Elements []Value
+	Type     semantic.Type
+}
+
+// Maps are shared when they're assigned, nil map has nil entries.
+// Arrays and structs are keys by their values, not by their pointers, see key.
+type Map struct {
+	Entries map[Value]Value
+	Type    semantic.Type
+	keys    map[string]Value // Keys which are arrays and structs by their text
+}
+
+// Structs are copied when they're assigned
+type Struct struct {
+	Fields []Value
+	Type   semantic.Type
+}
+
+// Results of call with several results
+type tuple []Value
+
+//   [1 2 3]
+func (array *Array) String() string {
+	var elements []string
+
+	for _, element := range array.Elements {
+		elements = append(elements, fmt.Sprint(element))
+	}
+
+	return "[" + strings.Join(elements, " ") + "]"
+}
+
+// Keys are sorted the same way fmt sorts them: map[a:1 b:2]
+func (m *Map) String() string {
+	var keys []Value
+
+	for key := range m.Entries {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return interpreter.IsLess(keys[i], keys[j])
+	})
+
+	var entries []string
+
+	for _, key := range keys {
+		entries = append(entries, fmt.Sprint(key)+":"+fmt.Sprint(m.Entries[key]))
+	}
+
+	return "map[" + strings.Join(entries, " ") + "]"
+}
+
+// Arrays and structs are equal keys if their values are equal,
+// so entries are keyed by the first key which is equal to the given one
+func (m *Map) key(key Value) Value {
+	switch key.(type) {
+	case *Array, *Struct:
+	default:
+		return key
+	}
+
+	text := keyText(key)
+
+	if existing, status := m.keys[text]; status {
+		return existing
+	}
+
+	if m.keys == nil {
+		m.keys = map[string]Value{}
+	}
+
+	m.keys[text] = key
+	return key
+}
+
+// Text which is the same only for equal keys, strings are quoted: {1 "a b"}
+func keyText(key Value) string {
+	var parts []string
+
+	switch k := key.(type) {
+	case *Array:
+		for _, element := range k.Elements {
+			parts = append(parts, keyText(element))
+		}
+
+		return "[" + strings.Join(parts, " ") + "]"
+	case *Struct:
+		for _, field := range k.Fields {
+			parts = append(parts, keyText(field))
+		}
+
+		return "{" + strings.Join(parts, " ") + "}"
+	}
+
+	return interpreter.FormatValue(key)
+}
+
+//   {1 2 a}
+func (s *Struct) String() string {
+	var fields []string
+
+	for _, field := range s.Fields {
+		fields = append(fields, fmt.Sprint(field))
+	}
+
+	return "{" + strings.Join(fields, " ") + "}"
+}
+
+// Types of type declarations are resolved when they're used for the first time
+func (evaluator *Evaluator) resolveType(expression parser.Expression) semantic.Type {
+	switch expr := expression.(type) {
+	case parser.Identifier:
+		if t, status := predeclaredTypes[expr.Name]; status {
+			return t
+		}
+
+		if t, status := evaluator.types[expr.Name]; status {
+			return t
+		}
+
+		decl, status := evaluator.declarations[expr.Name]
+
+		if !status {
+			fail(expr.Position, "%s is not a type", expr.Name)
+		}
+
+		if decl.Alias {
+			evaluator.types[expr.Name] = evaluator.resolveType(decl.Type)
+			return evaluator.types[expr.Name]
+		}
+
+		named := &semantic.Named{Name: expr.Name}
+		evaluator.types[expr.Name] = named
+		named.Underlying = evaluator.resolveType(decl.Type)
+
+		return named
+	case parser.ArrayType:
+		length, status := evaluator.evaluate(expr.Length, nil).(int64)
+
+		if !status || length < 0 {
+			fail(parser.ExpressionPosition(expr.Length), "wrong length of array")
+		}
+
+		return semantic.Array{Length: int(length), Element: evaluator.resolveType(expr.Element)}
+	case parser.SliceType:
+		return semantic.Slice{Element: evaluator.resolveType(expr.Element)}
+	case parser.MapType:
+		return semantic.Map{Key: evaluator.resolveType(expr.Key), Value: evaluator.resolveType(expr.Value)}
+	case parser.StructType:
+		structType := &semantic.Struct{}
+
+		for _, field := range expr.Fields {
+			fieldType := evaluator.resolveType(field.Type)
+
+			for _, name := range field.Names {
+				structType.Fields = append(structType.Fields, semantic.Field{Name: name.Name, Type: fieldType})
+			}
+		}
+
+		return structType
+	}
+
+	fail(parser.ExpressionPosition(expression), "%s is not a type", expression)
+	return nil
+}
+
+var predeclaredTypes = map[string]semantic.Type{
+	"int":     semantic.Int,
+	"float64": semantic.Float,
+	"string":  semantic.String,
+	"bool":    semantic.Bool,
+}
+
+func underlying(t semantic.Type) semantic.Type {
+	if named, status := t.(*semantic.Named); status {
+		return named.Underlying
+	}
+
+	return t
+}
+
+// Zero value of the type: nil slices and maps, arrays and structs of zero values
+func zero(t semantic.Type) Value {
+	switch u := underlying(t).(type) {
+	case semantic.Basic:
+		switch u {
+		case semantic.Int:
+			return int64(0)
+		case semantic.Float:
+			return 0.0
+		case semantic.String:
+			return ""
+		case semantic.Bool:
+			return false
+		}
+	case semantic.Array:
+		array := &Array{make([]Value, u.Length), t}
+
+		for i := range array.Elements {
+			array.Elements[i] = zero(u.Element)
+		}
+
+		return array
+	case semantic.Slice:
+		return &Array{nil, t}
+	case semantic.Map:
+		return &Map{nil, t, nil}
+	case *semantic.Struct:
+		s := &Struct{make([]Value, len(u.Fields)), t}
+
+		for i, field := range u.Fields {
+			s.Fields[i] = zero(field.Type)
+		}
+
+		return s
+	}
+
+	return nil
+}
+
+// Type of value which is assigned with :=, untyped constants get their default types
+func typeOf(value Value) semantic.Type {
+	switch v := value.(type) {
+	case int64:
+		return semantic.Int
+	case float64:
+		return semantic.Float
+	case string:
+		return semantic.String
+	case bool:
+		return semantic.Bool
+	case *Array:
+		return v.Type
+	case *Map:
+		return v.Type
+	case *Struct:
+		return v.Type
+	}
+
+	return semantic.Undefined
+}
+
+// Value is copied into a variable, element or field of the type.
+// Integer constants become real if the type is real, constants written as floats become integers
+// if the type is integer: var c int = 5.0. Arrays and structs get their own copy.
+func store(value Value, t semantic.Type) Value {
+	if i, status := value.(int64); status && underlying(t) == semantic.Float {
+		return float64(i)
+	} else if f, status := value.(float64); status && underlying(t) == semantic.Int {
+		return int64(f)
+	}
+
+	return copyValue(value)
+}
+
+func copyValue(value Value) Value {
+	switch v := value.(type) {
+	case *Array:
+		if _, status := underlying(v.Type).(semantic.Array); !status {
+			return v
+		}
+
+		array := &Array{make([]Value, len(v.Elements)), v.Type}
+
+		for i, element := range v.Elements {
+			array.Elements[i] = copyValue(element)
+		}
+
+		return array
+	case *Struct:
+		s := &Struct{make([]Value, len(v.Fields)), v.Type}
+
+		for i, field := range v.Fields {
+			s.Fields[i] = copyValue(field)
+		}
+
+		return s
+	}
+
+	return value
+}
+
+// Values are equal the same way as in Go, integer constant is equal to the same real
+func isEqual(x Value, y Value) bool {
+	x, y = interpreter.Promote(x, y)
+
+	switch x := x.(type) {
+	case *Array:
+		array, status := y.(*Array)
+
+		if !status || len(x.Elements) != len(array.Elements) {
+			return false
+		}
+
+		for i := range x.Elements {
+			if !isEqual(x.Elements[i], array.Elements[i]) {
+				return false
+			}
+		}
+
+		return true
+	case *Struct:
+		s, status := y.(*Struct)
+
+		if !status || len(x.Fields) != len(s.Fields) {
+			return false
+		}
+
+		for i := range x.Fields {
+			if !isEqual(x.Fields[i], s.Fields[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return x == y
+}
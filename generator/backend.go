@@ -0,0 +1,97 @@
+package generator
+
+import "sort"
+
+// Backend renders the constructs found while generator walks the syntax tree.
+// Generator decides how Go code is lowered: switch becomes if chain,
+// several results become output parameters and so on.
+// Backend only decides how the result is written.
+//
+// Line methods get index of the statement, like [1, 2, 3] for Q1.2.3.,
+// and return complete lines ending with a new line.
+// Other methods return text which is put inside the lines.
+type Backend interface {
+	Program(name string) string
+	Comment(text string) string
+
+	Procedure(index []int, name string, params []Parameter, result string, recursive bool) string
+	EndProcedure(index []int, name string) string
+	Locals(index []int, names []string) string
+	// Variables declared in the procedure with their types, numbered inside the block
+	Variables(index []int, variables []Parameter) string
+
+	Record(index []int, name string, fields []Parameter) string
+	TypeDeclaration(index []int, name string, typ string) string
+
+	// Target set to literal or zero value: a := 2, var a int
+	Value(index []int, target string, value string) string
+	Assign(index []int, target string, value string) string
+	// Single statement line, like a procedure call
+	Statement(index []int, text string) string
+	Return(index []int, value string) string
+	Branch(index []int, keyword string) string
+
+	// Index of else and end is the index of the statement after the last one in the branch
+	If(index []int, condition string) string
+	ElseIf(index []int, condition string) string
+	Else(index []int) string
+	EndIf(index []int) string
+
+	// Output writes items like fmt.Print or like fmt.Println if newline is set
+	Output(items []string, newline bool) string
+	Input(targets []string) string
+
+	Identifier(name string) string
+	Operators() OperatorTable
+	// Names of builtin and standard functions: len, math.Sqrt,
+	// and the ones used when lowering: lookup, contains, fixed
+	Function(name string) string
+	// Names of basic types: int, float64, string, bool
+	TypeName(name string) string
+	ArrayType(length string, element string) string
+	MapType(key string, value string) string
+	RecordType(fields []Parameter) string
+	// Name is empty for anonymous records
+	RecordLiteral(name string, elements string) string
+}
+
+// Spellings of all Go operators in the target. Unary operators are separate,
+// since some of them are binary too: a - b and -a, a ^ b and ^a.
+// a &^ b has no spelling of its own, it's written as a & (^b).
+// Binary operators can have other spellings for operands of some basic type,
+// like integer division or joining strings: "int" -> "/" -> DIV.
+type OperatorTable struct {
+	Binary map[string]string
+	Unary  map[string]string
+	Typed  map[string]map[string]string
+}
+
+// Parameter of procedure or field of record with generated name and type
+type Parameter struct {
+	Name   string
+	Type   string
+	Output bool
+}
+
+var backends = map[string]Backend{
+	"lwiqa":  LWIQA{},
+	"pseudo": Pseudocode{},
+}
+
+func GetBackend(name string) (Backend, bool) {
+	backend, status := backends[name]
+	return backend, status
+}
+
+// Names of all built-in backends in alphabetical order
+func Backends() []string {
+	var names []string
+
+	for name := range backends {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
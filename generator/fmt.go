@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"../parser"
+	"../semantic"
+)
+
+// Functions of fmt package are input and output statements:
+//   fmt.Print(a, b)           -> OUTPUT &a&, &b&
+//   fmt.Println(a, b)         -> OUTPUTLN &a&, &b&
+//   fmt.Printf("a = %d\n", a) -> OUTPUT "a = ", &a&, "\n"
+//   fmt.Scan(&a, &b)          -> INPUT &a&, &b&
+func (generator *Generator) generateFmtCall(name string, args parser.Expressions) string {
+	switch name {
+	case semantic.Print:
+		return generator.backend.Output(generator.generateExpressions(args), false)
+	case semantic.Println:
+		return generator.backend.Output(generator.generateExpressions(args), true)
+	case semantic.Printf:
+		return generator.backend.Output(generator.generateFormat(args), false)
+	case semantic.Scan:
+		var targets parser.Expressions
+
+		for _, arg := range args {
+			targets = append(targets, arg.(parser.UnaryExpression).Operand)
+		}
+
+		return generator.backend.Input(generator.generateExpressions(targets))
+	}
+
+	return "!!!Error!!!"
+}
+
+// Format is split into its text and values written in between.
+// Values formatted with %f get six digits after the point: FIXED(&x&, 6).
+// OUTPUT puts spaces between adjacent items which aren't strings, the same way fmt.Print does,
+// so only such values are separated with empty string: "%d%d" -> &a&, "", &b&
+func (generator *Generator) generateFormat(args parser.Expressions) []string {
+	parts, _ := semantic.SplitFormat(args[0].(parser.Literal).Value.(string))
+	values := args[1:]
+	var items []string
+	var isNonString bool // Previous item is a value which isn't a string
+
+	for _, part := range parts {
+		if part.Verb == 0 {
+			items = append(items, "\""+part.Text+"\"")
+			isNonString = false
+			continue
+		}
+
+		value := generator.generateExpression(values[0])
+		isString := part.Verb == 'f' || generator.basicType(values[0]) == "string"
+		values = values[1:]
+
+		if isNonString && !isString {
+			items = append(items, "\"\"")
+		}
+
+		if part.Verb == 'f' {
+			value = generator.backend.Function("fixed") + "(" + value + ", 6)"
+		}
+
+		items = append(items, value)
+		isNonString = !isString
+	}
+
+	if len(items) == 0 {
+		return []string{"\"\""}
+	}
+
+	return items
+}
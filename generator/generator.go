@@ -2,191 +2,589 @@ package generator
 
 import (
 	"../parser"
+	"../semantic"
 	"fmt"
+	"go/constant"
+	"io"
 	"strconv"
 	"strings"
 )
 
 type Generator struct {
 	syntaxTree parser.File
+	types      map[string]parser.TypeDeclaration
+	imports    map[string]bool
+	functions  map[string]parser.FuncDeclaration
+	current    parser.FuncDeclaration // Procedure which body is generated
+	variables  semantic.Variables     // Variables of all procedures with their types
+	info       *semantic.TypeInfo
+	callGraph  *semantic.CallGraph
+	backend    Backend
+	// Temporaries of the current procedure for switch tags, taken by switch statements in order
+	temporaries []parser.Identifier
+	// Temporaries of the current procedure for discarded outputs, taken by blank targets in order
+	discarded []parser.Identifier
+	sourceMap *SourceMap
+	position  parser.Position // Go statement which is being generated
+	options   GeneratorOptions
+	out       io.Writer
+	written   int   // Bytes written to the output
+	err       error // The first error of the output, nothing is written after it
 }
 
 type index []int
 
-func NewGenerator(ast parser.File) *Generator {
-	return &Generator{ast}
+// Go builtin functions, backends give their names
+var builtins = map[string]bool{
+	"len":    true,
+	"cap":    true,
+	"append": true,
+	"delete": true,
 }
 
+// Go basic types, backends give their names
+var basicTypes = map[string]bool{
+	"int":     true,
+	"float64": true,
+	"string":  true,
+	"bool":    true,
+}
+
+// Go names of basic types inferred by the analyzer
+var semanticTypes = map[semantic.Type]string{
+	semantic.Int:    "int",
+	semantic.Float:  "float64",
+	semantic.String: "string",
+	semantic.Bool:   "bool",
+}
+
+// Values of variables declared without initializer
+var zeroValues = map[string]string{
+	"int":     "0",
+	"float64": "0.0",
+	"string":  "\"\"",
+	"bool":    "false",
+}
+
+// Variables and type info come from the analyzer. Procedures don't get declarations
+// of their variables without them and operators don't depend on types of operands.
+func NewGenerator(
+	ast parser.File,
+	variables semantic.Variables,
+	info *semantic.TypeInfo,
+	callGraph *semantic.CallGraph,
+	backend Backend,
+	options GeneratorOptions,
+) *Generator {
+	types := map[string]parser.TypeDeclaration{}
+	functions := map[string]parser.FuncDeclaration{}
+
+	for _, declaration := range ast.Declarations {
+		if decl, status := declaration.(parser.TypeDeclaration); status {
+			types[decl.Name.Name] = decl
+		} else if decl, status := declaration.(parser.FuncDeclaration); status {
+			functions[decl.Name.Name] = decl
+		}
+	}
+
+	imports := map[string]bool{}
+
+	for _, spec := range ast.Imports {
+		imports[spec.Path[strings.LastIndex(spec.Path, "/")+1:]] = true
+	}
+
+	if configurable, status := backend.(Configurable); status {
+		backend = configurable.Configure(options)
+	}
+
+	generator := &Generator{ast, types, imports, functions, parser.FuncDeclaration{},
+		variables, info, callGraph, backend, nil, nil, nil, parser.Position{}, options, nil, 0, nil}
+
+	if options.SourceMap {
+		generator.enableSourceMap(options.SourceFile, options.SourceComments)
+	}
+
+	return generator
+}
+
+// Whole program is kept in memory, GenerateTo writes it while it's generated
 func (generator *Generator) Generate() string {
-	var genCode string
-	var curIndex = index([]int{1, 1})
+	var builder strings.Builder
+	generator.GenerateTo(&builder)
+
+	return builder.String()
+}
 
-	genCode += fmt.Sprintf("Z1 %s\n", generator.syntaxTree.Package.Name)
+// Program is written in a single pass over the syntax tree.
+// Nothing is written after the first error of the writer, it is returned.
+func (generator *Generator) GenerateTo(out io.Writer) error {
+	var curIndex = generator.options.startIndex()
+	last := len(curIndex) - 1
+
+	generator.out, generator.written, generator.err = out, 0, nil
+
+	if !generator.options.OmitHeader {
+		generator.write(generator.backend.Program(generator.syntaxTree.Package.Name))
+	}
+
+	generator.generateRecursionSummary()
 
 	for _, stmt := range generator.syntaxTree.Declarations {
+		generator.position = statementPosition(stmt)
+
+		if decl, status := stmt.(parser.TypeDeclaration); status {
+			generator.generateTypeDeclaration(decl, curIndex)
+			curIndex[last]++
+			continue
+		}
+
 		function := stmt.(parser.FuncDeclaration)
 
-		header := fmt.Sprintf("%sQ%s PROCEDURE &%s&\n", curIndex.Indentation(), curIndex.String(), function.Name.Name)
-		body := generator.generateStatement(function, append(curIndex, 1))
+		generator.generateProcedureHeader(function, curIndex)
+		generator.generateStatement(function, append(curIndex, 1))
+
+		curIndex[last]++
+	}
+
+	return generator.err
+}
 
-		curIndex[1]++
-		genCode += header + body
+func (generator *Generator) write(str string) {
+	if generator.err != nil {
+		return
 	}
 
-	return genCode
+	n, err := io.WriteString(generator.out, str)
+	generator.written += n
+	generator.err = err
 }
 
-func (generator *Generator) generateStatement(statement parser.Statement, index index) string {
+func (generator *Generator) generateStatement(statement parser.Statement, index index) {
+	// Lines without their own statement, like END of IF, belong to the enclosing one
+	if position := statementPosition(statement); position.Line != 0 {
+		enclosing := generator.position
+		generator.position = position
+		defer func() { generator.position = enclosing }()
+	}
+
 	if stmt, status := statement.(parser.SwitchStatement); status {                  // Switch
-		return generator.generateCaseStatements(stmt.Expression, stmt.Body, index)
+		generator.generateSwitchStatement(stmt, index)
 	} else if stmt, status := statement.(parser.AssignStatement); status {           // Assign
-		return generator.generateAssignStatement(stmt, index)
+		generator.generateAssignStatement(stmt, index)
+	} else if stmt, status := statement.(parser.VarStatement); status {              // Var
+		generator.generateVarStatement(stmt, index)
+	} else if stmt, status := statement.(parser.ExpressionStatement); status {       // Call
+		generator.write(generator.backend.Statement(index, generator.generateCallStatement(stmt.Expression)))
 	} else if stmt, status := statement.(parser.BlockStatement); status {            // Block
-		return generator.generateStatement(stmt.Statements, index)
+		generator.generateStatement(stmt.Statements, index)
 	} else if stmt, status := statement.(parser.BranchStatement); status {
-		return fmt.Sprintf("%sQ%s %s\n",
-			index.Indentation(), index.String(), stmt.Keyword)
+		generator.write(generator.backend.Branch(index, stmt.Keyword))
+	} else if stmt, status := statement.(parser.ReturnStatement); status {           // Return
+		generator.generateReturnStatement(stmt, index)
 	} else if stmt, status := statement.(parser.IfStatement); status {               // If
-		header := fmt.Sprintf("%sQ%s IF %s THEN BEGIN\n",
-			index.Indentation(), index.String(), generator.generateExpression(stmt.Condition))
-		return header + generator.generateIfStatement(stmt, append(index, 1))
+		generator.write(generator.backend.If(index, generator.generateExpression(stmt.Condition)))
+		generator.generateIfStatement(stmt, append(index, 1))
 	} else if decl, status := statement.(parser.FuncDeclaration); status {           // Procedure
-		body := generator.generateStatement(decl.Body, index)
-		closure := fmt.Sprintf("%sQ%s ENDPROC &%s&\n",
-			index.Indentation(), index.String(), decl.Name.Name)
-		return body + closure
+		renamed, declarations := generator.renameVariables(decl)
+		generator.current = renamed
+		generator.temporaries, generator.discarded = generator.temporaryNames(renamed)
+		generator.generateVariables(renamed, declarations, index)
+		generator.generateLocals(renamed, index)
+		generator.generateResultsInitialization(renamed, index)
+		generator.generateStatement(renamed.Body, index)
+		generator.write(generator.backend.EndProcedure(index, generator.generateExpression(decl.Name)))
 	} else if stmts, status := statement.(parser.Statements); status {               // Statements
-		var str string
-
+		// Statements which write nothing don't take an index,
+		// statements of nested blocks move the index themselves
 		for _, stmt := range stmts {
-			newStr := generator.generateStatement(stmt, index)
+			if block, status := stmt.(parser.BlockStatement); status {
+				generator.generateStatement(block, index)
+				continue
+			}
 
-			if newStr != "" {
-				str += generator.generateStatement(stmt, index)
+			written := generator.written
+			generator.generateStatement(stmt, index)
+
+			if generator.written != written {
 				index[len(index)-1]++
 			}
 		}
+	} else {
+		generator.write("!!!Error!!!")
+	}
+}
 
-		return str
+// Several values are assigned at once, all of them are evaluated first:
+//   Q1.1.2. &a&, &b& := &b&, &a&
+func (generator *Generator) generateAssignStatement(assign parser.AssignStatement, index index) {
+	if values, status := assign.Expression.(parser.Expressions); status {
+		var targets parser.Expressions
+
+		for _, target := range assign.Targets {
+			targets = append(targets, generator.discardedTarget(target))
+		}
+
+		generator.write(generator.backend.Assign(index,
+			generator.generateExpressionList(targets), generator.generateExpressionList(values)))
+		return
 	}
 
-	return "!!!Error!!!"
-}
+	if call, status := assign.Expression.(parser.CallExpression); status && len(assign.Targets) > 1 {
+		generator.write(generator.backend.Statement(index, generator.generateCallWithOutputs(call, assign.Targets)))
+		return
+	}
+
+	if len(assign.Targets) > 1 {
+		generator.generateLookupStatement(assign, index)
+		return
+	}
+
+	target := assign.Targets[0]
 
-func (generator *Generator) generateAssignStatement(assign parser.AssignStatement, index index) string {
-	var expr string
-	ident := fmt.Sprintf("%sQ%s %s",
-		index.Indentation(), index.String(), generator.generateExpression(assign.Identifier))
+	ident := generator.generateExpression(target)
 
 	// Basic types should be on the next line like an answer.
 	// But complex unary and binary expressions should be on the same line
 	if lit, status := assign.Expression.(parser.Literal); status {
-		expr = fmt.Sprintf("\n%sA%s %s\n",
-			index.Indentation(), index.String(), generateLiteral(lit))
+		generator.write(generator.backend.Value(index, ident, generator.generateExpression(lit)))
+		return
+	}
+
+	// Elements of arrays and maps created with ':=' are the answer too:
+	//   Q1.1.1. &a&
+	//   A1.1.1. [1, 2, 3]
+	if lit, status := assign.Expression.(parser.CompositeLiteral); status &&
+		assign.Operator == parser.GetType(parser.Define) && isCollectionType(lit.Type) {
+		generator.write(generator.backend.Value(index, ident, generator.generateExpression(lit)))
+		return
+	}
+
+	generator.write(generator.backend.Assign(index, ident, generator.generateExpression(assign.Expression)))
+}
+
+// Map lookup with two targets checks whether the key exists: v, ok := m[k].
+// Both results are written to output parameters of LOOKUP:
+//   Q1.1.1. LOOKUP(&m&, &k&, &v&, &ok&)
+// If one of the targets is blank, simpler form is used:
+//   Q1.1.1. &ok& := CONTAINS(&m&, &k&)
+func (generator *Generator) generateLookupStatement(assign parser.AssignStatement, index index) {
+	lookup := assign.Expression.(parser.IndexExpression)
+	value, found := assign.Targets[0], assign.Targets[1]
+	container := generator.generateExpression(lookup.Expression)
+	key := generator.generateExpression(lookup.Index)
+	backend := generator.backend
+
+	if isBlank(value) && isBlank(found) {
+		return
+	} else if isBlank(value) {
+		generator.write(backend.Assign(index, generator.generateExpression(found),
+			fmt.Sprintf("%s(%s, %s)", backend.Function("contains"), container, key)))
+	} else if isBlank(found) {
+		generator.write(backend.Assign(index, generator.generateExpression(value), fmt.Sprintf("%s[%s]", container, key)))
 	} else {
-		expr = fmt.Sprintf(" := %s\n",
-			generator.generateExpression(assign.Expression))
+		generator.write(backend.Statement(index, fmt.Sprintf("%s(%s, %s, %s, %s)", backend.Function("lookup"),
+			container, key, generator.generateExpression(value), generator.generateExpression(found))))
 	}
+}
 
-	return ident + expr
+// Declared variables without initializer get zero value of their type.
+// Nil maps keep the value they get in VAR block, so nothing is written for them.
+func (generator *Generator) generateVarStatement(stmt parser.VarStatement, index index) {
+	zero := generator.generateZeroValue(stmt.Type)
+
+	if stmt.Expression == nil && zero == "" {
+		return
+	}
+
+	if stmt.Expression != nil {
+		generator.generateAssignStatement(parser.AssignStatement{
+			Targets: parser.Expressions{stmt.Identifier}, Operator: parser.GetType(parser.Define),
+			Expression: stmt.Expression}, index)
+	} else {
+		generator.write(generator.backend.Value(index, generator.generateExpression(stmt.Identifier), zero))
+	}
 }
 
 func (generator *Generator) generateExpression(expression parser.Expression) string {
+	if value, status := generator.floatConstant(expression); status {
+		return value
+	}
+
 	if expr, status := expression.(parser.UnaryExpression); status {
-		operand := generator.generateExpression(expr.Operand)
-		return expr.Operator + " " + operand
+		return generator.unaryOperator(expr.Operator) + " " +
+			generator.generateOperand(expr.Operand, parser.UnaryPrecedence)
 	} else if expr, status := expression.(parser.BinaryExpression); status {
-		left := generator.generateExpression(expr.LeftOperand)
-		right := generator.generateExpression(expr.RightOperand)
-		return left + " " + expr.Operator + " " + right
+		prec := parser.Precedence(expr.Operator)
+		left := generator.generateOperand(expr.LeftOperand, prec)
+		right := generator.generateOperand(expr.RightOperand, prec+1)
+
+		// There's no AND NOT operation, so a &^ b is written as a & (^b)
+		if expr.Operator == parser.GetType(parser.AndNot) {
+			return left + " " + generator.binaryOperator(parser.GetType(parser.BitAnd), "") + " (" +
+				generator.unaryOperator(parser.GetType(parser.BitXor)) + " " +
+				generator.generateOperand(expr.RightOperand, parser.UnaryPrecedence) + ")"
+		}
+
+		return left + " " + generator.binaryOperator(expr.Operator, generator.basicType(expr)) + " " + right
 	} else if lit, status := expression.(parser.Literal); status {
 		return generateLiteral(lit)
 	} else if ident, status := expression.(parser.Identifier); status {
-		return "&" + ident.Name + "&"
+		return generator.backend.Identifier(ident.Name)
+	} else if expr, status := expression.(parser.IndexExpression); status {
+		return generator.generateExpression(expr.Expression) +
+			"[" + generator.generateExpression(expr.Index) + "]"
+	} else if call, status := expression.(parser.CallExpression); status {
+		return generator.generateCallExpression(call)
+	} else if lit, status := expression.(parser.CompositeLiteral); status {
+		return generator.generateCompositeLiteral(lit)
+	} else if expr, status := expression.(parser.SelectorExpression); status {
+		return generator.generateExpression(expr.Expression) + "." + generator.generateExpression(expr.Selector)
+	} else if expr, status := expression.(parser.KeyValueExpression); status {
+		return generator.generateExpression(expr.Key) + ": " + generator.generateExpression(expr.Value)
+	}
+
+	return "!!!Error!!!"
+}
+
+// Operand is put in parentheses if its operator has lower precedence
+// than the operator it's used with, so the order of evaluation is kept:
+//   (a + b) * c, a - (b - c), -(a + b)
+func (generator *Generator) generateOperand(operand parser.Expression, prec int) string {
+	str := generator.generateExpression(operand)
+
+	if expr, status := operand.(parser.BinaryExpression); status && parser.Precedence(expr.Operator) < prec {
+		return "(" + str + ")"
+	}
+
+	return str
+}
+
+// Operator has the spelling for operands of the type, if there's one: 7 / 2 is 7 DIV 2
+func (generator *Generator) binaryOperator(operator string, typ string) string {
+	operators := generator.backend.Operators()
+
+	if str, status := operators.Typed[typ][operator]; status {
+		return str
+	}
+
+	if str, status := operators.Binary[operator]; status {
+		return str
+	}
+
+	return "!!!Error!!!"
+}
+
+// Go name of the basic type of expression found by the analyzer, empty if it's not basic
+func (generator *Generator) basicType(expression parser.Expression) string {
+	return semanticTypes[generator.info.UnderlyingTypeOf(expression)]
+}
+
+func (generator *Generator) unaryOperator(operator string) string {
+	if str, status := generator.backend.Operators().Unary[operator]; status {
+		return str
+	}
+
+	return "!!!Error!!!"
+}
+
+// Builtins are called by their names in the target: LENGTH(&a&)
+func (generator *Generator) generateCallExpression(call parser.CallExpression) string {
+	if selector, status := call.Function.(parser.SelectorExpression); status {
+		if pkg, status := selector.Expression.(parser.Identifier); status && generator.imports[pkg.Name] {
+			return generator.generatePackageCall(pkg.Name, selector.Selector.Name, call.Arguments)
+		}
+	}
+
+	function := generator.generateExpression(call.Function)
+
+	if ident, status := call.Function.(parser.Identifier); status {
+		if builtins[ident.Name] {
+			function = generator.backend.Function(ident.Name)
+		} else if generator.isTypeName(ident) && len(call.Arguments) == 1 {
+			return generator.generateConversion(ident, call.Arguments[0])
+		}
+	}
+
+	return function + "(" + generator.generateExpressionList(call.Arguments) + ")"
+}
+
+// Functions of fmt are statements, others are standard functions: SQRT(&x&)
+func (generator *Generator) generatePackageCall(pkg string, name string, args parser.Expressions) string {
+	if pkg == semantic.Fmt {
+		return generator.generateFmtCall(name, args)
+	}
+
+	if semantic.IsStandardFunction(pkg, name) {
+		return generator.backend.Function(pkg+"."+name) + "(" + generator.generateExpressionList(args) + ")"
 	}
 
 	return "!!!Error!!!"
 }
 
-func (generator *Generator) generateIfStatement(stmt parser.IfStatement, index index) string {
-	ifBody := generator.generateStatement(stmt.IfBody, index)
-	var elseBody string
+func (generator *Generator) generateExpressionList(expressions parser.Expressions) string {
+	return strings.Join(generator.generateExpressions(expressions), ", ")
+}
+
+func (generator *Generator) generateExpressions(expressions parser.Expressions) []string {
+	var list []string
+
+	for _, expr := range expressions {
+		list = append(list, generator.generateExpression(expr))
+	}
+
+	return list
+}
+
+func (generator *Generator) generateIfStatement(stmt parser.IfStatement, index index) {
+	generator.generateStatement(stmt.IfBody, index)
 
 	if stmt.ElseBody.Statements != nil {
-		elseBody = fmt.Sprintf("%sQ%s END ELSE BEGIN\n",
-			index.Indentation(), index.String())
+		generator.write(generator.backend.Else(index))
 		index[len(index)-1]++
-		elseBody += generator.generateStatement(stmt.ElseBody, index)
-	} else {
-		elseBody = generator.generateStatement(stmt.ElseBody, index)
 	}
 
-	closure := fmt.Sprintf("%sQ%s END\n", index.Indentation(), index.String())
-	return ifBody + elseBody + closure
+	generator.generateStatement(stmt.ElseBody, index)
+	generator.write(generator.backend.EndIf(index))
 }
 
-func (generator *Generator) generateCaseStatements(
-	parentExpr parser.Expression,
-	stmts parser.CaseStatements,
-	index index,
-) string {
-	var expr parser.Expression
-	var ifs []parser.IfStatement
-	var str string
-
-	for _, stmt := range stmts {
-		if !isExpressionNil(parentExpr) {
-			expr = parser.BinaryExpression{
-				LeftOperand: parentExpr, Operator: "==", RightOperand: stmt.Expression}
-		} else { // Switch without condition
-			expr = stmt.Expression
+// Switch is a flat chain of conditions checked in order, one for each case.
+// Default case is the last branch, wherever it's written.
+// Tag other than variable or literal is evaluated once into a temporary:
+//   Q1.1.2. &switch1& := &f&(&a&)
+//   Q1.1.3. IF &switch1& = 1 THEN BEGIN
+//     Q1.1.3.1. &b& := 1
+//     Q1.1.3.2. END ELSE IF &switch1& = 2 THEN BEGIN
+//     Q1.1.3.3. &b& := 2
+//     Q1.1.3.4. END ELSE BEGIN
+//     Q1.1.3.5. &b& := 3
+//     Q1.1.3.6. END
+func (generator *Generator) generateSwitchStatement(stmt parser.SwitchStatement, index index) {
+	var cases []parser.CaseStatement
+	var defaultCase *parser.CaseStatement
+	tag := stmt.Expression
+
+	for i, caseStmt := range stmt.Body {
+		if isExpressionNil(caseStmt.Expression) {
+			defaultCase = &stmt.Body[i]
+		} else {
+			cases = append(cases, caseStmt)
 		}
+	}
 
-		body := stmt.Body
-		curIf := parser.IfStatement{
-			Condition: expr, IfBody: body, ElseBody: parser.BlockStatement{}}
+	if !isExpressionNil(tag) && !isSimpleExpression(tag) {
+		temporary := generator.temporaries[0]
+		generator.temporaries = generator.temporaries[1:]
 
-		if isExpressionNil(stmt.Expression){ // If 'default' case
-			curIf.Condition = parser.Literal{Type: parser.BooleanLiteral, Value: true}
+		generator.write(generator.backend.Assign(index,
+			generator.generateExpression(temporary), generator.generateExpression(tag)))
+		tag = temporary
+
+		if len(stmt.Body) == 0 {
+			return
 		}
 
-		ifs = append(ifs, curIf)
+		index[len(index)-1]++
+	} else if len(stmt.Body) == 0 {
+		return
 	}
 
+	// Switch with default case only
+	if len(cases) == 0 {
+		cases = append(cases, *defaultCase)
+		defaultCase = nil
+	}
 
-	for i := len(ifs) - 1; i > 0; i-- {
-		tmpStmt := parser.BlockStatement{}
-		tmpStmt.Statements = append(tmpStmt.Statements, ifs[i])
-		ifs[i-1].ElseBody = tmpStmt
+	// Conditions of the cases come from the lines of the cases
+	position := generator.position
+	generator.position = cases[0].Position
+	generator.write(generator.backend.If(index, generator.generateExpression(caseCondition(tag, cases[0]))))
+	branchIndex := append(index, 1)
+	generator.generateStatement(cases[0].Body, branchIndex)
+
+	for _, caseStmt := range cases[1:] {
+		generator.position = caseStmt.Position
+		generator.write(generator.backend.ElseIf(branchIndex, generator.generateExpression(caseCondition(tag, caseStmt))))
+		branchIndex[len(branchIndex)-1]++
+		generator.generateStatement(caseStmt.Body, branchIndex)
 	}
 
-	if len(ifs) > 0 {
-		str += generator.generateStatement(ifs[0], index)
+	if defaultCase != nil {
+		generator.position = defaultCase.Position
+		generator.write(generator.backend.Else(branchIndex))
+		branchIndex[len(branchIndex)-1]++
+		generator.generateStatement(defaultCase.Body, branchIndex)
 	}
 
-	return str
+	generator.position = position
+	generator.write(generator.backend.EndIf(branchIndex))
 }
 
+// Case is compared with tag. Switch without tag has conditions as cases.
+// Default case is always true.
+func caseCondition(tag parser.Expression, caseStmt parser.CaseStatement) parser.Expression {
+	if isExpressionNil(caseStmt.Expression) {
+		return parser.Literal{Type: parser.BooleanLiteral, Value: true}
+	} else if isExpressionNil(tag) {
+		return caseStmt.Expression
+	}
+
+	return parser.BinaryExpression{LeftOperand: tag, Operator: "==", RightOperand: caseStmt.Expression}
+}
+
+// Float constants are written as their values, the way Go computes them, since reals aren't exact:
+//   var c int = 2.5 * 2 -> 5
+//   f := 0.1 + 0.2      -> 0.3
+func (generator *Generator) floatConstant(expression parser.Expression) (string, bool) {
+	x := semantic.ConstantValue(expression)
+
+	if x == nil || x.Kind() != constant.Float {
+		return "", false
+	}
+
+	if generator.basicType(expression) == "int" {
+		return constant.ToInt(x).String(), true
+	}
+
+	if _, status := expression.(parser.BinaryExpression); status {
+		f, _ := constant.Float64Val(x)
+		return generateLiteral(parser.Literal{Type: parser.FloatLiteral, Value: f}), true
+	}
+
+	return "", false
+}
+
+// Reals keep their point, so they aren't read back as integers: 2.0, 1e+21
 func generateLiteral(literal parser.Literal) string {
 	if str, status := literal.Value.(string); status {
 		return "\"" + str + "\""
 	}
 
+	if value, status := literal.Value.(float64); status {
+		str := strconv.FormatFloat(value, 'g', -1, 64)
+
+		if !strings.ContainsAny(str, ".e") {
+			str += ".0"
+		}
+
+		return str
+	}
+
 	return fmt.Sprintf("%v", literal.Value)
 }
 
-func (index index) Indentation() string  {
-	return strings.Repeat("\t", len(index) - 1)
+func isBlank(expression parser.Expression) bool {
+	ident, status := expression.(parser.Identifier)
+	return status && ident.Name == "_"
 }
 
-func (index index) String() string {
-	var str string
-
-	for _, i := range index {
-		str += strconv.Itoa(i) + "."
+// Variables and literals can be evaluated several times without any effect
+func isSimpleExpression(expression parser.Expression) bool {
+	switch expression.(type) {
+	case parser.Identifier, parser.Literal:
+		return true
 	}
 
-	return str
+	return false
 }
 
 func isExpressionNil(expression parser.Expression) bool {
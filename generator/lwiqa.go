@@ -0,0 +1,322 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Statements of LWIQA are numbered questions, literal values are answers to them:
+//   Z1 main
+//   Q1.1. PROCEDURE &main&
+//     Q1.1.1. &a&
+//     A1.1.1. 2
+//     Q1.1.2. &b& := &a& * 2
+//     Q1.1.3. ENDPROC &main&
+// Zero value is the default layout, other layouts are set with options.
+type LWIQA struct {
+	IndentWidth       int // Spaces for each level of nesting, tabs if zero
+	LowercaseKeywords bool
+	PlainIdentifiers  bool
+}
+
+// LWIQA names of Go builtin and standard functions
+var lwiqaFunctions = map[string]string{
+	"len":        "LENGTH",
+	"cap":        "CAPACITY",
+	"append":     "APPEND",
+	"delete":     "DELETE",
+	"lookup":     "LOOKUP",
+	"contains":   "CONTAINS",
+	"fixed":      "FIXED",
+	"math.Sqrt":  "SQRT",
+	"math.Abs":   "ABS",
+	"math.Pow":   "POWER",
+	"math.Floor": "FLOOR",
+	"math.Max":   "MAX",
+	"math.Min":   "MIN",
+}
+
+// LWIQA operators. Logical operators, equality and bit operations are words:
+//   !(a == b) || c % 2 != 0 -> NOT (&a& = &b&) OR &c& MOD 2 <> 0
+// There's no AND NOT operation, so a &^ b is written as &a& BITAND (BITNOT &b&).
+var lwiqaOperators = OperatorTable{
+	Binary: map[string]string{
+		"||": "OR",
+		"&&": "AND",
+		"==": "=",
+		"!=": "<>",
+		"<":  "<",
+		"<=": "<=",
+		">":  ">",
+		">=": ">=",
+		"+":  "+",
+		"-":  "-",
+		"|":  "BITOR",
+		"^":  "BITXOR",
+		"*":  "*",
+		"/":  "/",
+		"%":  "MOD",
+		"<<": "SHL",
+		">>": "SHR",
+		"&":  "BITAND",
+	},
+	Unary: map[string]string{
+		"+": "+",
+		"-": "-",
+		"!": "NOT",
+		"^": "BITNOT",
+	},
+	Typed: map[string]map[string]string{
+		"int":    {"/": "DIV"},
+		"string": {"+": "CONCAT"},
+	},
+}
+
+// LWIQA names of Go basic types
+var lwiqaTypeNames = map[string]string{
+	"int":     "INTEGER",
+	"float64": "REAL",
+	"string":  "STRING",
+	"bool":    "BOOLEAN",
+}
+
+func (LWIQA) Configure(options GeneratorOptions) Backend {
+	return LWIQA{options.IndentWidth, options.LowercaseKeywords, options.PlainIdentifiers}
+}
+
+func (LWIQA) Program(name string) string {
+	return fmt.Sprintf("Z1 %s\n", name)
+}
+
+func (LWIQA) Comment(text string) string {
+	return "// " + text + "\n"
+}
+
+func (backend LWIQA) Procedure(index []int, name string, params []Parameter, result string, recursive bool) string {
+	var kind, list string
+
+	if recursive {
+		kind = backend.keyword("RECURSIVE") + " "
+	}
+
+	if len(params) > 0 {
+		list = "(" + backend.parameters(params, ", ") + ")"
+	}
+
+	if result != "" {
+		result = " : " + result
+	}
+
+	return backend.question(index, kind+backend.keyword("PROCEDURE")+" "+name+list+result)
+}
+
+func (backend LWIQA) EndProcedure(index []int, name string) string {
+	return backend.question(index, backend.keyword("ENDPROC")+" "+name)
+}
+
+func (backend LWIQA) Locals(index []int, names []string) string {
+	return backend.question(index, backend.keyword("LOCAL")+" "+strings.Join(names, ", "))
+}
+
+func (backend LWIQA) Variables(index []int, variables []Parameter) string {
+	str := backend.question(index, backend.keyword("VAR"))
+	variableIndex := append(append([]int{}, index...), 1)
+
+	for _, variable := range variables {
+		str += backend.question(variableIndex, backend.parameters([]Parameter{variable}, ""))
+		variableIndex[len(variableIndex)-1]++
+	}
+
+	return str + backend.question(variableIndex, backend.keyword("ENDVAR"))
+}
+
+func (backend LWIQA) Record(index []int, name string, fields []Parameter) string {
+	str := backend.question(index, backend.keyword("RECORD")+" "+name)
+	fieldIndex := append(append([]int{}, index...), 1)
+
+	for _, field := range fields {
+		str += backend.question(fieldIndex, backend.parameters([]Parameter{field}, ""))
+		fieldIndex[len(fieldIndex)-1]++
+	}
+
+	return str + backend.question(fieldIndex, backend.keyword("ENDREC")+" "+name)
+}
+
+func (backend LWIQA) TypeDeclaration(index []int, name string, typ string) string {
+	return backend.question(index, backend.keyword("TYPE")+" "+name+" = "+typ)
+}
+
+func (backend LWIQA) Value(index []int, target string, value string) string {
+	return backend.question(index, target) + backend.answer(index, value)
+}
+
+func (backend LWIQA) Assign(index []int, target string, value string) string {
+	return backend.question(index, target+" := "+value)
+}
+
+func (backend LWIQA) Statement(index []int, text string) string {
+	return backend.question(index, text)
+}
+
+func (backend LWIQA) Return(index []int, value string) string {
+	return backend.question(index, strings.TrimSpace(backend.keyword("RETURN")+" "+value))
+}
+
+// Keyword of Go is written in the case of other keywords: BREAK, CONTINUE
+func (backend LWIQA) Branch(index []int, keyword string) string {
+	return backend.question(index, backend.keyword(strings.ToUpper(keyword)))
+}
+
+func (backend LWIQA) If(index []int, condition string) string {
+	return backend.question(index, backend.keywords("IF %s THEN BEGIN", condition))
+}
+
+func (backend LWIQA) ElseIf(index []int, condition string) string {
+	return backend.question(index, backend.keywords("END ELSE IF %s THEN BEGIN", condition))
+}
+
+func (backend LWIQA) Else(index []int) string {
+	return backend.question(index, backend.keyword("END ELSE BEGIN"))
+}
+
+func (backend LWIQA) EndIf(index []int) string {
+	return backend.question(index, backend.keyword("END"))
+}
+
+// OUTPUT writes values like fmt.Print, adding spaces between operands
+// when neither is a string. OUTPUTLN separates all operands with spaces
+// and ends the line, the same as fmt.Println.
+func (backend LWIQA) Output(items []string, newline bool) string {
+	if newline {
+		return strings.TrimSpace(backend.keyword("OUTPUTLN") + " " + strings.Join(items, ", "))
+	}
+
+	return backend.keyword("OUTPUT") + " " + strings.Join(items, ", ")
+}
+
+func (backend LWIQA) Input(targets []string) string {
+	return backend.keyword("INPUT") + " " + strings.Join(targets, ", ")
+}
+
+func (backend LWIQA) Identifier(name string) string {
+	if backend.PlainIdentifiers {
+		return name
+	}
+
+	return "&" + name + "&"
+}
+
+// Operators written as words follow the case of keywords: NOT, MOD
+func (backend LWIQA) Operators() OperatorTable {
+	if !backend.LowercaseKeywords {
+		return lwiqaOperators
+	}
+
+	operators := OperatorTable{map[string]string{}, map[string]string{}, map[string]map[string]string{}}
+
+	for operator, str := range lwiqaOperators.Binary {
+		operators.Binary[operator] = backend.keyword(str)
+	}
+
+	for operator, str := range lwiqaOperators.Unary {
+		operators.Unary[operator] = backend.keyword(str)
+	}
+
+	for typ, typed := range lwiqaOperators.Typed {
+		operators.Typed[typ] = map[string]string{}
+
+		for operator, str := range typed {
+			operators.Typed[typ][operator] = backend.keyword(str)
+		}
+	}
+
+	return operators
+}
+
+func (backend LWIQA) Function(name string) string {
+	return backend.keyword(lwiqaFunctions[name])
+}
+
+func (backend LWIQA) TypeName(name string) string {
+	return backend.keyword(lwiqaTypeNames[name])
+}
+
+// Nested arrays are written as: ARRAY[2] OF ARRAY[3] OF INTEGER
+func (backend LWIQA) ArrayType(length string, element string) string {
+	return backend.keywords("ARRAY[%s] OF %s", length, element)
+}
+
+func (backend LWIQA) MapType(key string, value string) string {
+	return backend.keywords("MAP[%s] OF %s", key, value)
+}
+
+func (backend LWIQA) RecordType(fields []Parameter) string {
+	return backend.keyword("RECORD") + "(" + backend.parameters(fields, "; ") + ")"
+}
+
+// Records are created with their type name and field values: &Point&(1, 2)
+func (backend LWIQA) RecordLiteral(name string, elements string) string {
+	if name == "" {
+		return backend.keyword("RECORD") + "(" + elements + ")"
+	}
+
+	return name + "(" + elements + ")"
+}
+
+func (backend LWIQA) parameters(params []Parameter, separator string) string {
+	var list []string
+
+	for _, param := range params {
+		str := param.Name + " : " + param.Type
+
+		if param.Output {
+			str = backend.keyword("OUT") + " " + str
+		}
+
+		list = append(list, str)
+	}
+
+	return strings.Join(list, separator)
+}
+
+func (backend LWIQA) question(index []int, text string) string {
+	return fmt.Sprintf("%sQ%s %s\n", backend.indentation(index), numbering(index), text)
+}
+
+func (backend LWIQA) answer(index []int, text string) string {
+	return fmt.Sprintf("%sA%s %s\n", backend.indentation(index), numbering(index), text)
+}
+
+func (backend LWIQA) indentation(index []int) string {
+	if backend.IndentWidth > 0 {
+		return strings.Repeat(" ", backend.IndentWidth*(len(index)-1))
+	}
+
+	return strings.Repeat("\t", len(index)-1)
+}
+
+func (backend LWIQA) keyword(keyword string) string {
+	if backend.LowercaseKeywords {
+		return strings.ToLower(keyword)
+	}
+
+	return keyword
+}
+
+// Keywords of the format are in the case of keywords, values are left as they are:
+// IF %s THEN BEGIN
+func (backend LWIQA) keywords(format string, values ...interface{}) string {
+	return fmt.Sprintf(backend.keyword(format), values...)
+}
+
+// Index written with dots: 1.2.3.
+func numbering(index []int) string {
+	var str string
+
+	for _, i := range index {
+		str += strconv.Itoa(i) + "."
+	}
+
+	return str
+}
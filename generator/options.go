@@ -0,0 +1,35 @@
+package generator
+
+// Layout of generated code. Zero value gives the default LWIQA layout:
+//   Z1 main
+//   	Q1.1. PROCEDURE &main&
+//   		Q1.1.1. &a& := &b& * 2
+type GeneratorOptions struct {
+	IndentWidth       int   // Spaces for each level of nesting, tabs if zero
+	StartIndex        []int // Index of the first declaration, 1.1. if empty
+	OmitHeader        bool  // Program header with package name: Z1 main
+	LowercaseKeywords bool  // procedure, if, then instead of PROCEDURE, IF, THEN
+	PlainIdentifiers  bool  // Identifiers without '&': a := b * 2
+
+	// Source map is produced for the file if it's set,
+	// lines of the file can also be written after generated statements
+	SourceMap      bool
+	SourceFile     string
+	SourceComments bool
+}
+
+// Backends which can change their layout with options.
+// Options which don't make sense for the backend are ignored.
+type Configurable interface {
+	Configure(options GeneratorOptions) Backend
+}
+
+var defaultStartIndex = []int{1, 1}
+
+func (options GeneratorOptions) startIndex() index {
+	if len(options.StartIndex) == 0 {
+		return append(index{}, defaultStartIndex...)
+	}
+
+	return append(index{}, options.StartIndex...)
+}
@@ -0,0 +1,404 @@
+package generator
+
+import (
+	"../parser"
+	"../semantic"
+	"strconv"
+	"strings"
+)
+
+// Parameters are written with their types, result type follows them:
+//   Q1.1. PROCEDURE &gcd&(&a& : INTEGER, &b& : INTEGER) : INTEGER
+// Several results are output parameters assigned before returning:
+//   Q1.2. PROCEDURE &divmod&(&a& : INTEGER, &b& : INTEGER, OUT &q& : INTEGER, OUT &r& : INTEGER)
+// Procedures which can be invoked while they're still running are recursive:
+//   Q1.3. RECURSIVE PROCEDURE &fact&(&n& : INTEGER) : INTEGER
+func (generator *Generator) generateProcedureHeader(decl parser.FuncDeclaration, index index) {
+	var params []Parameter
+	var result string
+
+	for _, param := range decl.Parameters {
+		for _, name := range param.Names {
+			params = append(params, Parameter{
+				Name: generator.generateExpression(name), Type: generator.generateType(param.Type)})
+		}
+	}
+
+	if results := resultTypes(decl); len(results) == 1 {
+		result = generator.generateType(results[0])
+	} else {
+		for i, name := range generator.resultNames(decl) {
+			params = append(params, Parameter{
+				Name: generator.generateExpression(name), Type: generator.generateType(results[i]), Output: true})
+		}
+	}
+
+	generator.write(generator.backend.Procedure(index, generator.generateExpression(decl.Name), params, result,
+		generator.callGraph.IsRecursive(decl.Name.Name)))
+}
+
+// Every invocation of recursive procedure gets its own copies
+// of variables declared in it, listed at the beginning of the body:
+//   Q1.2.1. LOCAL &r&, &i&
+// Index is moved to the next statement if the line is written.
+func (generator *Generator) generateLocals(decl parser.FuncDeclaration, index index) {
+	if !generator.callGraph.IsRecursive(decl.Name.Name) {
+		return
+	}
+
+	var names []string
+	declared := map[string]bool{}
+	idents := append(append(collectDeclarations(decl.Body), generator.temporaries...), generator.discarded...)
+
+	// The only named result is a local variable too: func f() (n int)
+	if len(resultTypes(decl)) == 1 && decl.Results[0].Names != nil {
+		idents = append(generator.resultNames(decl), idents...)
+	}
+
+	for _, ident := range idents {
+		if !declared[ident.Name] && !isBlank(ident) {
+			declared[ident.Name] = true
+			names = append(names, generator.generateExpression(ident))
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	generator.write(generator.backend.Locals(index, names))
+	index[len(index)-1]++
+}
+
+// Named results start with zero values of their types:
+//   Q1.2.1. &q&
+//   A1.2.1. 0
+// Index is moved to the next statement for each result.
+func (generator *Generator) generateResultsInitialization(decl parser.FuncDeclaration, index index) {
+	for _, result := range decl.Results {
+		for _, name := range result.Names {
+			if isBlank(name) {
+				continue
+			}
+
+			generator.generateVarStatement(parser.VarStatement{Identifier: name, Type: result.Type}, index)
+			index[len(index)-1]++
+		}
+	}
+}
+
+// Return from procedure with several results assigns output parameters first:
+//   Q1.2.3. &q&, &r& := &a& / &b&, &a& % &b&
+//   Q1.2.4. RETURN
+// Results of other procedure are passed to it as outputs: return divmod(a, b)
+//   Q1.2.3. &divmod&(&a&, &b&, &q&, &r&)
+// Index is moved to RETURN if assignment is written.
+func (generator *Generator) generateReturnStatement(stmt parser.ReturnStatement, index index) {
+	decl := generator.current
+	results := generator.resultNames(decl)
+	values := stmt.Results
+
+	if len(results) == 1 && len(values) == 0 && decl.Results[0].Names != nil {
+		values = parser.Expressions{results[0]} // Named result: func f() (n int)
+	}
+
+	if len(results) < 2 || len(values) == 0 {
+		if len(values) == 0 {
+			generator.write(generator.backend.Return(index, ""))
+		} else {
+			generator.write(generator.backend.Return(index, generator.generateExpression(values[0])))
+		}
+
+		return
+	}
+
+	var targets parser.Expressions
+
+	for _, name := range results {
+		targets = append(targets, name)
+	}
+
+	if call, status := values[0].(parser.CallExpression); status && len(values) == 1 {
+		generator.write(generator.backend.Statement(index, generator.generateCallWithOutputs(call, targets)))
+		index[len(index)-1]++
+	} else if generator.generateExpressionList(targets) != generator.generateExpressionList(values) {
+		generator.write(generator.backend.Assign(index,
+			generator.generateExpressionList(targets), generator.generateExpressionList(values)))
+		index[len(index)-1]++
+	}
+
+	generator.write(generator.backend.Return(index, ""))
+}
+
+// Calls used as statements get temporaries as outputs for results of procedure,
+// if it has several of them: &divmod&(&a&, &b&, &discarded1&, &discarded2&)
+func (generator *Generator) generateCallStatement(expression parser.Expression) string {
+	call, status := expression.(parser.CallExpression)
+
+	if !status {
+		return generator.generateExpression(expression)
+	}
+
+	var outputs parser.Expressions
+
+	if ident, status := call.Function.(parser.Identifier); status {
+		if decl, status := generator.functions[ident.Name]; status && len(resultTypes(decl)) > 1 {
+			for range resultTypes(decl) {
+				outputs = append(outputs, parser.Identifier{Name: "_"})
+			}
+		}
+	}
+
+	return generator.generateCallWithOutputs(call, outputs)
+}
+
+// Targets of the call are passed after its arguments: q, r := divmod(a, b)
+//   Q1.1.3. &divmod&(&a&, &b&, &q&, &r&)
+// Blank targets are replaced with the next temporaries for discarded outputs.
+func (generator *Generator) generateCallWithOutputs(call parser.CallExpression, outputs parser.Expressions) string {
+	args := append(parser.Expressions{}, call.Arguments...)
+
+	for _, output := range outputs {
+		args = append(args, generator.discardedTarget(output))
+	}
+
+	return generator.generateCallExpression(parser.CallExpression{Function: call.Function, Arguments: args})
+}
+
+// Target itself if it isn't blank, otherwise the next temporary for discarded outputs
+func (generator *Generator) discardedTarget(target parser.Expression) parser.Expression {
+	if !isBlank(target) || len(generator.discarded) == 0 {
+		return target
+	}
+
+	temporary := generator.discarded[0]
+	generator.discarded = generator.discarded[1:]
+
+	return temporary
+}
+
+// Types of all results in order: (q, r int) -> int, int
+func resultTypes(decl parser.FuncDeclaration) []parser.Expression {
+	var types []parser.Expression
+
+	for _, result := range decl.Results {
+		if len(result.Names) == 0 {
+			types = append(types, result.Type)
+		}
+
+		for range result.Names {
+			types = append(types, result.Type)
+		}
+	}
+
+	return types
+}
+
+// Names of results in order. Unnamed results get names
+// which are not used in the procedure: &result1&, &result2&
+func (generator *Generator) resultNames(decl parser.FuncDeclaration) []parser.Identifier {
+	var names []parser.Identifier
+	used := usedNames(decl)
+
+	for _, result := range decl.Results {
+		if len(result.Names) > 0 {
+			names = append(names, result.Names...)
+			continue
+		}
+
+		name := "result" + strconv.Itoa(len(names)+1)
+
+		for used[name] {
+			name += "_"
+		}
+
+		names = append(names, parser.Identifier{Name: name})
+	}
+
+	return names
+}
+
+// Temporaries for switch tags other than variables and literals,
+// in order of switch statements: &switch1&, &switch2&,
+// and for discarded outputs of calls and values: &discarded1&, &discarded2&.
+// Names used in the procedure get '_' suffix.
+func (generator *Generator) temporaryNames(decl parser.FuncDeclaration) ([]parser.Identifier, []parser.Identifier) {
+	var tags, discarded []parser.Identifier
+	used := usedNames(decl)
+
+	for _, result := range generator.resultNames(decl) {
+		used[result.Name] = true
+	}
+
+	for range collectSwitchTags(decl.Body) {
+		tags = append(tags, unusedName("switch"+strconv.Itoa(len(tags)+1), used))
+	}
+
+	for range generator.collectDiscarded(decl.Body) {
+		discarded = append(discarded, unusedName("discarded"+strconv.Itoa(len(discarded)+1), used))
+	}
+
+	return tags, discarded
+}
+
+func unusedName(name string, used map[string]bool) parser.Identifier {
+	for used[name] {
+		name += "_"
+	}
+
+	return parser.Identifier{Name: name}
+}
+
+// Names of parameters, named results and variables declared in the procedure
+func usedNames(decl parser.FuncDeclaration) map[string]bool {
+	used := map[string]bool{}
+
+	for _, field := range append(append([]parser.Field{}, decl.Parameters...), decl.Results...) {
+		for _, name := range field.Names {
+			used[name.Name] = true
+		}
+	}
+
+	for _, ident := range collectDeclarations(decl.Body) {
+		used[ident.Name] = true
+	}
+
+	return used
+}
+
+// Switch tags which need temporaries, in order they are generated
+func collectSwitchTags(statement parser.Statement) []parser.Expression {
+	var tags []parser.Expression
+
+	if stmt, status := statement.(parser.BlockStatement); status {
+		tags = collectSwitchTags(stmt.Statements)
+	} else if stmts, status := statement.(parser.Statements); status {
+		for _, stmt := range stmts {
+			tags = append(tags, collectSwitchTags(stmt)...)
+		}
+	} else if stmt, status := statement.(parser.IfStatement); status {
+		tags = append(collectSwitchTags(stmt.IfBody), collectSwitchTags(stmt.ElseBody)...)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {
+		if !isExpressionNil(stmt.Expression) && !isSimpleExpression(stmt.Expression) {
+			tags = append(tags, stmt.Expression)
+		}
+
+		for _, body := range caseBodies(stmt) {
+			tags = append(tags, collectSwitchTags(body)...)
+		}
+	}
+
+	return tags
+}
+
+// Types of outputs and values which are assigned to blank identifiers, in order they are generated:
+//   divmod(a, b)
+//   q, _ := divmod(a, b)
+//   a, _ = 1, f()
+func (generator *Generator) collectDiscarded(statement parser.Statement) []semantic.Type {
+	var types []semantic.Type
+
+	if stmt, status := statement.(parser.ExpressionStatement); status {
+		if tuple, status := generator.info.TypeOf(stmt.Expression).(*semantic.Tuple); status {
+			types = tuple.Types
+		}
+	} else if stmt, status := statement.(parser.AssignStatement); status && len(stmt.Targets) > 1 {
+		tuple, isTuple := generator.info.TypeOf(stmt.Expression).(*semantic.Tuple)
+		values, isValues := stmt.Expression.(parser.Expressions)
+
+		for i, target := range stmt.Targets {
+			if isBlank(target) && isTuple {
+				types = append(types, tuple.Types[i])
+			} else if isBlank(target) && isValues {
+				types = append(types, generator.info.TypeOf(values[i]))
+			}
+		}
+	} else if stmt, status := statement.(parser.BlockStatement); status {
+		types = generator.collectDiscarded(stmt.Statements)
+	} else if stmts, status := statement.(parser.Statements); status {
+		for _, stmt := range stmts {
+			types = append(types, generator.collectDiscarded(stmt)...)
+		}
+	} else if stmt, status := statement.(parser.IfStatement); status {
+		types = append(generator.collectDiscarded(stmt.IfBody), generator.collectDiscarded(stmt.ElseBody)...)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {
+		for _, body := range caseBodies(stmt) {
+			types = append(types, generator.collectDiscarded(body)...)
+		}
+	}
+
+	return types
+}
+
+// Bodies of cases in order they are generated, default case is the last one
+func caseBodies(stmt parser.SwitchStatement) []parser.BlockStatement {
+	var bodies []parser.BlockStatement
+	var defaultBody []parser.BlockStatement
+
+	for _, caseStmt := range stmt.Body {
+		if isExpressionNil(caseStmt.Expression) {
+			defaultBody = append(defaultBody, caseStmt.Body)
+		} else {
+			bodies = append(bodies, caseStmt.Body)
+		}
+	}
+
+	return append(bodies, defaultBody...)
+}
+
+// Names declared with ':=' and 'var' in statement and its nested blocks
+func collectDeclarations(statement parser.Statement) []parser.Identifier {
+	var names []parser.Identifier
+
+	if stmt, status := statement.(parser.AssignStatement); status && stmt.Operator == parser.GetType(parser.Define) {
+		for _, target := range stmt.Targets {
+			if ident, status := target.(parser.Identifier); status {
+				names = append(names, ident)
+			}
+		}
+	} else if stmt, status := statement.(parser.VarStatement); status {
+		names = append(names, stmt.Identifier)
+	} else if stmt, status := statement.(parser.BlockStatement); status {
+		names = collectDeclarations(stmt.Statements)
+	} else if stmts, status := statement.(parser.Statements); status {
+		for _, stmt := range stmts {
+			names = append(names, collectDeclarations(stmt)...)
+		}
+	} else if stmt, status := statement.(parser.IfStatement); status {
+		names = append(collectDeclarations(stmt.IfBody), collectDeclarations(stmt.ElseBody)...)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {
+		for _, caseStmt := range stmt.Body {
+			names = append(names, collectDeclarations(caseStmt.Body)...)
+		}
+	}
+
+	return names
+}
+
+// Groups of recursive procedures are listed in comments after the program header:
+//   // Recursive procedures:
+//   //   &fact&
+//   //   &isEven&, &isOdd&
+func (generator *Generator) generateRecursionSummary() {
+	if generator.callGraph == nil {
+		return
+	}
+
+	cycles := generator.callGraph.Cycles()
+
+	if len(cycles) == 0 {
+		return
+	}
+
+	generator.write(generator.backend.Comment("Recursive procedures:"))
+
+	for _, cycle := range cycles {
+		var names []string
+
+		for _, name := range cycle {
+			names = append(names, generator.backend.Identifier(name))
+		}
+
+		generator.write(generator.backend.Comment("  " + strings.Join(names, ", ")))
+	}
+}
@@ -0,0 +1,263 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Structured pseudo-code, where nesting is shown by indentation
+// and every block is closed by its own keyword:
+//   program main
+//
+//   procedure fact(n: integer): integer
+//       if n ≤ 1 then
+//           return 1
+//       end if
+//       return n * fact(n - 1)
+//   end procedure
+// Keywords are always lower case and identifiers are plain, so only indentation is set with options.
+type Pseudocode struct {
+	IndentWidth int // Spaces for each level of nesting, 4 if zero
+	topLevel    int // Length of indices of declarations, 2 if zero
+}
+
+// Pseudo-code names of Go builtin and standard functions
+var pseudocodeFunctions = map[string]string{
+	"len":        "length",
+	"cap":        "capacity",
+	"append":     "append",
+	"delete":     "delete",
+	"lookup":     "lookup",
+	"contains":   "contains",
+	"fixed":      "fixed",
+	"math.Sqrt":  "sqrt",
+	"math.Abs":   "abs",
+	"math.Pow":   "power",
+	"math.Floor": "floor",
+	"math.Max":   "max",
+	"math.Min":   "min",
+}
+
+// Logical and bit operators are words, comparisons are mathematical symbols
+var pseudocodeOperators = OperatorTable{
+	Binary: map[string]string{
+		"||": "or",
+		"&&": "and",
+		"==": "=",
+		"!=": "≠",
+		"<":  "<",
+		"<=": "≤",
+		">":  ">",
+		">=": "≥",
+		"+":  "+",
+		"-":  "-",
+		"|":  "bitor",
+		"^":  "bitxor",
+		"*":  "*",
+		"/":  "/",
+		"%":  "mod",
+		"<<": "shl",
+		">>": "shr",
+		"&":  "bitand",
+	},
+	Unary: map[string]string{
+		"+": "+",
+		"-": "-",
+		"!": "not",
+		"^": "bitnot",
+	},
+	Typed: map[string]map[string]string{
+		"int":    {"/": "div"},
+		"string": {"+": "concat"},
+	},
+}
+
+// Pseudo-code names of Go basic types
+var pseudocodeTypeNames = map[string]string{
+	"int":     "integer",
+	"float64": "real",
+	"string":  "string",
+	"bool":    "boolean",
+}
+
+func (Pseudocode) Configure(options GeneratorOptions) Backend {
+	return Pseudocode{options.IndentWidth, len(options.startIndex())}
+}
+
+func (Pseudocode) Program(name string) string {
+	return "program " + name + "\n"
+}
+
+func (Pseudocode) Comment(text string) string {
+	return "# " + text + "\n"
+}
+
+// Procedures are separated by empty lines
+func (backend Pseudocode) Procedure(index []int, name string, params []Parameter, result string, recursive bool) string {
+	var kind string
+
+	if recursive {
+		kind = "recursive "
+	}
+
+	if result != "" {
+		result = ": " + result
+	}
+
+	return "\n" + backend.line(index, kind+"procedure "+name+"("+backend.parameters(params, ", ")+")"+result)
+}
+
+// Procedure is closed at the level of its header
+func (backend Pseudocode) EndProcedure(index []int, name string) string {
+	return backend.line(index[:len(index)-1], "end procedure")
+}
+
+func (backend Pseudocode) Locals(index []int, names []string) string {
+	return backend.line(index, "local "+strings.Join(names, ", "))
+}
+
+func (backend Pseudocode) Variables(index []int, variables []Parameter) string {
+	str := backend.line(index, "var")
+	variableIndex := append(append([]int{}, index...), 1)
+
+	for _, variable := range variables {
+		str += backend.line(variableIndex, backend.parameters([]Parameter{variable}, ""))
+	}
+
+	return str + backend.line(index, "end var")
+}
+
+func (backend Pseudocode) Record(index []int, name string, fields []Parameter) string {
+	str := "\n" + backend.line(index, "record "+name)
+	fieldIndex := append(append([]int{}, index...), 1)
+
+	for _, field := range fields {
+		str += backend.line(fieldIndex, backend.parameters([]Parameter{field}, ""))
+	}
+
+	return str + backend.line(index, "end record")
+}
+
+func (backend Pseudocode) TypeDeclaration(index []int, name string, typ string) string {
+	return "\n" + backend.line(index, "type "+name+" = "+typ)
+}
+
+func (backend Pseudocode) Value(index []int, target string, value string) string {
+	return backend.Assign(index, target, value)
+}
+
+func (backend Pseudocode) Assign(index []int, target string, value string) string {
+	return backend.line(index, target+" ← "+value)
+}
+
+func (backend Pseudocode) Statement(index []int, text string) string {
+	return backend.line(index, text)
+}
+
+func (backend Pseudocode) Return(index []int, value string) string {
+	return backend.line(index, strings.TrimSpace("return "+value))
+}
+
+func (backend Pseudocode) Branch(index []int, keyword string) string {
+	return backend.line(index, keyword)
+}
+
+func (backend Pseudocode) If(index []int, condition string) string {
+	return backend.line(index, "if "+condition+" then")
+}
+
+func (backend Pseudocode) ElseIf(index []int, condition string) string {
+	return backend.line(index[:len(index)-1], "else if "+condition+" then")
+}
+
+func (backend Pseudocode) Else(index []int) string {
+	return backend.line(index[:len(index)-1], "else")
+}
+
+func (backend Pseudocode) EndIf(index []int) string {
+	return backend.line(index[:len(index)-1], "end if")
+}
+
+func (Pseudocode) Output(items []string, newline bool) string {
+	if newline {
+		return strings.TrimSpace("writeln " + strings.Join(items, ", "))
+	}
+
+	return "write " + strings.Join(items, ", ")
+}
+
+func (Pseudocode) Input(targets []string) string {
+	return "read " + strings.Join(targets, ", ")
+}
+
+func (Pseudocode) Identifier(name string) string {
+	return name
+}
+
+func (Pseudocode) Operators() OperatorTable {
+	return pseudocodeOperators
+}
+
+func (Pseudocode) Function(name string) string {
+	return pseudocodeFunctions[name]
+}
+
+func (Pseudocode) TypeName(name string) string {
+	return pseudocodeTypeNames[name]
+}
+
+// Slices have no length: array of integer
+func (Pseudocode) ArrayType(length string, element string) string {
+	if length == "" {
+		return "array of " + element
+	}
+
+	return fmt.Sprintf("array[%s] of %s", length, element)
+}
+
+func (Pseudocode) MapType(key string, value string) string {
+	return fmt.Sprintf("map[%s] of %s", key, value)
+}
+
+func (backend Pseudocode) RecordType(fields []Parameter) string {
+	return "record(" + backend.parameters(fields, "; ") + ")"
+}
+
+func (Pseudocode) RecordLiteral(name string, elements string) string {
+	if name == "" {
+		return "record(" + elements + ")"
+	}
+
+	return name + "(" + elements + ")"
+}
+
+func (Pseudocode) parameters(params []Parameter, separator string) string {
+	var list []string
+
+	for _, param := range params {
+		str := param.Name + ": " + param.Type
+
+		if param.Output {
+			str = "out " + str
+		}
+
+		list = append(list, str)
+	}
+
+	return strings.Join(list, separator)
+}
+
+// Declarations of the program, which have index like [1, 2], aren't indented
+func (backend Pseudocode) line(index []int, text string) string {
+	width, topLevel := backend.IndentWidth, backend.topLevel
+
+	if width == 0 {
+		width = 4
+	}
+
+	if topLevel == 0 {
+		topLevel = len(defaultStartIndex)
+	}
+
+	return strings.Repeat(" ", width*(len(index)-topLevel)) + text + "\n"
+}
@@ -0,0 +1,235 @@
+package generator
+
+import (
+	"../parser"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Source map links indices of generated statements to Go statements
+// they come from, so editors can jump between the two:
+//   {
+//     "version": 1,
+//     "file": "main.go",
+//     "mappings": [
+//       {"index": "1.1.3.", "line": 12, "column": 5}
+//     ]
+//   }
+// Index is written the same way as after Q and A. Lines and columns start from 1.
+// Lines without their own Go statement, like END of IF, point to the enclosing one.
+type SourceMap struct {
+	Version  int             `json:"version"`
+	File     string          `json:"file"`
+	Mappings []SourceMapping `json:"mappings"`
+	indices  map[string]bool // Indices which are mapped already
+	unsorted bool            // Some mapping is added before the last one
+}
+
+type SourceMapping struct {
+	Index  string `json:"index"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	index  []int
+}
+
+const sourceMapVersion = 1
+
+func (sourceMap *SourceMap) JSON() ([]byte, error) {
+	return json.MarshalIndent(sourceMap, "", "  ")
+}
+
+// Indices are mapped once, to the first statement they're written for.
+// They're usually added in order, otherwise mappings are sorted when the map is taken, see sortMappings.
+func (sourceMap *SourceMap) add(index []int, position parser.Position) {
+	number := numbering(index)
+
+	if sourceMap.indices[number] {
+		return
+	}
+
+	if sourceMap.indices == nil {
+		sourceMap.indices = map[string]bool{}
+	}
+
+	sourceMap.indices[number] = true
+
+	if last := len(sourceMap.Mappings) - 1; last >= 0 && isIndexBefore(index, sourceMap.Mappings[last].index) {
+		sourceMap.unsorted = true
+	}
+
+	mapping := SourceMapping{number, position.Line, position.Column, append([]int{}, index...)}
+	sourceMap.Mappings = append(sourceMap.Mappings, mapping)
+}
+
+// Mappings are kept in order of indices: 1.2. < 1.2.1. < 1.10.
+func (sourceMap *SourceMap) sortMappings() {
+	if !sourceMap.unsorted {
+		return
+	}
+
+	sort.SliceStable(sourceMap.Mappings, func(i, j int) bool {
+		return isIndexBefore(sourceMap.Mappings[i].index, sourceMap.Mappings[j].index)
+	})
+	sourceMap.unsorted = false
+}
+
+func isIndexBefore(first []int, second []int) bool {
+	for i := 0; i < len(first) && i < len(second); i++ {
+		if first[i] != second[i] {
+			return first[i] < second[i]
+		}
+	}
+
+	return len(first) < len(second)
+}
+
+// Source maps are produced for the file with Go code.
+// If comments are set, position is also written after every line:
+//   Q1.1.3. &a& := &b& * 2 // main.go:12
+func (generator *Generator) enableSourceMap(file string, comments bool) {
+	generator.sourceMap = &SourceMap{Version: sourceMapVersion, File: file, Mappings: []SourceMapping{}}
+	generator.backend = sourceMapBackend{generator.backend, generator, comments}
+}
+
+// Nil if source map isn't enabled
+func (generator *Generator) SourceMap() *SourceMap {
+	if generator.sourceMap != nil {
+		generator.sourceMap.sortMappings()
+	}
+
+	return generator.sourceMap
+}
+
+// Position of the Go statement which is being generated, zero if it's unknown
+func statementPosition(statement parser.Statement) parser.Position {
+	switch stmt := statement.(type) {
+	case parser.AssignStatement:
+		return stmt.Position
+	case parser.ExpressionStatement:
+		return stmt.Position
+	case parser.VarStatement:
+		return stmt.Position
+	case parser.BranchStatement:
+		return stmt.Position
+	case parser.ReturnStatement:
+		return stmt.Position
+	case parser.SwitchStatement:
+		return stmt.Position
+	case parser.IfStatement:
+		return stmt.Position
+	case parser.FuncDeclaration:
+		return stmt.Position
+	case parser.TypeDeclaration:
+		return stmt.Position
+	}
+
+	return parser.Position{}
+}
+
+// Backend which records position of the current Go statement for every line written by another backend
+type sourceMapBackend struct {
+	Backend
+	generator *Generator
+	comments  bool
+}
+
+func (backend sourceMapBackend) Procedure(index []int, name string, params []Parameter, result string, recursive bool) string {
+	return backend.mark(index, backend.Backend.Procedure(index, name, params, result, recursive))
+}
+
+func (backend sourceMapBackend) EndProcedure(index []int, name string) string {
+	return backend.mark(index, backend.Backend.EndProcedure(index, name))
+}
+
+func (backend sourceMapBackend) Locals(index []int, names []string) string {
+	return backend.mark(index, backend.Backend.Locals(index, names))
+}
+
+// Variables and the end of the block are numbered inside the block
+func (backend sourceMapBackend) Variables(index []int, variables []Parameter) string {
+	for i := 1; i <= len(variables)+1; i++ {
+		backend.locate(append(append([]int{}, index...), i))
+	}
+
+	return backend.mark(index, backend.Backend.Variables(index, variables))
+}
+
+// Fields and the end of record are numbered inside the record
+func (backend sourceMapBackend) Record(index []int, name string, fields []Parameter) string {
+	for i := 1; i <= len(fields)+1; i++ {
+		backend.locate(append(append([]int{}, index...), i))
+	}
+
+	return backend.mark(index, backend.Backend.Record(index, name, fields))
+}
+
+func (backend sourceMapBackend) TypeDeclaration(index []int, name string, typ string) string {
+	return backend.mark(index, backend.Backend.TypeDeclaration(index, name, typ))
+}
+
+func (backend sourceMapBackend) Value(index []int, target string, value string) string {
+	return backend.mark(index, backend.Backend.Value(index, target, value))
+}
+
+func (backend sourceMapBackend) Assign(index []int, target string, value string) string {
+	return backend.mark(index, backend.Backend.Assign(index, target, value))
+}
+
+func (backend sourceMapBackend) Statement(index []int, text string) string {
+	return backend.mark(index, backend.Backend.Statement(index, text))
+}
+
+func (backend sourceMapBackend) Return(index []int, value string) string {
+	return backend.mark(index, backend.Backend.Return(index, value))
+}
+
+func (backend sourceMapBackend) Branch(index []int, keyword string) string {
+	return backend.mark(index, backend.Backend.Branch(index, keyword))
+}
+
+func (backend sourceMapBackend) If(index []int, condition string) string {
+	return backend.mark(index, backend.Backend.If(index, condition))
+}
+
+func (backend sourceMapBackend) ElseIf(index []int, condition string) string {
+	return backend.mark(index, backend.Backend.ElseIf(index, condition))
+}
+
+func (backend sourceMapBackend) Else(index []int) string {
+	return backend.mark(index, backend.Backend.Else(index))
+}
+
+func (backend sourceMapBackend) EndIf(index []int) string {
+	return backend.mark(index, backend.Backend.EndIf(index))
+}
+
+func (backend sourceMapBackend) locate(index []int) {
+	if position := backend.generator.position; position.Line != 0 {
+		backend.generator.sourceMap.add(index, position)
+	}
+}
+
+// Lines get comments with the file and line of Go statement: // main.go:12.
+// Empty lines separating declarations are left as they are.
+func (backend sourceMapBackend) mark(index []int, text string) string {
+	generator := backend.generator
+	backend.locate(index)
+
+	if !backend.comments || generator.position.Line == 0 {
+		return text
+	}
+
+	comment := fmt.Sprintf("%s:%d", generator.sourceMap.File, generator.position.Line)
+	comment = strings.TrimSuffix(backend.Comment(comment), "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = line + " " + comment
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
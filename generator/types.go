@@ -0,0 +1,154 @@
+package generator
+
+import "../parser"
+
+// Structs are declared as records with one field per line:
+//   Q1.1. RECORD &Point&
+//     Q1.1.1. &X& : INTEGER
+//     Q1.1.2. ENDREC &Point&
+// Other named types are declared on a single line:
+//   Q1.2. TYPE &Celsius& = REAL
+func (generator *Generator) generateTypeDeclaration(decl parser.TypeDeclaration, index index) {
+	name := generator.generateExpression(decl.Name)
+
+	if structType, status := decl.Type.(parser.StructType); status {
+		generator.write(generator.backend.Record(index, name, generator.generateFields(structType)))
+	} else {
+		generator.write(generator.backend.TypeDeclaration(index, name, generator.generateType(decl.Type)))
+	}
+}
+
+func (generator *Generator) generateFields(structType parser.StructType) []Parameter {
+	var fields []Parameter
+
+	for _, field := range structType.Fields {
+		for _, name := range field.Names {
+			fields = append(fields, Parameter{
+				Name: generator.generateExpression(name), Type: generator.generateType(field.Type)})
+		}
+	}
+
+	return fields
+}
+
+// Nested arrays are written as: ARRAY[2] OF ARRAY[3] OF INTEGER.
+// Named types are referenced by their names: &Point&
+func (generator *Generator) generateType(expression parser.Expression) string {
+	if ident, status := expression.(parser.Identifier); status {
+		if basicTypes[ident.Name] {
+			return generator.backend.TypeName(ident.Name)
+		}
+
+		return generator.generateExpression(ident)
+	} else if array, status := expression.(parser.ArrayType); status {
+		return generator.backend.ArrayType(generator.generateExpression(array.Length), generator.generateType(array.Element))
+	} else if slice, status := expression.(parser.SliceType); status {
+		return generator.backend.ArrayType("", generator.generateType(slice.Element))
+	} else if mapType, status := expression.(parser.MapType); status {
+		return generator.backend.MapType(generator.generateType(mapType.Key), generator.generateType(mapType.Value))
+	} else if structType, status := expression.(parser.StructType); status {
+		return generator.backend.RecordType(generator.generateFields(structType))
+	}
+
+	return "!!!Error!!!"
+}
+
+// Records are created with their type name and field values: &Point&(1, 2).
+// Arrays are just lists of elements: [1, 2], maps are lists of pairs: {"a": 1}
+func (generator *Generator) generateCompositeLiteral(lit parser.CompositeLiteral) string {
+	elements := generator.generateExpressionList(lit.Elements)
+
+	switch generator.resolveType(lit.Type).(type) {
+	case parser.StructType:
+		if ident, status := lit.Type.(parser.Identifier); status {
+			return generator.backend.RecordLiteral(generator.generateExpression(ident), elements)
+		}
+
+		return generator.backend.RecordLiteral("", elements)
+	case parser.MapType:
+		return "{" + elements + "}"
+	}
+
+	return "[" + elements + "]"
+}
+
+// Maps are nil, they have no value to write, so it's empty
+func (generator *Generator) generateZeroValue(expression parser.Expression) string {
+	if _, status := generator.resolveType(expression).(parser.MapType); status {
+		return ""
+	}
+
+	if ident, status := expression.(parser.Identifier); status {
+		if value, status := zeroValues[ident.Name]; status {
+			return value
+		}
+
+		if generator.isRecord(ident) {
+			return generator.backend.RecordLiteral(generator.generateExpression(ident), "")
+		}
+
+		if decl, status := generator.types[ident.Name]; status {
+			return generator.generateZeroValue(decl.Type)
+		}
+	} else if _, status := expression.(parser.StructType); status {
+		return generator.backend.RecordLiteral("", "")
+	}
+
+	return "[]"
+}
+
+// Conversion functions are named after the basic type they convert to:
+//   float64(&a&) -> REAL(&a&)
+//   Celsius(&a&) -> REAL(&a&)
+// Other types are represented the same way after conversion,
+// so only the converted value is left.
+func (generator *Generator) generateConversion(ident parser.Identifier, arg parser.Expression) string {
+	if basic, status := generator.resolveType(ident).(parser.Identifier); status {
+		if basicTypes[basic.Name] {
+			return generator.backend.TypeName(basic.Name) + "(" + generator.generateExpression(arg) + ")"
+		}
+	}
+
+	return generator.generateExpression(arg)
+}
+
+func (generator *Generator) isTypeName(ident parser.Identifier) bool {
+	basic := basicTypes[ident.Name]
+	_, declared := generator.types[ident.Name]
+
+	return basic || declared
+}
+
+// Named type which is declared as struct
+func (generator *Generator) isRecord(ident parser.Identifier) bool {
+	_, status := generator.resolveType(ident).(parser.StructType)
+	return status
+}
+
+// Named types are replaced with types they're declared with
+func (generator *Generator) resolveType(expression parser.Expression) parser.Expression {
+	for {
+		ident, status := expression.(parser.Identifier)
+
+		if !status {
+			return expression
+		}
+
+		decl, status := generator.types[ident.Name]
+
+		if !status {
+			return expression
+		}
+
+		expression = decl.Type
+	}
+}
+
+func isCollectionType(expression parser.Expression) bool {
+	switch expression.(type) {
+	case parser.ArrayType, parser.SliceType, parser.MapType:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,338 @@
+package generator
+
+import (
+	"../parser"
+	"../semantic"
+	"strconv"
+)
+
+// Variable declared in the procedure, name is the one it gets in generated code
+type declaration struct {
+	ident parser.Identifier
+	name  string
+	scope semantic.Scope
+}
+
+type scopedName struct {
+	scope semantic.Scope
+	name  string
+}
+
+// Variables of the procedure are renamed when they shadow names declared before them,
+// so each variable has its own name in generated code:
+//   x := 1; if c { x := 2 }  ->  &x& := 1, &x_& := 2
+// Scopes are numbered the same way the analyzer numbers them,
+// variables declared in a block are forgotten after it.
+type renamer struct {
+	generator    *Generator
+	names        map[scopedName]string // Variables declared so far
+	declared     map[string]bool       // Go names declared so far
+	used         map[string]bool       // Names which can't be given to renamed variables
+	declarations []declaration
+}
+
+// Procedure with renamed variables and the variables declared in its body in order of declaration
+func (generator *Generator) renameVariables(decl parser.FuncDeclaration) (parser.FuncDeclaration, []declaration) {
+	renamer := &renamer{generator, map[scopedName]string{}, map[string]bool{}, usedNames(decl), nil}
+
+	for _, result := range generator.resultNames(decl) {
+		renamer.used[result.Name] = true
+	}
+
+	for _, field := range append(append([]parser.Field{}, decl.Parameters...), decl.Results...) {
+		for _, name := range field.Names {
+			renamer.names[scopedName{0, name.Name}] = name.Name
+			renamer.declared[name.Name] = true
+		}
+	}
+
+	decl.Body = renamer.renameStatement(decl.Body, 0).(parser.BlockStatement)
+
+	return decl, renamer.declarations
+}
+
+func (renamer *renamer) renameStatement(statement parser.Statement, scope semantic.Scope) parser.Statement {
+	if stmt, status := statement.(parser.AssignStatement); status {                // Assign
+		if values, status := stmt.Expression.(parser.Expressions); status {
+			stmt.Expression = renamer.renameExpressions(values, scope)
+		} else {
+			stmt.Expression = renamer.renameExpression(stmt.Expression, scope)
+		}
+
+		if stmt.Operator != parser.GetType(parser.Define) {
+			stmt.Targets = renamer.renameExpressions(stmt.Targets, scope)
+			return stmt
+		}
+
+		targets := parser.Expressions{}
+
+		for _, target := range stmt.Targets {
+			if ident, status := target.(parser.Identifier); status {
+				target = renamer.declare(ident, scope)
+			}
+
+			targets = append(targets, target)
+		}
+
+		stmt.Targets = targets
+		return stmt
+	} else if stmt, status := statement.(parser.VarStatement); status {          // Var
+		if stmt.Expression != nil {
+			stmt.Expression = renamer.renameExpression(stmt.Expression, scope)
+		}
+
+		stmt.Identifier = renamer.declare(stmt.Identifier, scope)
+		return stmt
+	} else if stmt, status := statement.(parser.ExpressionStatement); status {   // Call
+		stmt.Expression = renamer.renameExpression(stmt.Expression, scope)
+		return stmt
+	} else if stmt, status := statement.(parser.ReturnStatement); status {       // Return
+		stmt.Results = renamer.renameExpressions(stmt.Results, scope)
+		return stmt
+	} else if stmt, status := statement.(parser.BlockStatement); status {        // Block
+		stmt.Statements = renamer.renameStatement(stmt.Statements, scope).(parser.Statements)
+		return stmt
+	} else if stmts, status := statement.(parser.Statements); status {           // Statements
+		if stmts == nil {
+			return stmts
+		}
+
+		renamed := parser.Statements{}
+
+		for _, stmt := range stmts {
+			// Nested block is left by the optimizer in place of if or switch
+			if block, status := stmt.(parser.BlockStatement); status {
+				renamed = append(renamed, renamer.renameBlock(block, scope+1))
+			} else {
+				renamed = append(renamed, renamer.renameStatement(stmt, scope))
+			}
+		}
+
+		return renamed
+	} else if stmt, status := statement.(parser.IfStatement); status {           // If
+		stmt.Condition = renamer.renameExpression(stmt.Condition, scope)
+		stmt.IfBody = renamer.renameBlock(stmt.IfBody, scope+1)
+		stmt.ElseBody = renamer.renameBlock(stmt.ElseBody, scope+1)
+		return stmt
+	} else if stmt, status := statement.(parser.SwitchStatement); status {       // Switch
+		stmt.Expression = renamer.renameExpression(stmt.Expression, scope)
+		cases := parser.CaseStatements{}
+
+		for _, caseStmt := range stmt.Body {
+			caseStmt.Expression = renamer.renameExpression(caseStmt.Expression, scope+1)
+			caseStmt.Body = renamer.renameBlock(caseStmt.Body, scope+2)
+			cases = append(cases, caseStmt)
+		}
+
+		stmt.Body = cases
+		return stmt
+	}
+
+	return statement
+}
+
+func (renamer *renamer) renameBlock(block parser.BlockStatement, scope semantic.Scope) parser.BlockStatement {
+	names := map[scopedName]string{}
+
+	for key, name := range renamer.names {
+		names[key] = name
+	}
+
+	names, renamer.names = renamer.names, names
+	block = renamer.renameStatement(block, scope).(parser.BlockStatement)
+	renamer.names = names
+
+	return block
+}
+
+// Identifiers get names of the variables they refer to, fields and keys of records keep their names
+func (renamer *renamer) renameExpression(expression parser.Expression, scope semantic.Scope) parser.Expression {
+	if expr, status := expression.(parser.Identifier); status {
+		for s := scope; s >= 0; s-- {
+			if name, status := renamer.names[scopedName{s, expr.Name}]; status {
+				expr.Name = name
+				return expr
+			}
+		}
+	} else if expr, status := expression.(parser.UnaryExpression); status {
+		expr.Operand = renamer.renameExpression(expr.Operand, scope)
+		return expr
+	} else if expr, status := expression.(parser.BinaryExpression); status {
+		expr.LeftOperand = renamer.renameExpression(expr.LeftOperand, scope)
+		expr.RightOperand = renamer.renameExpression(expr.RightOperand, scope)
+		return expr
+	} else if expr, status := expression.(parser.IndexExpression); status {
+		expr.Expression = renamer.renameExpression(expr.Expression, scope)
+		expr.Index = renamer.renameExpression(expr.Index, scope)
+		return expr
+	} else if call, status := expression.(parser.CallExpression); status {
+		call.Function = renamer.renameExpression(call.Function, scope)
+		call.Arguments = renamer.renameExpressions(call.Arguments, scope)
+		return call
+	} else if expr, status := expression.(parser.SelectorExpression); status {
+		expr.Expression = renamer.renameExpression(expr.Expression, scope)
+		return expr
+	} else if lit, status := expression.(parser.CompositeLiteral); status {
+		_, isRecord := renamer.generator.resolveType(lit.Type).(parser.StructType)
+		elements := parser.Expressions{}
+
+		for _, element := range lit.Elements {
+			if keyValue, status := element.(parser.KeyValueExpression); status {
+				if !isRecord {
+					keyValue.Key = renamer.renameExpression(keyValue.Key, scope)
+				}
+
+				keyValue.Value = renamer.renameExpression(keyValue.Value, scope)
+				element = keyValue
+			} else {
+				element = renamer.renameExpression(element, scope)
+			}
+
+			elements = append(elements, element)
+		}
+
+		lit.Elements = elements
+		return lit
+	}
+
+	return expression
+}
+
+func (renamer *renamer) renameExpressions(expressions parser.Expressions, scope semantic.Scope) parser.Expressions {
+	if expressions == nil {
+		return nil
+	}
+
+	renamed := parser.Expressions{}
+
+	for _, expr := range expressions {
+		renamed = append(renamed, renamer.renameExpression(expr, scope))
+	}
+
+	return renamed
+}
+
+// Names declared again in the procedure get '_' suffix: &x_&, &x__&.
+// Variable which is already declared in the scope is just assigned: a, err := f()
+func (renamer *renamer) declare(ident parser.Identifier, scope semantic.Scope) parser.Identifier {
+	if isBlank(ident) {
+		return ident
+	}
+
+	if name, status := renamer.names[scopedName{scope, ident.Name}]; status {
+		ident.Name = name
+		return ident
+	}
+
+	name := ident.Name
+
+	if renamer.declared[name] {
+		name += "_"
+
+		for renamer.used[name] {
+			name += "_"
+		}
+	}
+
+	renamer.names[scopedName{scope, ident.Name}] = name
+	renamer.declared[ident.Name] = true
+	renamer.used[name] = true
+	renamer.declarations = append(renamer.declarations, declaration{ident, name, scope})
+	ident.Name = name
+
+	return ident
+}
+
+// Variables of the procedure are declared at the beginning of its body with their types:
+//   Q1.1.1. VAR
+//     Q1.1.1.1. &a& : INTEGER
+//     Q1.1.1.2. &x_& : REAL
+//     Q1.1.1.3. ENDVAR
+// Parameters and output parameters are declared in the header.
+// Temporaries for switch tags follow the variables, they have types of the tags,
+// temporaries for discarded outputs are the last ones.
+// Index is moved to the next statement if the block is written.
+func (generator *Generator) generateVariables(
+	decl parser.FuncDeclaration,
+	declarations []declaration,
+	index index,
+) {
+	if generator.variables == nil {
+		return
+	}
+
+	var variables []Parameter
+
+	// The only named result is a local variable: func f() (n int)
+	if len(resultTypes(decl)) == 1 && decl.Results[0].Names != nil {
+		for _, result := range generator.resultNames(decl) {
+			declarations = append([]declaration{{result, result.Name, 0}}, declarations...)
+		}
+	}
+
+	for _, declared := range declarations {
+		variables = append(variables, Parameter{Name: generator.backend.Identifier(declared.name),
+			Type: generator.generateVariableType(generator.variableType(decl, declared))})
+	}
+
+	for i, tag := range collectSwitchTags(decl.Body) {
+		variables = append(variables, Parameter{Name: generator.generateExpression(generator.temporaries[i]),
+			Type: generator.generateVariableType(generator.info.TypeOf(tag))})
+	}
+
+	for i, discardedType := range generator.collectDiscarded(decl.Body) {
+		variables = append(variables, Parameter{Name: generator.generateExpression(generator.discarded[i]),
+			Type: generator.generateVariableType(discardedType)})
+	}
+
+	if len(variables) == 0 {
+		return
+	}
+
+	generator.write(generator.backend.Variables(index, variables))
+	index[len(index)-1]++
+}
+
+// Type inferred by the analyzer, Undefined if the analyzer doesn't know the variable
+func (generator *Generator) variableType(decl parser.FuncDeclaration, declared declaration) semantic.Type {
+	if variable := generator.info.SymbolOf(declared.ident); variable != nil {
+		return variable.Type
+	}
+
+	for _, variable := range generator.variables[declared.scope] {
+		if variable.Function == decl.Name.Name && variable.Name == declared.ident.Name {
+			return variable.Type
+		}
+	}
+
+	return semantic.Undefined
+}
+
+// Types inferred by the analyzer are written the same way as types in the code
+func (generator *Generator) generateVariableType(varType semantic.Type) string {
+	switch t := varType.(type) {
+	case semantic.Basic:
+		if name, status := semanticTypes[t]; status {
+			return generator.backend.TypeName(name)
+		}
+	case *semantic.Named:
+		return generator.backend.Identifier(t.Name)
+	case semantic.Array:
+		return generator.backend.ArrayType(strconv.Itoa(t.Length), generator.generateVariableType(t.Element))
+	case semantic.Slice:
+		return generator.backend.ArrayType("", generator.generateVariableType(t.Element))
+	case semantic.Map:
+		return generator.backend.MapType(generator.generateVariableType(t.Key), generator.generateVariableType(t.Value))
+	case *semantic.Struct:
+		var fields []Parameter
+
+		for _, field := range t.Fields {
+			fields = append(fields, Parameter{Name: generator.backend.Identifier(field.Name),
+				Type: generator.generateVariableType(field.Type)})
+		}
+
+		return generator.backend.RecordType(fields)
+	}
+
+	return "!!!Error!!!"
+}
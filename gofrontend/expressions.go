@@ -0,0 +1,355 @@
+package gofrontend
+
+import (
+	"../parser"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"strconv"
+)
+
+func (lowering *lowering) expressions(list []ast.Expr) parser.Expressions {
+	var exprs parser.Expressions
+
+	for _, expr := range list {
+		exprs = append(exprs, lowering.expression(expr))
+	}
+
+	return exprs
+}
+
+// Constant expressions are computed exactly by go/types, they're literals of their values:
+//   0.1 + 0.2 -> 0.3, math.Pi -> 3.141592653589793
+func (lowering *lowering) expression(expression ast.Expr) parser.Expression {
+	if lowering.isConstant(expression) {
+		return lowering.constant(expression, lowering.position(tokenPos(expression)))
+	}
+
+	return lowering.operation(expression)
+}
+
+// Position of the token which is the position of the expression in the native tree, see operation
+func tokenPos(expression ast.Expr) token.Pos {
+	switch expr := expression.(type) {
+	case *ast.ParenExpr:
+		return tokenPos(expr.X)
+	case *ast.UnaryExpr:
+		return expr.OpPos
+	case *ast.BinaryExpr:
+		return expr.OpPos
+	case *ast.SelectorExpr:
+		return expr.X.End()
+	case *ast.CallExpr:
+		return expr.Lparen
+	case *ast.IndexExpr:
+		return expr.Lbrack
+	}
+
+	return expression.Pos()
+}
+
+// Expressions have positions of the same tokens as in the native parser:
+// operator, '(' of call, '[' of index, '.' of selector and '{' of composite literal.
+// Types are expressions as well: []int, map[string]Point
+func (lowering *lowering) operation(expression ast.Expr) parser.Expression {
+	switch expr := expression.(type) {
+	case *ast.Ident:
+		// Predeclared true and false are literals, the same way they're read by the lexer
+		if object := lowering.info.Uses[expr]; object != nil && object == types.Universe.Lookup(expr.Name) {
+			if value := lowering.info.Types[expr].Value; value != nil && value.Kind() == constant.Bool {
+				return parser.Literal{Type: parser.BooleanLiteral, Value: constant.BoolVal(value), Position: lowering.position(expr.Pos())}
+			}
+		}
+
+		if object, status := lowering.info.Uses[expr].(*types.TypeName); status {
+			if basic, status := object.Type().(*types.Basic); status {
+				lowering.basicType(expr, basic)
+			}
+		}
+
+		return lowering.identifier(expr)
+	case *ast.BasicLit:
+		return lowering.literal(expr)
+	case *ast.ParenExpr:
+		return lowering.expression(expr.X)
+	case *ast.UnaryExpr:
+		if expr.Op == token.ARROW {
+			lowering.unsupported(expr, "channel receives")
+		}
+
+		return parser.UnaryExpression{
+			Operator: expr.Op.String(),
+			Operand:  lowering.expression(expr.X),
+			Position: lowering.position(expr.OpPos),
+		}
+	case *ast.BinaryExpr:
+		return parser.BinaryExpression{
+			LeftOperand:  lowering.expression(expr.X),
+			Operator:     expr.Op.String(),
+			RightOperand: lowering.expression(expr.Y),
+			Position:     lowering.position(expr.OpPos),
+		}
+	case *ast.IndexExpr:
+		return parser.IndexExpression{
+			Expression: lowering.expression(expr.X),
+			Index:      lowering.expression(expr.Index),
+			Position:   lowering.position(expr.Lbrack),
+		}
+	case *ast.SelectorExpr:
+		// Functions of packages are only called, constants of packages are literals
+		if lowering.isPackage(expr.X) && lowering.info.Types[expr].Value == nil {
+			lowering.unsupported(expr, "package selectors which aren't calls or constants")
+		}
+
+		return lowering.selector(expr)
+	case *ast.CallExpr:
+		if expr.Ellipsis.IsValid() {
+			lowering.unsupported(expr, "calls with '...'")
+		}
+
+		var function parser.Expression
+
+		if fun, status := expr.Fun.(*ast.SelectorExpr); status && lowering.isPackage(fun.X) {
+			function = lowering.selector(fun)
+		} else {
+			function = lowering.expression(expr.Fun)
+		}
+
+		return parser.CallExpression{
+			Function:  function,
+			Arguments: lowering.expressions(expr.Args),
+			Position:  lowering.position(expr.Lparen),
+		}
+	case *ast.CompositeLit:
+		return lowering.compositeLiteral(expr)
+	case *ast.KeyValueExpr:
+		return parser.KeyValueExpression{Key: lowering.expression(expr.Key), Value: lowering.expression(expr.Value)}
+	case *ast.ArrayType:
+		return lowering.arrayType(expr)
+	case *ast.MapType:
+		return parser.MapType{Key: lowering.expression(expr.Key), Value: lowering.expression(expr.Value)}
+	case *ast.StructType:
+		for _, field := range expr.Fields.List {
+			if len(field.Names) == 0 {
+				lowering.unsupported(field, "embedded fields")
+			}
+			if field.Tag != nil {
+				lowering.unsupported(field.Tag, "field tags")
+			}
+		}
+
+		return parser.StructType{Fields: lowering.fields(expr.Fields)}
+	case *ast.SliceExpr:
+		lowering.unsupported(expr, "slice expressions")
+	case *ast.StarExpr:
+		lowering.unsupported(expr, "pointers")
+	case *ast.FuncLit:
+		lowering.unsupported(expr, "function literals")
+	case *ast.TypeAssertExpr:
+		lowering.unsupported(expr, "type assertions")
+	case *ast.IndexListExpr:
+		lowering.unsupported(expr, "generic instantiations")
+	case *ast.FuncType:
+		lowering.unsupported(expr, "function types")
+	case *ast.InterfaceType:
+		lowering.unsupported(expr, "interfaces")
+	case *ast.ChanType:
+		lowering.unsupported(expr, "channels")
+	default:
+		lowering.unsupported(expr, "expressions of this kind")
+	}
+
+	return parser.UnaryExpression{}
+}
+
+// Values of literals are the constants of go/types, they have the type of their context,
+// so untyped integer is float where float is expected. Strings are quoted again:
+//   `C:\dir` -> "C:\\dir"
+//   "\x41"   -> "A"
+func (lowering *lowering) literal(lit *ast.BasicLit) parser.Expression {
+	position := lowering.position(lit.Pos())
+
+	switch lit.Kind {
+	case token.CHAR:
+		lowering.unsupported(lit, "rune literals")
+		return parser.UnaryExpression{}
+	case token.IMAG:
+		lowering.unsupported(lit, "imaginary numbers")
+		return parser.UnaryExpression{}
+	}
+
+	return lowering.constant(lit, position)
+}
+
+// Names and literals are lowered as they are, constants of declared types keep their conversions
+// and constants of basic types which LWIQA doesn't have are rejected: uint8(200)
+func (lowering *lowering) isConstant(expression ast.Expr) bool {
+	switch expression.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return false
+	}
+
+	tv := lowering.info.Types[expression]
+	basic, status := tv.Type.(*types.Basic)
+
+	if tv.Value == nil || !status {
+		return false
+	}
+
+	switch basic.Kind() {
+	case types.Int, types.Float64, types.String, types.Bool:
+		return true
+	}
+
+	return basic.Info()&types.IsUntyped != 0
+}
+
+// Constant value of go/types is a literal, it's read from the literal itself if go/types has none.
+// Float constant where integer is expected is integer: var c int = 2.5 * 2
+func (lowering *lowering) constant(expr ast.Expr, position parser.Position) parser.Expression {
+	tv := lowering.info.Types[expr]
+	value := tv.Value
+
+	if lit, status := expr.(*ast.BasicLit); status && value == nil {
+		value = constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+	}
+
+	if basic, status := tv.Type.(*types.Basic); status && basic.Info()&types.IsInteger != 0 {
+		value = constant.ToInt(value)
+	}
+
+	switch value.Kind() {
+	case constant.Bool:
+		return parser.Literal{Type: parser.BooleanLiteral, Value: constant.BoolVal(value), Position: position}
+	case constant.Int:
+		if i, exact := constant.Int64Val(value); exact {
+			return parser.Literal{Type: parser.IntegerLiteral, Value: i, Position: position}
+		}
+
+		lowering.unsupported(expr, "integers which don't fit in 64 bits")
+	case constant.Float:
+		if f, _ := constant.Float64Val(value); !math.IsInf(f, 0) {
+			return parser.Literal{Type: parser.FloatLiteral, Value: f, Position: position}
+		}
+
+		lowering.unsupported(expr, "floats which don't fit in 64 bits")
+	case constant.String:
+		quoted := strconv.Quote(constant.StringVal(value))
+		return parser.Literal{Type: parser.StringLiteral, Value: quoted[1 : len(quoted)-1], Position: position}
+	}
+
+	return parser.UnaryExpression{}
+}
+
+// Dot goes right after the operand in gofmt'ed code: p.X
+func (lowering *lowering) selector(expr *ast.SelectorExpr) parser.Expression {
+	lowering.usePackage(expr.X)
+
+	return parser.SelectorExpression{
+		Expression: lowering.expression(expr.X),
+		Selector:   lowering.identifier(expr.Sel),
+		Position:   lowering.position(expr.X.End()),
+	}
+}
+
+func (lowering *lowering) isPackage(expr ast.Expr) bool {
+	ident, status := expr.(*ast.Ident)
+
+	if !status {
+		return false
+	}
+
+	_, status = lowering.info.Uses[ident].(*types.PkgName)
+	return status
+}
+
+// Package is imported only if its selector is in the lowered tree
+func (lowering *lowering) usePackage(expr ast.Expr) {
+	if ident, status := expr.(*ast.Ident); status {
+		if name, status := lowering.info.Uses[ident].(*types.PkgName); status {
+			lowering.packages[name.Imported().Path()] = true
+		}
+	}
+}
+
+// Only basic types of LWIQA are translated: INTEGER, REAL, STRING and BOOLEAN
+func (lowering *lowering) basicType(node ast.Node, t *types.Basic) {
+	switch t.Kind() {
+	case types.Int, types.Float64, types.String, types.Bool:
+	default:
+		lowering.unsupported(node, "basic types other than int, float64, string and bool")
+	}
+}
+
+// Length of [...]int{1, 2, 3} is counted by go/types
+func (lowering *lowering) arrayType(expr *ast.ArrayType) parser.Expression {
+	element := lowering.expression(expr.Elt)
+
+	if expr.Len == nil {
+		return parser.SliceType{Element: element}
+	}
+
+	if _, status := expr.Len.(*ast.Ellipsis); !status {
+		return parser.ArrayType{Length: lowering.expression(expr.Len), Element: element}
+	}
+
+	if array, status := lowering.info.TypeOf(expr).(*types.Array); status {
+		return parser.ArrayType{Length: parser.Literal{Type: parser.IntegerLiteral, Value: array.Len()}, Element: element}
+	}
+
+	lowering.unsupported(expr, "arrays of unknown length")
+	return parser.ArrayType{Element: element}
+}
+
+// Elements of arrays, slices and maps can omit their types, they are written by go/types:
+//   []Point{{1, 2}} -> []Point{Point{1, 2}}
+func (lowering *lowering) compositeLiteral(lit *ast.CompositeLit) parser.Expression {
+	var litType parser.Expression
+
+	if lit.Type != nil {
+		litType = lowering.expression(lit.Type)
+	} else {
+		litType = lowering.typeExpression(lit, lowering.info.TypeOf(lit))
+	}
+
+	return parser.CompositeLiteral{
+		Type:     litType,
+		Elements: lowering.expressions(lit.Elts),
+		Position: lowering.position(lit.Lbrace),
+	}
+}
+
+// Type of go/types written as expression, node is the one which has the type
+func (lowering *lowering) typeExpression(node ast.Node, t types.Type) parser.Expression {
+	switch t := t.(type) {
+	case *types.Basic:
+		lowering.basicType(node, t)
+		return parser.Identifier{Name: t.Name()}
+	case *types.Named:
+		return parser.Identifier{Name: t.Obj().Name()}
+	case *types.Alias:
+		return parser.Identifier{Name: t.Obj().Name()}
+	case *types.Slice:
+		return parser.SliceType{Element: lowering.typeExpression(node, t.Elem())}
+	case *types.Array:
+		length := parser.Literal{Type: parser.IntegerLiteral, Value: t.Len()}
+		return parser.ArrayType{Length: length, Element: lowering.typeExpression(node, t.Elem())}
+	case *types.Map:
+		return parser.MapType{Key: lowering.typeExpression(node, t.Key()), Value: lowering.typeExpression(node, t.Elem())}
+	case *types.Struct:
+		var fields []parser.Field
+
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			fieldType := lowering.typeExpression(node, field.Type())
+			fields = append(fields, parser.Field{Names: []parser.Identifier{{Name: field.Name()}}, Type: fieldType})
+		}
+
+		return parser.StructType{Fields: fields}
+	}
+
+	lowering.unsupported(node, "composite literals of this type")
+	return parser.UnaryExpression{}
+}
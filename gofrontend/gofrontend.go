@@ -0,0 +1,215 @@
+package gofrontend
+
+import (
+	"../parser"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// Code is parsed by go/parser and checked by go/types, then the syntax tree of Go
+// is lowered to the syntax tree of the native parser, so the rest of translation is the same.
+// Syntax errors and constructs which have no node in the native tree are in File.Errors,
+// errors found by go/types are returned. Each error starts with its position:
+//   5:2: for loops are not supported
+// The tree can be used only if there are no errors.
+func Parse(code string) (parser.File, parser.Errors) {
+	fset := token.NewFileSet()
+	goFile, err := goparser.ParseFile(fset, "", code, 0)
+
+	if err != nil {
+		return parser.File{Errors: syntaxErrors(err)}, nil
+	}
+
+	var checkErrors parser.Errors
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	config := types.Config{
+		Importer: importer.Default(),
+		Error: func(err error) {
+			if typeErr, status := err.(types.Error); status {
+				checkErrors = append(checkErrors, newError(parser.CheckError, fset.Position(typeErr.Pos), typeErr.Msg))
+			} else {
+				checkErrors = append(checkErrors, &parser.Error{Type: parser.CheckError, Message: err.Error()})
+			}
+		},
+	}
+
+	// Errors are collected by the handler, the first one is returned as well
+	config.Check(goFile.Name.Name, fset, []*ast.File{goFile}, info)
+
+	lowering := &lowering{fset: fset, info: info, packages: map[string]bool{}}
+	file := lowering.file(goFile)
+	file.Errors = lowering.errors
+
+	return file, checkErrors
+}
+
+func syntaxErrors(err error) parser.Errors {
+	list, status := err.(scanner.ErrorList)
+
+	if !status {
+		return parser.Errors{&parser.Error{Type: parser.ExpectError, Message: err.Error()}}
+	}
+
+	var errors parser.Errors
+
+	for _, item := range list {
+		errors = append(errors, newError(parser.ExpectError, item.Pos, item.Msg))
+	}
+
+	return errors
+}
+
+func newError(errType int, position token.Position, message string) *parser.Error {
+	return &parser.Error{Type: errType, Message: fmt.Sprintf("%d:%d: %s", position.Line, position.Column, message)}
+}
+
+type lowering struct {
+	fset     *token.FileSet
+	info     *types.Info
+	errors   parser.Errors
+	packages map[string]bool // Paths of packages which are used by the lowered tree
+}
+
+// Constructs are named in plural: for loops, methods
+func (lowering *lowering) unsupported(node ast.Node, constructs string) {
+	lowering.errors = append(lowering.errors,
+		newError(parser.UnsupportedError, lowering.fset.Position(node.Pos()), constructs+" are not supported"))
+}
+
+func (lowering *lowering) position(pos token.Pos) parser.Position {
+	position := lowering.fset.Position(pos)
+	return parser.Position{Line: position.Line, Column: position.Column}
+}
+
+func (lowering *lowering) identifier(ident *ast.Ident) parser.Identifier {
+	return parser.Identifier{Name: ident.Name, Position: lowering.position(ident.Pos())}
+}
+
+// Packages which are used only by their constants aren't imported, constants are literals:
+//   import "math"; x := math.Pi   ->   x := 3.141592653589793
+func (lowering *lowering) file(goFile *ast.File) parser.File {
+	file := parser.File{Package: parser.Package{Name: goFile.Name.Name}}
+
+	for _, spec := range goFile.Imports {
+		if spec.Name != nil {
+			lowering.unsupported(spec, "named imports")
+		}
+	}
+
+	for _, declaration := range goFile.Decls {
+		switch decl := declaration.(type) {
+		case *ast.GenDecl:
+			switch decl.Tok {
+			case token.IMPORT:
+				// Imports are already read
+			case token.TYPE:
+				for _, spec := range decl.Specs {
+					file.Declarations = append(file.Declarations, lowering.typeDeclaration(decl, spec.(*ast.TypeSpec)))
+				}
+			default:
+				lowering.unsupported(decl, "package-level "+decl.Tok.String()+" declarations")
+			}
+		case *ast.FuncDecl:
+			file.Declarations = append(file.Declarations, lowering.funcDeclaration(decl))
+		default:
+			lowering.unsupported(declaration, "declarations of this kind")
+		}
+	}
+
+	for _, spec := range goFile.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); lowering.packages[path] {
+			file.Imports = append(file.Imports, parser.ImportSpec{Path: path})
+		}
+	}
+
+	return file
+}
+
+// Declarations in parentheses have positions of their own lines, others start with the keyword:
+//   var (
+//   	a int
+//   	b string
+//   )
+func (lowering *lowering) specPosition(decl *ast.GenDecl, spec ast.Spec) parser.Position {
+	if decl.Lparen.IsValid() {
+		return lowering.position(spec.Pos())
+	}
+
+	return lowering.position(decl.Pos())
+}
+
+//   type Point struct { X, Y int }
+//   type Number = int (Alias)
+func (lowering *lowering) typeDeclaration(decl *ast.GenDecl, spec *ast.TypeSpec) parser.TypeDeclaration {
+	if spec.TypeParams != nil {
+		lowering.unsupported(spec.TypeParams, "generic types")
+	}
+
+	return parser.TypeDeclaration{
+		Name:     lowering.identifier(spec.Name),
+		Type:     lowering.expression(spec.Type),
+		Alias:    spec.Assign.IsValid(),
+		Position: lowering.specPosition(decl, spec),
+	}
+}
+
+func (lowering *lowering) funcDeclaration(decl *ast.FuncDecl) parser.FuncDeclaration {
+	if decl.Recv != nil {
+		lowering.unsupported(decl.Recv, "methods")
+	}
+	if decl.Type.TypeParams != nil {
+		lowering.unsupported(decl.Type.TypeParams, "generic functions")
+	}
+	if decl.Body == nil {
+		lowering.unsupported(decl, "functions without body")
+		return parser.FuncDeclaration{Name: lowering.identifier(decl.Name)}
+	}
+
+	for _, param := range decl.Type.Params.List {
+		if len(param.Names) == 0 {
+			lowering.unsupported(param, "parameters without names")
+		}
+		if _, status := param.Type.(*ast.Ellipsis); status {
+			lowering.unsupported(param.Type, "variadic parameters")
+		}
+	}
+
+	return parser.FuncDeclaration{
+		Name:       lowering.identifier(decl.Name),
+		Parameters: lowering.fields(decl.Type.Params),
+		Results:    lowering.fields(decl.Type.Results),
+		Body:       lowering.block(decl.Body.List),
+		Position:   lowering.position(decl.Pos()),
+	}
+}
+
+// Names of unnamed results are empty
+func (lowering *lowering) fields(list *ast.FieldList) []parser.Field {
+	if list == nil {
+		return nil
+	}
+
+	var fields []parser.Field
+
+	for _, field := range list.List {
+		var names []parser.Identifier
+
+		for _, name := range field.Names {
+			names = append(names, lowering.identifier(name))
+		}
+
+		fields = append(fields, parser.Field{Names: names, Type: lowering.expression(field.Type)})
+	}
+
+	return fields
+}
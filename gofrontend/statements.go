@@ -0,0 +1,290 @@
+package gofrontend
+
+import (
+	"../parser"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Statements which are lowered to several ones are put in the block one after another
+func (lowering *lowering) block(list []ast.Stmt) parser.BlockStatement {
+	var stmts parser.Statements
+
+	for _, stmt := range list {
+		stmts = append(stmts, lowering.statement(stmt)...)
+	}
+
+	return parser.BlockStatement{Statements: stmts}
+}
+
+func (lowering *lowering) statement(statement ast.Stmt) parser.Statements {
+	position := lowering.position(statement.Pos())
+
+	switch stmt := statement.(type) {
+	case *ast.AssignStmt:
+		return lowering.assignStatement(stmt)
+	case *ast.IncDecStmt:
+		// Lowered to assignment: i++ -> i = i + 1
+		one := parser.Literal{Type: parser.IntegerLiteral, Value: int64(1)}
+		operator := parser.GetType(parser.Plus)
+
+		if stmt.Tok == token.DEC {
+			operator = parser.GetType(parser.Minus)
+		}
+
+		return parser.Statements{lowering.operatorAssignment(stmt, stmt.X, operator, one, stmt.TokPos)}
+	case *ast.DeclStmt:
+		return lowering.declStatement(stmt.Decl.(*ast.GenDecl))
+	case *ast.ExprStmt:
+		return parser.Statements{parser.ExpressionStatement{Expression: lowering.expression(stmt.X), Position: position}}
+	case *ast.ReturnStmt:
+		return parser.Statements{parser.ReturnStatement{Results: lowering.expressions(stmt.Results), Position: position}}
+	case *ast.IfStmt:
+		return parser.Statements{lowering.ifStatement(stmt)}
+	case *ast.SwitchStmt:
+		return parser.Statements{lowering.switchStatement(stmt)}
+	case *ast.BlockStmt:
+		return parser.Statements{lowering.block(stmt.List)}
+	case *ast.BranchStmt:
+		if stmt.Label != nil {
+			lowering.unsupported(stmt.Label, "labels")
+		}
+
+		if stmt.Tok != token.BREAK && stmt.Tok != token.CONTINUE {
+			lowering.unsupported(stmt, stmt.Tok.String()+" statements")
+		}
+
+		return parser.Statements{parser.BranchStatement{Keyword: stmt.Tok.String(), Position: position}}
+	case *ast.EmptyStmt:
+		return nil
+	case *ast.ForStmt, *ast.RangeStmt:
+		lowering.unsupported(stmt, "for loops")
+	case *ast.LabeledStmt:
+		lowering.unsupported(stmt, "labels")
+	case *ast.GoStmt:
+		lowering.unsupported(stmt, "go statements")
+	case *ast.DeferStmt:
+		lowering.unsupported(stmt, "defer statements")
+	case *ast.SelectStmt:
+		lowering.unsupported(stmt, "select statements")
+	case *ast.TypeSwitchStmt:
+		lowering.unsupported(stmt, "type switches")
+	case *ast.SendStmt:
+		lowering.unsupported(stmt, "channel sends")
+	default:
+		lowering.unsupported(stmt, "statements of this kind")
+	}
+
+	return nil
+}
+
+// Several values are assigned one by one, if none of them reads the targets:
+//   a, b := 1, 2   ->   a := 1; b := 2
+// Otherwise they're assigned at once: a, b = b, a
+// Assignment with operator is lowered to plain one: a += 2 -> a = a + 2
+func (lowering *lowering) assignStatement(stmt *ast.AssignStmt) parser.Statements {
+	if stmt.Tok != token.ASSIGN && stmt.Tok != token.DEFINE {
+		operator := strings.TrimSuffix(stmt.Tok.String(), "=")
+		value := lowering.expression(stmt.Rhs[0])
+		return parser.Statements{lowering.operatorAssignment(stmt, stmt.Lhs[0], operator, value, stmt.TokPos)}
+	}
+
+	if len(stmt.Rhs) == 1 {
+		return parser.Statements{parser.AssignStatement{
+			Targets:    lowering.expressions(stmt.Lhs),
+			Operator:   stmt.Tok.String(),
+			Expression: lowering.expression(stmt.Rhs[0]),
+			Position:   lowering.position(stmt.Pos()),
+		}}
+	}
+
+	if lowering.readsTargets(stmt) {
+		return parser.Statements{parser.AssignStatement{
+			Targets:    lowering.expressions(stmt.Lhs),
+			Operator:   stmt.Tok.String(),
+			Expression: lowering.expressions(stmt.Rhs),
+			Position:   lowering.position(stmt.Pos()),
+		}}
+	}
+
+	var stmts parser.Statements
+
+	for i, target := range stmt.Lhs {
+		operator := token.ASSIGN.String()
+
+		// Targets which are declared again keep their variables: a, err := 1, nil
+		if ident, status := target.(*ast.Ident); status && stmt.Tok == token.DEFINE && lowering.info.Defs[ident] != nil {
+			operator = token.DEFINE.String()
+		}
+
+		stmts = append(stmts, parser.AssignStatement{
+			Targets:    parser.Expressions{lowering.expression(target)},
+			Operator:   operator,
+			Expression: lowering.expression(stmt.Rhs[i]),
+			Position:   lowering.position(target.Pos()),
+		})
+	}
+
+	return stmts
+}
+
+// Target is read by the expression, so it's checked that it has no calls: a[f()] += 1
+func (lowering *lowering) operatorAssignment(
+	stmt ast.Stmt,
+	target ast.Expr,
+	operator string,
+	value parser.Expression,
+	operatorPos token.Pos,
+) parser.Statement {
+	ast.Inspect(target, func(node ast.Node) bool {
+		if _, status := node.(*ast.CallExpr); status {
+			lowering.unsupported(stmt, "assignments with operator to targets with calls")
+			return false
+		}
+
+		return true
+	})
+
+	x := lowering.expression(target)
+	expr := parser.BinaryExpression{LeftOperand: x, Operator: operator, RightOperand: value, Position: lowering.position(operatorPos)}
+
+	return parser.AssignStatement{
+		Targets:    parser.Expressions{x},
+		Operator:   token.ASSIGN.String(),
+		Expression: expr,
+		Position:   lowering.position(stmt.Pos()),
+	}
+}
+
+// Whether some value refers to the variable of some target: a, b = b, a
+func (lowering *lowering) readsTargets(stmt *ast.AssignStmt) bool {
+	targets := map[*ast.Ident]bool{}
+
+	for _, target := range stmt.Lhs {
+		ast.Inspect(target, func(node ast.Node) bool {
+			if ident, status := node.(*ast.Ident); status {
+				targets[ident] = true
+			}
+
+			return true
+		})
+	}
+
+	reads := false
+
+	for _, value := range stmt.Rhs {
+		ast.Inspect(value, func(node ast.Node) bool {
+			ident, status := node.(*ast.Ident)
+
+			if !status || lowering.info.Uses[ident] == nil {
+				return true
+			}
+
+			for target := range targets {
+				if lowering.object(target) == lowering.info.Uses[ident] {
+					reads = true
+				}
+			}
+
+			return true
+		})
+	}
+
+	return reads
+}
+
+// Object which identifier declares or refers to
+func (lowering *lowering) object(ident *ast.Ident) types.Object {
+	if object := lowering.info.Defs[ident]; object != nil {
+		return object
+	}
+
+	return lowering.info.Uses[ident]
+}
+
+// Each variable gets its own statement: var a, b int -> var a int; var b int
+func (lowering *lowering) declStatement(decl *ast.GenDecl) parser.Statements {
+	if decl.Tok != token.VAR {
+		lowering.unsupported(decl, "local "+decl.Tok.String()+" declarations")
+		return nil
+	}
+
+	var stmts parser.Statements
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+
+		if len(spec.Values) > 0 && len(spec.Values) != len(spec.Names) {
+			lowering.unsupported(spec, "var declarations of several values from one call")
+			continue
+		}
+
+		for i, name := range spec.Names {
+			stmt := parser.VarStatement{Identifier: lowering.identifier(name), Position: lowering.specPosition(decl, spec)}
+
+			if spec.Type != nil {
+				stmt.Type = lowering.expression(spec.Type)
+			}
+			if len(spec.Values) > 0 {
+				stmt.Expression = lowering.expression(spec.Values[i])
+			}
+
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	return stmts
+}
+
+// Else if is kept as else block with the single if statement
+func (lowering *lowering) ifStatement(stmt *ast.IfStmt) parser.IfStatement {
+	if stmt.Init != nil {
+		lowering.unsupported(stmt.Init, "init statements of if")
+	}
+
+	ifStmt := parser.IfStatement{
+		Condition: lowering.expression(stmt.Cond),
+		IfBody:    lowering.block(stmt.Body.List),
+		Position:  lowering.position(stmt.Pos()),
+	}
+
+	if elseIf, status := stmt.Else.(*ast.IfStmt); status {
+		ifStmt.ElseBody = parser.BlockStatement{Statements: parser.Statements{lowering.ifStatement(elseIf)}}
+	} else if block, status := stmt.Else.(*ast.BlockStmt); status {
+		ifStmt.ElseBody = lowering.block(block.List)
+	}
+
+	return ifStmt
+}
+
+// Switch without tag and default case have empty expressions, the same way the native parser has them
+func (lowering *lowering) switchStatement(stmt *ast.SwitchStmt) parser.SwitchStatement {
+	if stmt.Init != nil {
+		lowering.unsupported(stmt.Init, "init statements of switch")
+	}
+
+	switchStmt := parser.SwitchStatement{Expression: parser.UnaryExpression{}, Position: lowering.position(stmt.Pos())}
+
+	if stmt.Tag != nil {
+		switchStmt.Expression = lowering.expression(stmt.Tag)
+	}
+
+	for _, clause := range stmt.Body.List {
+		clause := clause.(*ast.CaseClause)
+		caseStmt := parser.CaseStatement{Expression: parser.UnaryExpression{}, Position: lowering.position(clause.Pos())}
+
+		if len(clause.List) > 0 {
+			caseStmt.Expression = lowering.expression(clause.List[0])
+		}
+		if len(clause.List) > 1 {
+			lowering.unsupported(clause.List[1], "cases with several values")
+		}
+
+		caseStmt.Body = lowering.block(clause.Body)
+		switchStmt.Body = append(switchStmt.Body, caseStmt)
+	}
+
+	return switchStmt
+}
@@ -0,0 +1,253 @@
+package interpreter
+
+import (
+	"../lwiqa"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Name of the call is looked up in order: procedure, record type, other type, standard function.
+// Standard functions and conversions are found in any case: LENGTH, length.
+func (interpreter *Interpreter) evaluateCall(call lwiqa.CallExpression, frame *frame) Value {
+	name := call.Function.Name
+
+	if procedure := interpreter.program.Procedure(name); procedure != nil {
+		return interpreter.call(procedure, call.Arguments, frame)
+	}
+
+	if t, status := interpreter.types[name]; status {
+		if record, status := interpreter.resolve(t).(lwiqa.RecordType); status {
+			return interpreter.record(record, call.Arguments, frame)
+		}
+
+		interpreter.arguments(call, 1)
+		return interpreter.conversion(interpreter.resolve(t), interpreter.evaluate(call.Arguments[0], frame))
+	}
+
+	switch basic := lwiqa.BasicType(strings.ToUpper(name)); basic {
+	case lwiqa.Integer, lwiqa.Real, lwiqa.String, lwiqa.Boolean:
+		interpreter.arguments(call, 1)
+		return interpreter.conversion(basic, interpreter.evaluate(call.Arguments[0], frame))
+	case "RECORD":
+		return interpreter.record(lwiqa.RecordType{}, call.Arguments, frame)
+	}
+
+	return interpreter.builtin(call, frame)
+}
+
+func (interpreter *Interpreter) arguments(call lwiqa.CallExpression, count int) {
+	if len(call.Arguments) != count {
+		fail("%s takes %d arguments, got %d", describe(call.Function), count, len(call.Arguments))
+	}
+}
+
+// Fields are given in order or with their names: &Point&(1, 2), &Point&(&X&: 1).
+// Anonymous record gets its fields when it's assigned: RECORD(&X&: 1, &Y&: 2)
+func (interpreter *Interpreter) record(t lwiqa.RecordType, args lwiqa.Expressions, frame *frame) *Record {
+	record := interpreter.zero(t).(*Record)
+	literal := len(t.Fields) == 0
+
+	for i, arg := range args {
+		index := i
+
+		if pair, status := arg.(lwiqa.KeyValueExpression); status {
+			key, status := pair.Key.(lwiqa.Identifier)
+
+			if !status {
+				fail("name of field expected")
+			}
+
+			if literal {
+				record.Type.Fields = append(record.Type.Fields, lwiqa.Parameter{Name: key.Name})
+				record.Fields = append(record.Fields, interpreter.evaluate(pair.Value, frame))
+				continue
+			}
+
+			index = fieldIndex(t, key.Name)
+			arg = pair.Value
+		} else if literal {
+			record.Type.Fields = append(record.Type.Fields, lwiqa.Parameter{})
+			record.Fields = append(record.Fields, interpreter.evaluate(arg, frame))
+			continue
+		}
+
+		if index < 0 || index >= len(record.Fields) {
+			fail("record %s has no such field", t.Name)
+		}
+
+		record.Fields[index] = interpreter.store(interpreter.evaluate(arg, frame), t.Fields[index].Type)
+	}
+
+	return record
+}
+
+// Conversions work the same way as in Go: INTEGER(2.5) is 2, STRING(65) is "A"
+func (interpreter *Interpreter) conversion(t lwiqa.Type, value Value) Value {
+	switch t {
+	case lwiqa.Integer:
+		switch x := value.(type) {
+		case int64:
+			return x
+		case float64:
+			return int64(x)
+		}
+	case lwiqa.Real:
+		switch x := value.(type) {
+		case int64:
+			return float64(x)
+		case float64:
+			return x
+		}
+	case lwiqa.String:
+		switch x := value.(type) {
+		case int64:
+			return string(rune(x))
+		case string:
+			return x
+		}
+	case lwiqa.Boolean:
+		if x, status := value.(bool); status {
+			return x
+		}
+	default:
+		return interpreter.store(value, t)
+	}
+
+	fail("%s can't be converted to %s", FormatValue(value), t)
+	return nil
+}
+
+//   LENGTH(&a&), APPEND(&a&, 1, 2), LOOKUP(&m&, "a", &v&, &ok&), FIXED(&x&, 2), SQRT(&x&)
+// LOOKUP assigns the value and whether it's found to its last arguments.
+func (interpreter *Interpreter) builtin(call lwiqa.CallExpression, frame *frame) Value {
+	name := strings.ToUpper(call.Function.Name)
+	var args []Value
+
+	for _, arg := range call.Arguments {
+		if name == "LOOKUP" && len(args) == 2 {
+			break
+		}
+
+		args = append(args, interpreter.evaluate(arg, frame))
+	}
+
+	switch name {
+	case "LENGTH", "CAPACITY":
+		interpreter.arguments(call, 1)
+
+		switch x := args[0].(type) {
+		case *Array:
+			if name == "CAPACITY" {
+				return int64(cap(x.Elements))
+			}
+
+			return int64(len(x.Elements))
+		case *Map:
+			return int64(len(x.Entries))
+		case string:
+			return int64(len(x))
+		}
+	case "APPEND":
+		if len(args) == 0 {
+			fail("APPEND takes an array")
+		}
+
+		if array, status := args[0].(*Array); status {
+			result := &Array{array.Elements, array.Type}
+
+			for _, element := range args[1:] {
+				result.Elements = append(result.Elements, interpreter.store(element, array.Type.Element))
+			}
+
+			return result
+		}
+	case "DELETE", "CONTAINS":
+		interpreter.arguments(call, 2)
+
+		if m, status := args[0].(*Map); status {
+			key := m.key(interpreter.convert(args[1], m.Type.Key))
+			_, found := m.Entries[key]
+
+			if name == "CONTAINS" {
+				return found
+			}
+
+			delete(m.Entries, key)
+			return nil
+		}
+	case "LOOKUP":
+		interpreter.arguments(call, 4)
+
+		if m, status := args[0].(*Map); status {
+			value, found := m.Entries[m.key(interpreter.convert(args[1], m.Type.Key))]
+
+			if !found {
+				value = interpreter.zero(m.Type.Value)
+			}
+
+			interpreter.assign(call.Arguments[2], value, frame)
+			interpreter.assign(call.Arguments[3], found, frame)
+			return nil
+		}
+	case "FIXED":
+		interpreter.arguments(call, 2)
+		x, xStatus := toReal(args[0])
+		digits, digitsStatus := args[1].(int64)
+
+		if xStatus && digitsStatus {
+			return strconv.FormatFloat(x, 'f', int(digits), 64)
+		}
+	case "SQRT", "ABS", "FLOOR":
+		interpreter.arguments(call, 1)
+
+		if x, status := toReal(args[0]); status {
+			return map[string]func(float64) float64{"SQRT": math.Sqrt, "ABS": math.Abs, "FLOOR": math.Floor}[name](x)
+		}
+	case "POWER", "MAX", "MIN":
+		interpreter.arguments(call, 2)
+		x, xStatus := toReal(args[0])
+		y, yStatus := toReal(args[1])
+
+		if xStatus && yStatus {
+			return map[string]func(float64, float64) float64{"POWER": math.Pow, "MAX": math.Max, "MIN": math.Min}[name](x, y)
+		}
+	default:
+		fail("%s isn't declared", describe(call.Function))
+	}
+
+	fail("wrong arguments of %s", name)
+	return nil
+}
+
+// Math functions take reals, integers are converted the way Go converts constants
+func toReal(value Value) (float64, bool) {
+	switch x := value.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+
+	return 0, false
+}
+
+// Word of input is read as a value of the same type as the target has
+func parseInput(word string, target Value) (Value, bool) {
+	switch target.(type) {
+	case int64:
+		value, err := strconv.ParseInt(word, 0, 64)
+		return value, err == nil
+	case float64:
+		value, err := strconv.ParseFloat(word, 64)
+		return value, err == nil
+	case string:
+		return word, true
+	case bool:
+		value, err := strconv.ParseBool(word)
+		return value, err == nil
+	}
+
+	fail("%s can't be read", FormatValue(target))
+	return nil, false
+}
@@ -0,0 +1,333 @@
+package interpreter
+
+import (
+	"../lwiqa"
+	"fmt"
+)
+
+func (interpreter *Interpreter) evaluate(expression lwiqa.Expression, frame *frame) Value {
+	if expr, status := expression.(lwiqa.Literal); status {                     // Literal
+		return expr.Value
+	} else if expr, status := expression.(lwiqa.Identifier); status {           // Identifier
+		value, status := frame.variables[expr.Name]
+
+		if !status {
+			fail("variable %s isn't declared", describe(expr))
+		}
+
+		return value
+	} else if expr, status := expression.(lwiqa.UnaryExpression); status {      // Unary
+		return unaryOperation(expr.Operator, interpreter.evaluate(expr.Operand, frame))
+	} else if expr, status := expression.(lwiqa.BinaryExpression); status {     // Binary
+		return interpreter.evaluateBinaryExpression(expr, frame)
+	} else if expr, status := expression.(lwiqa.IndexExpression); status {      // Index
+		return interpreter.evaluateIndexExpression(expr, frame)
+	} else if expr, status := expression.(lwiqa.SelectorExpression); status {   // Selector
+		record, index := interpreter.field(expr, frame)
+		return record.Fields[index]
+	} else if expr, status := expression.(lwiqa.CallExpression); status {       // Call
+		return interpreter.evaluateCall(expr, frame)
+	} else if expr, status := expression.(lwiqa.ArrayLiteral); status {         // Array
+		array := &Array{[]Value{}, lwiqa.ArrayType{Length: -1}}
+
+		for _, element := range expr.Elements {
+			array.Elements = append(array.Elements, interpreter.evaluate(element, frame))
+		}
+
+		return array
+	} else if expr, status := expression.(lwiqa.MapLiteral); status {           // Map
+		m := &Map{map[Value]Value{}, lwiqa.MapType{}, nil}
+
+		for _, element := range expr.Elements {
+			pair, status := element.(lwiqa.KeyValueExpression)
+
+			if !status {
+				fail("element of map has no key")
+			}
+
+			m.Entries[m.key(interpreter.evaluate(pair.Key, frame))] = interpreter.evaluate(pair.Value, frame)
+		}
+
+		return m
+	}
+
+	fail("unexpected expression")
+	return nil
+}
+
+// AND and OR don't evaluate the right operand if the left one gives the result
+func (interpreter *Interpreter) evaluateBinaryExpression(expr lwiqa.BinaryExpression, frame *frame) Value {
+	left := interpreter.evaluate(expr.LeftOperand, frame)
+
+	if expr.Operator == "AND" || expr.Operator == "OR" {
+		condition, status := left.(bool)
+
+		if !status {
+			fail("operand of %s is not BOOLEAN", expr.Operator)
+		}
+
+		if condition == (expr.Operator == "OR") {
+			return condition
+		}
+
+		right, status := interpreter.evaluate(expr.RightOperand, frame).(bool)
+
+		if !status {
+			fail("operand of %s is not BOOLEAN", expr.Operator)
+		}
+
+		return right
+	}
+
+	return binaryOperation(left, expr.Operator, interpreter.evaluate(expr.RightOperand, frame))
+}
+
+// Missing key of map gives zero value, characters of strings are integers
+func (interpreter *Interpreter) evaluateIndexExpression(expr lwiqa.IndexExpression, frame *frame) Value {
+	container := interpreter.evaluate(expr.Expression, frame)
+	index := interpreter.evaluate(expr.Index, frame)
+
+	switch container := container.(type) {
+	case *Array:
+		return container.Elements[arrayIndex(index, len(container.Elements))]
+	case *Map:
+		if value, status := container.Entries[container.key(interpreter.convert(index, container.Type.Key))]; status {
+			return value
+		}
+
+		return interpreter.zero(container.Type.Value)
+	case string:
+		return int64(container[arrayIndex(index, len(container))])
+	}
+
+	fail("%s can't be indexed", FormatValue(container))
+	return nil
+}
+
+// Record and index of its field
+func (interpreter *Interpreter) field(expr lwiqa.SelectorExpression, frame *frame) (*Record, int) {
+	record, status := interpreter.evaluate(expr.Expression, frame).(*Record)
+
+	if !status {
+		fail("%s is not a record", describe(expr.Expression))
+	}
+
+	index := fieldIndex(record.Type, expr.Field.Name)
+
+	if index < 0 {
+		fail("record has no field %s", describe(expr.Field))
+	}
+
+	return record, index
+}
+
+func arrayIndex(index Value, length int) int {
+	i, status := index.(int64)
+
+	if !status {
+		fail("index %s is not INTEGER", FormatValue(index))
+	}
+
+	if i < 0 || i >= int64(length) {
+		fail("index %d is out of range [0, %d)", i, length)
+	}
+
+	return int(i)
+}
+
+// Value is stored into the variable, element or field with the type it's declared with.
+// Assignment to &_& is skipped.
+func (interpreter *Interpreter) assign(target lwiqa.Expression, value Value, frame *frame) {
+	var description string
+
+	if expr, status := target.(lwiqa.Identifier); status {                      // Variable
+		if expr.Name == "_" {
+			return
+		}
+
+		value = interpreter.store(value, frame.types[expr.Name])
+		frame.variables[expr.Name] = value
+		description = describe(expr)
+	} else if expr, status := target.(lwiqa.IndexExpression); status {          // Element
+		container := interpreter.evaluate(expr.Expression, frame)
+		index := interpreter.evaluate(expr.Index, frame)
+
+		if array, status := container.(*Array); status {
+			value = interpreter.store(value, array.Type.Element)
+			array.Elements[arrayIndex(index, len(array.Elements))] = value
+		} else if m, status := container.(*Map); status {
+			if m.Entries == nil {
+				fail("assignment to entry in nil map")
+			}
+
+			value = interpreter.store(value, m.Type.Value)
+			m.Entries[m.key(interpreter.convert(index, m.Type.Key))] = value
+		} else {
+			fail("element of %s can't be assigned", FormatValue(container))
+		}
+
+		description = fmt.Sprintf("%s[%s]", describe(expr.Expression), FormatValue(index))
+	} else if expr, status := target.(lwiqa.SelectorExpression); status {       // Field
+		record, index := interpreter.field(expr, frame)
+		value = interpreter.store(value, record.Type.Fields[index].Type)
+		record.Fields[index] = value
+		description = describe(expr)
+	} else {
+		fail("value can't be assigned to the expression")
+	}
+
+	interpreter.trace("%s = %s", description, FormatValue(value))
+}
+
+// Target as it's shown in traces and errors: &a&, &p&.&X&
+func describe(expression lwiqa.Expression) string {
+	if expr, status := expression.(lwiqa.Identifier); status {
+		if expr.Marked {
+			return "&" + expr.Name + "&"
+		}
+
+		return expr.Name
+	} else if expr, status := expression.(lwiqa.SelectorExpression); status {
+		return describe(expr.Expression) + "." + describe(expr.Field)
+	} else if expr, status := expression.(lwiqa.IndexExpression); status {
+		return describe(expr.Expression) + "[..]"
+	}
+
+	return "expression"
+}
+
+func unaryOperation(operator string, operand Value) Value {
+	switch x := operand.(type) {
+	case int64:
+		switch operator {
+		case "+":
+			return x
+		case "-":
+			return -x
+		case "BITNOT":
+			return ^x
+		}
+	case float64:
+		switch operator {
+		case "+":
+			return x
+		case "-":
+			return -x
+		}
+	case bool:
+		if operator == "NOT" {
+			return !x
+		}
+	}
+
+	fail("operator %s can't be used with %s", operator, FormatValue(operand))
+	return nil
+}
+
+// Operands have the same type, except integer used with real.
+// Integers are divided the same way as in Go, with / as well as with DIV.
+func binaryOperation(left Value, operator string, right Value) Value {
+	switch operator {
+	case "=":
+		return isEqual(left, right)
+	case "<>":
+		return !isEqual(left, right)
+	}
+
+	left, right = Promote(left, right)
+
+	switch x := left.(type) {
+	case int64:
+		if y, status := right.(int64); status {
+			if value, status := integerOperation(x, operator, y); status {
+				return value
+			}
+		}
+	case float64:
+		if y, status := right.(float64); status {
+			switch operator {
+			case "+":
+				return x + y
+			case "-":
+				return x - y
+			case "*":
+				return x * y
+			case "/":
+				return x / y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	case string:
+		if y, status := right.(string); status {
+			switch operator {
+			case "+", "CONCAT":
+				return x + y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+
+	fail("operator %s can't be used with %s and %s", operator, FormatValue(left), FormatValue(right))
+	return nil
+}
+
+func integerOperation(x int64, operator string, y int64) (Value, bool) {
+	switch operator {
+	case "+":
+		return x + y, true
+	case "-":
+		return x - y, true
+	case "*":
+		return x * y, true
+	case "/", "DIV", "MOD":
+		if y == 0 {
+			fail("division by zero")
+		}
+
+		if operator == "MOD" {
+			return x % y, true
+		}
+
+		return x / y, true
+	case "BITAND":
+		return x & y, true
+	case "BITOR":
+		return x | y, true
+	case "BITXOR":
+		return x ^ y, true
+	case "SHL", "SHR":
+		if y < 0 {
+			fail("negative shift count %d", y)
+		}
+
+		if operator == "SHL" {
+			return x << uint64(y), true
+		}
+
+		return x >> uint64(y), true
+	case "<":
+		return x < y, true
+	case "<=":
+		return x <= y, true
+	case ">":
+		return x > y, true
+	case ">=":
+		return x >= y, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,355 @@
+package interpreter
+
+import (
+	"../lwiqa"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Programs start with procedure main and read their input and write their output
+// the same way Go programs do with fmt.Scan and fmt.Print.
+// Every call gets its own variables, so LOCAL lines change nothing.
+// There are no loops, so break and continue leave the innermost IF:
+// switch is written as IF chain, which break leaves.
+type Interpreter struct {
+	program *lwiqa.Program
+	types   map[string]lwiqa.Type
+	in      *bufio.Reader
+	out     io.Writer
+	options Options
+	line    lwiqa.Line // Statement which is executed
+	depth   int        // Calls which are running
+}
+
+type Options struct {
+	Trace    io.Writer // Executed lines and assigned values are written here if it's set
+	MaxDepth int       // Calls nested deeper stop the program, DefaultMaxDepth if it's zero
+}
+
+const DefaultMaxDepth = 10000
+
+// Error which stops the program, with the line of the statement it happens in
+type Error struct {
+	Line    lwiqa.Line
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("line %d (Q%s): %s", err.Line.Number, err.Line.Index, err.Message)
+}
+
+// Runtime errors are raised as panics with this type, so they stop the program at once
+type runtimeError string
+
+func fail(format string, args ...interface{}) {
+	panic(runtimeError(fmt.Sprintf(format, args...)))
+}
+
+// Variables of the running procedure, types are known for declared ones
+type frame struct {
+	procedure *lwiqa.Procedure
+	variables map[string]Value
+	types     map[string]lwiqa.Type
+	result    Value
+}
+
+// Result of executed statement
+type control int
+
+const (
+	proceed control = iota
+	returned
+	branched // break or continue leaves the IF
+)
+
+func NewInterpreter(program *lwiqa.Program, in io.Reader, out io.Writer, options Options) *Interpreter {
+	types := map[string]lwiqa.Type{}
+
+	for _, declaration := range program.Declarations {
+		if decl, status := declaration.(*lwiqa.TypeDeclaration); status {
+			types[decl.Name] = decl.Type
+		}
+	}
+
+	if options.MaxDepth == 0 {
+		options.MaxDepth = DefaultMaxDepth
+	}
+
+	return &Interpreter{program, types, bufio.NewReader(in), out, options, lwiqa.Line{}, 0}
+}
+
+// Variables of main are returned with the values they have at the end
+func (interpreter *Interpreter) Run() (variables map[string]Value, err error) {
+	main := interpreter.program.Procedure("main")
+
+	if main == nil {
+		return nil, fmt.Errorf("procedure main is not declared")
+	}
+
+	defer func() {
+		recovered := recover()
+
+		if message, status := recovered.(runtimeError); status {
+			variables, err = nil, &Error{interpreter.line, string(message)}
+		} else if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	interpreter.line = main.Line
+	frame := interpreter.newFrame(main)
+	interpreter.execute(main.Body, frame)
+
+	return frame.variables, nil
+}
+
+// Variables declared in VAR block start with zero values
+func (interpreter *Interpreter) newFrame(procedure *lwiqa.Procedure) *frame {
+	frame := &frame{procedure, map[string]Value{}, map[string]lwiqa.Type{}, nil}
+
+	for _, variable := range procedure.Variables {
+		frame.declare(variable.Name, variable.Type, interpreter.zero(variable.Type))
+	}
+
+	return frame
+}
+
+func (frame *frame) declare(name string, t lwiqa.Type, value Value) {
+	frame.types[name] = t
+	frame.variables[name] = value
+}
+
+func (interpreter *Interpreter) execute(stmts lwiqa.Statements, frame *frame) control {
+	for _, stmt := range stmts {
+		if control := interpreter.executeStatement(stmt, frame); control != proceed {
+			return control
+		}
+	}
+
+	return proceed
+}
+
+func (interpreter *Interpreter) executeStatement(statement lwiqa.Statement, frame *frame) control {
+	interpreter.line = statement.Source()
+
+	if stmt, status := statement.(lwiqa.IfStatement); status {                      // If
+		return interpreter.executeIfStatement(stmt, frame)
+	}
+
+	interpreter.traceLine(statement.Source())
+
+	if stmt, status := statement.(lwiqa.AssignStatement); status {                  // Assign
+		if len(stmt.Targets) != len(stmt.Values) {
+			fail("%d values assigned to %d targets", len(stmt.Values), len(stmt.Targets))
+		}
+
+		var values []Value
+
+		for _, value := range stmt.Values {
+			values = append(values, interpreter.evaluate(value, frame))
+		}
+
+		for i, target := range stmt.Targets {
+			interpreter.assign(target, values[i], frame)
+		}
+	} else if stmt, status := statement.(lwiqa.DeclareStatement); status {         // Array or map
+		value := interpreter.zero(stmt.Type)
+
+		if stmt.Value != nil {
+			value = interpreter.evaluate(stmt.Value, frame)
+		}
+
+		frame.types[stmt.Target.Name] = stmt.Type
+		interpreter.assign(stmt.Target, value, frame)
+	} else if stmt, status := statement.(lwiqa.CallStatement); status {            // Call
+		interpreter.evaluateCall(stmt.Call, frame)
+	} else if stmt, status := statement.(lwiqa.OutputStatement); status {          // Output
+		var values []interface{}
+
+		for _, item := range stmt.Items {
+			values = append(values, interpreter.evaluate(item, frame))
+		}
+
+		var err error
+
+		if stmt.Newline {
+			_, err = fmt.Fprintln(interpreter.out, values...)
+		} else {
+			_, err = fmt.Fprint(interpreter.out, values...)
+		}
+
+		if err != nil {
+			fail("could not write output: %v", err)
+		}
+	} else if stmt, status := statement.(lwiqa.InputStatement); status {           // Input
+		interpreter.executeInputStatement(stmt, frame)
+	} else if stmt, status := statement.(lwiqa.ReturnStatement); status {          // Return
+		if stmt.Value != nil {
+			frame.result = interpreter.store(interpreter.evaluate(stmt.Value, frame), frame.procedure.Result)
+		}
+
+		return returned
+	} else if _, status := statement.(lwiqa.BranchStatement); status {             // Branch
+		return branched
+	}
+
+	return proceed
+}
+
+// Branches are checked in order, break in the taken branch leaves the whole IF
+func (interpreter *Interpreter) executeIfStatement(stmt lwiqa.IfStatement, frame *frame) control {
+	for _, branch := range stmt.Branches {
+		interpreter.line = branch.Line
+		interpreter.traceLine(branch.Line)
+
+		if branch.Condition != nil {
+			condition, status := interpreter.evaluate(branch.Condition, frame).(bool)
+
+			if !status {
+				fail("condition is not BOOLEAN")
+			}
+
+			interpreter.trace("= %t", condition)
+
+			if !condition {
+				continue
+			}
+		}
+
+		control := interpreter.execute(branch.Body, frame)
+
+		if control == branched {
+			return proceed
+		}
+
+		return control
+	}
+
+	return proceed
+}
+
+// Values are read the same way as with fmt.Scan, they are separated by spaces and new lines.
+// Type of value is the type of target. Reading stops at the end of input
+// or at the value which can't be read, the rest of targets keep their values.
+func (interpreter *Interpreter) executeInputStatement(stmt lwiqa.InputStatement, frame *frame) {
+	for _, target := range stmt.Targets {
+		word := interpreter.readWord()
+
+		if word == "" {
+			return
+		}
+
+		value, status := parseInput(word, interpreter.evaluate(target, frame))
+
+		if !status {
+			return
+		}
+
+		interpreter.assign(target, value, frame)
+	}
+}
+
+// Next word of input, empty at the end of input
+func (interpreter *Interpreter) readWord() string {
+	var word strings.Builder
+
+	for {
+		r, _, err := interpreter.in.ReadRune()
+
+		if err != nil {
+			break
+		}
+
+		if strings.ContainsRune(" \t\r\n", r) {
+			if word.Len() > 0 {
+				break
+			}
+
+			continue
+		}
+
+		word.WriteRune(r)
+	}
+
+	return word.String()
+}
+
+// Call of procedure by its name: arguments are copied into parameters,
+// output parameters are copied back into their targets after the call.
+func (interpreter *Interpreter) call(procedure *lwiqa.Procedure, args lwiqa.Expressions, caller *frame) Value {
+	if len(args) != len(procedure.Parameters) {
+		fail("procedure %s takes %d arguments, got %d", procedure.Name, len(procedure.Parameters), len(args))
+	}
+
+	if interpreter.depth >= interpreter.options.MaxDepth {
+		fail("calls are nested deeper than %d", interpreter.options.MaxDepth)
+	}
+
+	frame := interpreter.newFrame(procedure)
+	var values []string
+
+	for i, param := range procedure.Parameters {
+		if param.Output {
+			frame.declare(param.Name, param.Type, interpreter.zero(param.Type))
+			continue
+		}
+
+		value := interpreter.store(interpreter.evaluate(args[i], caller), param.Type)
+		frame.declare(param.Name, param.Type, value)
+		values = append(values, FormatValue(value))
+	}
+
+	line := interpreter.line
+	interpreter.trace("CALL %s(%s)", procedure.Name, strings.Join(values, ", "))
+	interpreter.depth++
+	interpreter.line = procedure.Line
+	interpreter.execute(procedure.Body, frame)
+	interpreter.depth--
+	interpreter.line = line
+
+	if procedure.Result != nil {
+		if frame.result == nil {
+			fail("procedure %s ended without RETURN", procedure.Name)
+		}
+
+		interpreter.trace("RETURN %s", FormatValue(frame.result))
+	}
+
+	for i, param := range procedure.Parameters {
+		if param.Output {
+			interpreter.assign(args[i], frame.variables[param.Name], caller)
+		}
+	}
+
+	return frame.result
+}
+
+// Lines are written as they are in the program, nested calls are indented
+func (interpreter *Interpreter) traceLine(line lwiqa.Line) {
+	if interpreter.options.Trace == nil {
+		return
+	}
+
+	for _, str := range strings.Split(line.Text, "\n") {
+		interpreter.writeTrace(str)
+	}
+}
+
+// Events are written after the line they happen in: &a& = 2
+func (interpreter *Interpreter) trace(format string, args ...interface{}) {
+	if interpreter.options.Trace == nil {
+		return
+	}
+
+	interpreter.writeTrace("  " + fmt.Sprintf(format, args...))
+}
+
+func (interpreter *Interpreter) writeTrace(str string) {
+	_, err := io.WriteString(interpreter.options.Trace, strings.Repeat("  ", interpreter.depth)+str+"\n")
+
+	if err != nil {
+		fail("could not write trace: %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package interpreter
+
+import (
+	"../lwiqa"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Values are int64, float64, string, bool, *Array, *Map and *Record.
+// Values are printed the same way Go prints values of the types they come from.
+type Value interface{}
+
+// Arrays of fixed length are copied when they're assigned,
+// arrays of dynamic length share their elements the same way Go slices do.
+// Type of array literal is unknown until it's assigned.
+type Array struct {
+	Elements []Value
+	Type     lwiqa.ArrayType
+}
+
+// Maps are shared when they're assigned. Map which isn't created yet has nil entries,
+// it can be read but not written. Arrays and records are keys by their values, see key.
+type Map struct {
+	Entries map[Value]Value
+	Type    lwiqa.MapType
+	keys    map[string]Value // Keys which are arrays and records by their text
+}
+
+// Records are copied when they're assigned
+type Record struct {
+	Fields []Value
+	Type   lwiqa.RecordType
+}
+
+//   [1 2 3]
+func (array *Array) String() string {
+	var elements []string
+
+	for _, element := range array.Elements {
+		elements = append(elements, fmt.Sprint(element))
+	}
+
+	return "[" + strings.Join(elements, " ") + "]"
+}
+
+// Keys are sorted: map[a:1 b:2]
+func (m *Map) String() string {
+	var keys []Value
+
+	for key := range m.Entries {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return IsLess(keys[i], keys[j])
+	})
+
+	var entries []string
+
+	for _, key := range keys {
+		entries = append(entries, fmt.Sprint(key)+":"+fmt.Sprint(m.Entries[key]))
+	}
+
+	return "map[" + strings.Join(entries, " ") + "]"
+}
+
+// Arrays and records are equal keys if their values are equal,
+// so entries are keyed by the first key which is equal to the given one
+func (m *Map) key(key Value) Value {
+	switch key.(type) {
+	case *Array, *Record:
+	default:
+		return key
+	}
+
+	text := keyText(key)
+
+	if existing, status := m.keys[text]; status {
+		return existing
+	}
+
+	if m.keys == nil {
+		m.keys = map[string]Value{}
+	}
+
+	key = copyValue(key)
+	m.keys[text] = key
+	return key
+}
+
+// Text which is the same only for equal keys, strings are quoted: {1 "a b"}
+func keyText(key Value) string {
+	var parts []string
+
+	switch k := key.(type) {
+	case *Array:
+		for _, element := range k.Elements {
+			parts = append(parts, keyText(element))
+		}
+
+		return "[" + strings.Join(parts, " ") + "]"
+	case *Record:
+		for _, field := range k.Fields {
+			parts = append(parts, keyText(field))
+		}
+
+		return "{" + strings.Join(parts, " ") + "}"
+	}
+
+	return FormatValue(key)
+}
+
+//   {1 2 a}
+func (record *Record) String() string {
+	var fields []string
+
+	for _, field := range record.Fields {
+		fields = append(fields, fmt.Sprint(field))
+	}
+
+	return "{" + strings.Join(fields, " ") + "}"
+}
+
+// Value as it's shown in traces and differences of runs, strings are quoted: "a"
+func FormatValue(value Value) string {
+	if str, status := value.(string); status {
+		return strconv.Quote(str)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// Order of map keys when maps are printed, false goes before true
+func IsLess(x Value, y Value) bool {
+	switch x := x.(type) {
+	case int64:
+		return x < y.(int64)
+	case float64:
+		return x < y.(float64)
+	case string:
+		return x < y.(string)
+	case bool:
+		return !x && y.(bool)
+	}
+
+	return fmt.Sprint(x) < fmt.Sprint(y)
+}
+
+// Named types are replaced with types they're declared with, declarations can't form a cycle
+func (interpreter *Interpreter) resolve(t lwiqa.Type) lwiqa.Type {
+	for i := 0; i <= len(interpreter.types); i++ {
+		named, status := t.(lwiqa.NamedType)
+
+		if !status {
+			return t
+		}
+
+		t = interpreter.types[named.Name]
+	}
+
+	return t
+}
+
+// Zero value of the type, nil if type isn't known
+func (interpreter *Interpreter) zero(t lwiqa.Type) Value {
+	switch t := interpreter.resolve(t).(type) {
+	case lwiqa.BasicType:
+		switch t {
+		case lwiqa.Integer:
+			return int64(0)
+		case lwiqa.Real:
+			return 0.0
+		case lwiqa.String:
+			return ""
+		case lwiqa.Boolean:
+			return false
+		}
+	case lwiqa.ArrayType:
+		array := &Array{[]Value{}, t}
+
+		for i := 0; i < t.Length; i++ {
+			array.Elements = append(array.Elements, interpreter.zero(t.Element))
+		}
+
+		return array
+	case lwiqa.MapType:
+		return &Map{nil, t, nil}
+	case lwiqa.RecordType:
+		record := &Record{nil, t}
+
+		for _, field := range t.Fields {
+			record.Fields = append(record.Fields, interpreter.zero(field.Type))
+		}
+
+		return record
+	}
+
+	return nil
+}
+
+// Value is copied into a variable, element or field of the type.
+// Arrays of fixed length and records get their own copy.
+func (interpreter *Interpreter) store(value Value, t lwiqa.Type) Value {
+	return copyValue(interpreter.convert(value, t))
+}
+
+func copyValue(value Value) Value {
+	switch v := value.(type) {
+	case *Array:
+		if v.Type.Length < 0 {
+			return v
+		}
+
+		array := &Array{make([]Value, len(v.Elements)), v.Type}
+
+		for i, element := range v.Elements {
+			array.Elements[i] = copyValue(element)
+		}
+
+		return array
+	case *Record:
+		record := &Record{make([]Value, len(v.Fields)), v.Type}
+
+		for i, field := range v.Fields {
+			record.Fields[i] = copyValue(field)
+		}
+
+		return record
+	}
+
+	return value
+}
+
+// Literals get the type they're assigned to: integer assigned to REAL variable is real,
+// literal of array of fixed length gets zero elements up to its length:
+//   ARRAY &c&[2] OF ARRAY[3] OF INTEGER  /  [[1, 2, 3]]  ->  [[1 2 3] [0 0 0]]
+func (interpreter *Interpreter) convert(value Value, t lwiqa.Type) Value {
+	switch t := interpreter.resolve(t).(type) {
+	case lwiqa.BasicType:
+		if i, status := value.(int64); status && t == lwiqa.Real {
+			return float64(i)
+		}
+	case lwiqa.ArrayType:
+		if array, status := value.(*Array); status && array.Type.Element == nil {
+			array.Type = t
+
+			for i, element := range array.Elements {
+				array.Elements[i] = interpreter.store(element, t.Element)
+			}
+
+			for len(array.Elements) < t.Length {
+				array.Elements = append(array.Elements, interpreter.zero(t.Element))
+			}
+		}
+	case lwiqa.MapType:
+		if m, status := value.(*Map); status && m.Type.Value == nil {
+			entries := m.Entries
+			m.Entries, m.Type, m.keys = map[Value]Value{}, t, nil
+
+			for key, element := range entries {
+				m.Entries[m.key(interpreter.convert(key, t.Key))] = interpreter.store(element, t.Value)
+			}
+		}
+	case lwiqa.RecordType:
+		if record, status := value.(*Record); status && isRecordLiteral(record) {
+			return interpreter.anonymousRecord(record, t)
+		}
+	}
+
+	return value
+}
+
+// Record literal without type name gets fields of the type it's assigned to.
+// Fields of the literal are named if they're given with keys.
+func (interpreter *Interpreter) anonymousRecord(literal *Record, t lwiqa.RecordType) *Record {
+	record := interpreter.zero(t).(*Record)
+
+	for i, field := range literal.Type.Fields {
+		index := i
+
+		if field.Name != "" {
+			index = fieldIndex(t, field.Name)
+		}
+
+		if index >= 0 && index < len(record.Fields) {
+			record.Fields[index] = interpreter.store(literal.Fields[i], t.Fields[index].Type)
+		}
+	}
+
+	return record
+}
+
+// Record created with RECORD(...) has fields without types until it's assigned
+func isRecordLiteral(record *Record) bool {
+	for _, field := range record.Type.Fields {
+		if field.Type != nil {
+			return false
+		}
+	}
+
+	return record.Type.Name == ""
+}
+
+// Index of the field, -1 if record has no such field
+func fieldIndex(t lwiqa.RecordType, name string) int {
+	for i, field := range t.Fields {
+		if field.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Values are equal the same way as in Go, integer is equal to the same real
+func isEqual(x Value, y Value) bool {
+	x, y = Promote(x, y)
+
+	switch x := x.(type) {
+	case *Array:
+		array, status := y.(*Array)
+
+		if !status || len(x.Elements) != len(array.Elements) {
+			return false
+		}
+
+		for i := range x.Elements {
+			if !isEqual(x.Elements[i], array.Elements[i]) {
+				return false
+			}
+		}
+
+		return true
+	case *Record:
+		record, status := y.(*Record)
+
+		if !status || len(x.Fields) != len(record.Fields) {
+			return false
+		}
+
+		for i := range x.Fields {
+			if !isEqual(x.Fields[i], record.Fields[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return x == y
+}
+
+// Integer used with real is converted, the way Go converts constants: 2 * &x&
+func Promote(x Value, y Value) (Value, Value) {
+	if i, status := x.(int64); status {
+		if _, status := y.(float64); status {
+			return float64(i), y
+		}
+	}
+
+	if i, status := y.(int64); status {
+		if _, status := x.(float64); status {
+			return x, float64(i)
+		}
+	}
+
+	return x, y
+}
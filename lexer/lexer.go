@@ -9,6 +9,9 @@ type Lexer struct {
 	text          string
 	patterns      []Pair
 	remainingText string
+	line          int // Position of the beginning of remaining text
+	column        int
+	comments      int // Comments which are skipped, they aren't tokens
 }
 
 func NewLexer(text string) *Lexer {
@@ -22,7 +25,7 @@ func NewLexer(text string) *Lexer {
 		compiledPatterns = append(compiledPatterns, value)
 	}
 
-	return &Lexer{text, compiledPatterns, text}
+	return &Lexer{text, compiledPatterns, text, 1, 1, 0}
 }
 
 func (lexer *Lexer) Tokenize() []Token {
@@ -37,7 +40,12 @@ func (lexer *Lexer) Tokenize() []Token {
 
 		if token.TokenType != Comment {
 			tokens = append(tokens, token)
-		} else if token.Text[0:2] == "/*" {
+			continue
+		}
+
+		lexer.comments++
+
+		if token.Text[0:2] == "/*" {
 			lexer.consumeMultilineComment()
 		}
 	}
@@ -45,6 +53,11 @@ func (lexer *Lexer) Tokenize() []Token {
 	return tokens
 }
 
+// Whether comments were skipped by Tokenize
+func (lexer *Lexer) HasComments() bool {
+	return lexer.comments > 0
+}
+
 func (lexer *Lexer) nextToken() Token {
 	var token Token
 	var nearestIndices []int
@@ -62,9 +75,11 @@ func (lexer *Lexer) nextToken() Token {
 	}
 
 	if len(nearestIndices) > 0 {
+		lexer.consume(nearestIndices[0])
 		token.TokenType = nearestType
-		token.Text = lexer.remainingText[nearestIndices[0]:nearestIndices[1]]
-		lexer.remainingText = lexer.remainingText[nearestIndices[1]:]
+		token.Text = lexer.remainingText[:nearestIndices[1]-nearestIndices[0]]
+		token.Line, token.Column = lexer.line, lexer.column
+		lexer.consume(len(token.Text))
 	}
 
 	return token
@@ -74,8 +89,21 @@ func (lexer *Lexer) consumeMultilineComment() {
 	index := strings.Index(lexer.remainingText, "*/")
 
 	if index != -1 {
-		lexer.remainingText = lexer.remainingText[index + 2:]
+		lexer.consume(index + 2)
+	} else {
+		lexer.consume(len(lexer.remainingText))
+	}
+}
+
+// Skipping the beginning of remaining text, moving position past it
+func (lexer *Lexer) consume(length int) {
+	consumed := lexer.remainingText[:length]
+	lexer.remainingText = lexer.remainingText[length:]
+
+	if index := strings.LastIndex(consumed, "\n"); index != -1 {
+		lexer.line += strings.Count(consumed, "\n")
+		lexer.column = len(consumed) - index
 	} else {
-		lexer.remainingText = ""
+		lexer.column += len(consumed)
 	}
 }
@@ -17,10 +17,10 @@ const (
 )
 
 var patterns = []Pair{
-	{Keyword,   "switch|case|default|var|for|break|continue|return|if|else"},
+	{Keyword,   "\\b(switch|case|default|var|for|break|continue|return|if|else|type|struct|map|import)\\b"},
 	{Comment,   "(//|/\\*).*"},
-	{Operator,  ":=|==|<=|>=|=|\\+\\+|--|\\+|-|\\*|/|%|>|<|!"},
-	{Delimiter, "[{}():;,]"},
+	{Operator,  ":=|==|!=|<=|>=|<<|>>|&&|&\\^|&|\\|\\||\\||\\^|=|\\+\\+|--|\\+|-|\\*|/|%|>|<|!"},
+	{Delimiter, "[{}():;,.\\[\\]]"},
     {Literal,   "\\d+[\\.exobEXOB]?\\d*|true|false|\"[^\"]*\"|'\\\\?.'"},
 	{Identifier,"[a-zA-Z_]\\w*"},
     {EndOfLine, "[\r\n]"},
@@ -5,4 +5,6 @@ type TokenType string
 type Token struct {
 	TokenType TokenType
 	Text      string
+	Line      int // Line and column of the first character, both start from 1
+	Column    int
 }
@@ -0,0 +1,267 @@
+package lwiqa
+
+// Program written in LWIQA, as it's produced by the generator:
+//   Z1 main
+//   Q1.1. PROCEDURE &main&
+//     Q1.1.1. &a&
+//     A1.1.1. 2
+//     Q1.1.2. ENDPROC &main&
+// Keywords are read in any case, identifiers are written with or without '&'.
+type Program struct {
+	Name         string // Name from Z header, empty if there's no header
+	Declarations []Declaration
+}
+
+// Line of the question, used in errors and traces
+type Line struct {
+	Number int    // Line in the text, starts from 1
+	Index  string // Index written after Q: 1.1.3.
+	Text   string // Question and its answer as they're written, without indentation
+}
+
+func (line Line) Source() Line {
+	return line
+}
+
+//------------------------------------------------------------------------------
+// Declarations
+type Declaration interface {
+	Source() Line
+}
+
+// Records are declared with their fields:
+//   Q1.1. RECORD &Point&
+//     Q1.1.1. &X& : INTEGER
+//     Q1.1.2. ENDREC &Point&
+// Other types are declared on a single line: TYPE &Celsius& = REAL
+type TypeDeclaration struct {
+	Line
+	Name string
+	Type Type // RecordType for records
+}
+
+type Procedure struct {
+	Line
+	Name       string
+	Recursive  bool
+	Parameters []Parameter // Output parameters are marked
+	Result     Type        // Nil if procedure returns nothing
+	Variables  []Parameter // Declared in VAR block
+	Locals     []string    // Listed in LOCAL line
+	Body       Statements
+}
+
+// Parameter of procedure, variable or field of record: &a& : INTEGER
+type Parameter struct {
+	Name   string
+	Type   Type
+	Output bool
+}
+
+// Procedure with the name, nil if there's no such procedure
+func (program *Program) Procedure(name string) *Procedure {
+	for _, declaration := range program.Declarations {
+		if procedure, status := declaration.(*Procedure); status && procedure.Name == name {
+			return procedure
+		}
+	}
+
+	return nil
+}
+
+// Declaration of the type with the name, nil if there's no such type
+func (program *Program) Type(name string) *TypeDeclaration {
+	for _, declaration := range program.Declarations {
+		if decl, status := declaration.(*TypeDeclaration); status && decl.Name == name {
+			return decl
+		}
+	}
+
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// Types
+type Type interface{}
+
+// INTEGER, REAL, STRING or BOOLEAN
+type BasicType string
+
+const (
+	Integer BasicType = "INTEGER"
+	Real    BasicType = "REAL"
+	String  BasicType = "STRING"
+	Boolean BasicType = "BOOLEAN"
+)
+
+// ARRAY[5] OF INTEGER, length of ARRAY[] OF INTEGER is -1
+type ArrayType struct {
+	Length  int
+	Element Type
+}
+
+// MAP[STRING] OF INTEGER
+type MapType struct {
+	Key   Type
+	Value Type
+}
+
+// Fields of records declared with RECORD lines, name is empty for RECORD(&X& : INTEGER)
+type RecordType struct {
+	Name   string
+	Fields []Parameter
+}
+
+// Type referenced by its name: &Point&
+type NamedType struct {
+	Name string
+}
+
+//------------------------------------------------------------------------------
+// Statements
+type Statement interface {
+	Source() Line
+}
+
+type Statements []Statement
+
+// Several targets are assigned at once: &a&, &b& := &b&, &a&.
+// Literal values are answers to the question with target:
+//   Q1.1.1. &a&
+//   A1.1.1. 2
+type AssignStatement struct {
+	Line
+	Targets Expressions
+	Values  Expressions
+	Answer  bool // Value is written as the answer
+}
+
+// Arrays and maps are declared with their types, initial elements are the answer:
+//   Q1.1.1. ARRAY &a&[3] OF INTEGER
+//   A1.1.1. [1, 2, 3]
+// Value is nil if there is no answer.
+type DeclareStatement struct {
+	Line
+	Target Identifier
+	Type   Type
+	Value  Expression
+}
+
+// Procedures and functions called for their effect: &divmod&(&a&, &b&, &q&, &r&)
+type CallStatement struct {
+	Line
+	Call CallExpression
+}
+
+// OUTPUT writes values the same way as fmt.Print, OUTPUTLN as fmt.Println
+type OutputStatement struct {
+	Line
+	Items   Expressions
+	Newline bool
+}
+
+// INPUT reads values the same way as fmt.Scan
+type InputStatement struct {
+	Line
+	Targets Expressions
+}
+
+// Value is nil if nothing is returned
+type ReturnStatement struct {
+	Line
+	Value Expression
+}
+
+// break or continue
+type BranchStatement struct {
+	Line
+	Keyword string
+}
+
+// Branches are checked in order, the last one has no condition if there's ELSE:
+//   Q1.1.1. IF &a& = 1 THEN BEGIN
+//     Q1.1.1.1. &b& := 1
+//     Q1.1.1.2. END ELSE IF &a& = 2 THEN BEGIN
+//     Q1.1.1.3. &b& := 2
+//     Q1.1.1.4. END ELSE BEGIN
+//     Q1.1.1.5. &b& := 3
+//     Q1.1.1.6. END
+type IfStatement struct {
+	Branches []Branch
+	End      Line
+}
+
+// Line of IF or ELSE which starts the branch
+type Branch struct {
+	Line
+	Condition Expression // Nil for ELSE
+	Body      Statements
+}
+
+func (stmt IfStatement) Source() Line {
+	return stmt.Branches[0].Line
+}
+
+//------------------------------------------------------------------------------
+// Expressions
+type Expression interface{}
+
+type Expressions []Expression
+
+// Identifiers written with '&' are marked, so they aren't taken for keywords
+type Identifier struct {
+	Name   string
+	Marked bool
+}
+
+// Value is int64, float64, string or bool
+type Literal struct {
+	Value interface{}
+}
+
+// Operators written as words are in upper case: -, NOT, BITNOT
+type UnaryExpression struct {
+	Operator string
+	Operand  Expression
+}
+
+// Operators written as words are in upper case: +, DIV, AND
+type BinaryExpression struct {
+	LeftOperand  Expression
+	Operator     string
+	RightOperand Expression
+}
+
+type IndexExpression struct {
+	Expression Expression
+	Index      Expression
+}
+
+// Field of record: &p&.&X&
+type SelectorExpression struct {
+	Expression Expression
+	Field      Identifier
+}
+
+// Calls of procedures, standard functions and conversions: LENGTH(&a&), REAL(&n&).
+// Records are created the same way: &Point&(1, 2), &Point&(&X&: 1)
+type CallExpression struct {
+	Function  Identifier
+	Arguments Expressions
+}
+
+// Field of record literal or element of map literal: &X&: 1
+type KeyValueExpression struct {
+	Key   Expression
+	Value Expression
+}
+
+// [1, 2, 3]
+type ArrayLiteral struct {
+	Elements Expressions
+}
+
+// {"a": 1, "b": 2}, elements are KeyValueExpression
+type MapLiteral struct {
+	Elements Expressions
+}
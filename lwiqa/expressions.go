@@ -0,0 +1,495 @@
+package lwiqa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tokens of a single line
+type stream struct {
+	tokens []token
+	pos    int
+	line   int
+}
+
+// Precedence of binary operators is the same as in Go, operators written as words are in upper case
+var binaryPrecedence = map[string]int{
+	"OR":  1,
+	"AND": 2,
+	"=":   3, "<>": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4, "BITOR": 4, "BITXOR": 4, "CONCAT": 4,
+	"*": 5, "/": 5, "DIV": 5, "MOD": 5, "SHL": 5, "SHR": 5, "BITAND": 5,
+}
+
+var unaryOperators = map[string]bool{
+	"+":      true,
+	"-":      true,
+	"NOT":    true,
+	"BITNOT": true,
+}
+
+var basicTypes = map[string]BasicType{
+	"INTEGER": Integer,
+	"REAL":    Real,
+	"STRING":  String,
+	"BOOLEAN": Boolean,
+}
+
+func (stream *stream) peek() token {
+	return stream.tokens[stream.pos]
+}
+
+func (stream *stream) next() token {
+	token := stream.tokens[stream.pos]
+
+	if token.kind != endToken {
+		stream.pos++
+	}
+
+	return token
+}
+
+func (stream *stream) isEnd() bool {
+	return stream.peek().kind == endToken
+}
+
+func (stream *stream) isSymbol(symbol string) bool {
+	return stream.peek().kind == symbolToken && stream.peek().text == symbol
+}
+
+// Current word in upper case, empty if it's not a word
+func (stream *stream) keyword() string {
+	if stream.peek().kind != wordToken {
+		return ""
+	}
+
+	return strings.ToUpper(stream.peek().text)
+}
+
+func (stream *stream) errorf(format string, args ...interface{}) *Error {
+	return &Error{stream.line, fmt.Sprintf(format, args...)}
+}
+
+func (stream *stream) expect(symbol string) error {
+	if !stream.isSymbol(symbol) {
+		return stream.errorf("'%s' expected, got '%s'", symbol, stream.peek().text)
+	}
+
+	stream.next()
+	return nil
+}
+
+func (stream *stream) expectKeyword(keyword string) error {
+	if stream.keyword() != keyword {
+		return stream.errorf("%s expected, got '%s'", keyword, stream.peek().text)
+	}
+
+	stream.next()
+	return nil
+}
+
+func (stream *stream) expectEnd() error {
+	if !stream.isEnd() {
+		return stream.errorf("unexpected '%s'", stream.peek().text)
+	}
+
+	return nil
+}
+
+// Name with or without '&': &Point&, Point
+func (stream *stream) parseIdentifier() (Identifier, error) {
+	token := stream.peek()
+
+	if token.kind != identToken && token.kind != wordToken {
+		return Identifier{}, stream.errorf("identifier expected, got '%s'", token.text)
+	}
+
+	stream.next()
+	return Identifier{token.text, token.kind == identToken}, nil
+}
+
+func (stream *stream) parseName() (string, error) {
+	ident, err := stream.parseIdentifier()
+	return ident.Name, err
+}
+
+// Names separated with commas: &a&, &b&
+func (stream *stream) parseNames() ([]string, error) {
+	var names []string
+
+	for {
+		name, err := stream.parseName()
+
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+
+		if !stream.isSymbol(",") {
+			return names, stream.expectEnd()
+		}
+
+		stream.next()
+	}
+}
+
+// [OUT] &a& : INTEGER
+func (stream *stream) parseParameter() (Parameter, error) {
+	var param Parameter
+
+	if stream.keyword() == "OUT" {
+		param.Output = true
+		stream.next()
+	}
+
+	name, err := stream.parseName()
+
+	if err == nil {
+		err = stream.expect(":")
+	}
+
+	if err != nil {
+		return param, err
+	}
+
+	param.Name = name
+	param.Type, err = stream.parseType()
+
+	return param, err
+}
+
+// Parameters separated by the separator until the closing symbol, which is skipped
+func (stream *stream) parseParameters(separator string, end string) ([]Parameter, error) {
+	var params []Parameter
+
+	for !stream.isSymbol(end) {
+		if len(params) > 0 {
+			if err := stream.expect(separator); err != nil {
+				return nil, err
+			}
+		}
+
+		param, err := stream.parseParameter()
+
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, param)
+	}
+
+	stream.next()
+	return params, nil
+}
+
+//   INTEGER, &Point&, ARRAY[5] OF INTEGER, MAP[STRING] OF REAL, RECORD(&X& : INTEGER; &Y& : INTEGER)
+func (stream *stream) parseType() (Type, error) {
+	if basic, status := basicTypes[stream.keyword()]; status {
+		stream.next()
+		return basic, nil
+	}
+
+	switch stream.keyword() {
+	case "ARRAY":
+		stream.next()
+
+		if err := stream.expect("["); err != nil {
+			return nil, err
+		}
+
+		return stream.parseArrayType()
+	case "MAP":
+		stream.next()
+
+		if err := stream.expect("["); err != nil {
+			return nil, err
+		}
+
+		return stream.parseMapType()
+	case "RECORD":
+		stream.next()
+
+		if err := stream.expect("("); err != nil {
+			return nil, err
+		}
+
+		fields, err := stream.parseParameters(";", ")")
+		return RecordType{"", fields}, err
+	}
+
+	name, err := stream.parseName()
+	return NamedType{name}, err
+}
+
+// Rest of array type after '[': 5] OF INTEGER
+func (stream *stream) parseArrayType() (Type, error) {
+	length := -1
+
+	if stream.peek().kind == numberToken {
+		n, err := strconv.Atoi(stream.next().text)
+
+		if err != nil {
+			return nil, stream.errorf("wrong length of array")
+		}
+
+		length = n
+	}
+
+	if err := stream.expect("]"); err != nil {
+		return nil, err
+	}
+
+	if err := stream.expectKeyword("OF"); err != nil {
+		return nil, err
+	}
+
+	element, err := stream.parseType()
+	return ArrayType{length, element}, err
+}
+
+// Rest of map type after '[': STRING] OF INTEGER
+func (stream *stream) parseMapType() (Type, error) {
+	key, err := stream.parseType()
+
+	if err == nil {
+		err = stream.expect("]")
+	}
+
+	if err == nil {
+		err = stream.expectKeyword("OF")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := stream.parseType()
+	return MapType{key, value}, err
+}
+
+// Expressions separated with commas
+func (stream *stream) parseExpressions() (Expressions, error) {
+	var exprs Expressions
+
+	for {
+		expr, err := stream.parseExpression()
+
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		if !stream.isSymbol(",") {
+			return exprs, nil
+		}
+
+		stream.next()
+	}
+}
+
+func (stream *stream) parseExpression() (Expression, error) {
+	return stream.parseBinaryExpression(1)
+}
+
+// Right operand takes only operators of higher precedence,
+// so operators of the same precedence are grouped from the left.
+func (stream *stream) parseBinaryExpression(precedence int) (Expression, error) {
+	left, err := stream.parseUnaryExpression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator := stream.operator()
+		operatorPrecedence, status := binaryPrecedence[operator]
+
+		if !status || operatorPrecedence < precedence {
+			return left, nil
+		}
+
+		stream.next()
+		right, err := stream.parseBinaryExpression(operatorPrecedence + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = BinaryExpression{left, operator, right}
+	}
+}
+
+// Current symbol or word in upper case
+func (stream *stream) operator() string {
+	if stream.peek().kind == symbolToken {
+		return stream.peek().text
+	}
+
+	return stream.keyword()
+}
+
+func (stream *stream) parseUnaryExpression() (Expression, error) {
+	if operator := stream.operator(); unaryOperators[operator] {
+		stream.next()
+		operand, err := stream.parseUnaryExpression()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return UnaryExpression{operator, operand}, nil
+	}
+
+	expr, err := stream.parsePrimaryExpression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields and elements: &s&.&To&.&Name&, &c&[1][2]
+	for {
+		if stream.isSymbol("[") {
+			stream.next()
+			index, err := stream.parseExpression()
+
+			if err == nil {
+				err = stream.expect("]")
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			expr = IndexExpression{expr, index}
+		} else if stream.isSymbol(".") {
+			stream.next()
+			field, err := stream.parseIdentifier()
+
+			if err != nil {
+				return nil, err
+			}
+
+			expr = SelectorExpression{expr, field}
+		} else {
+			return expr, nil
+		}
+	}
+}
+
+func (stream *stream) parsePrimaryExpression() (Expression, error) {
+	token := stream.next()
+
+	switch token.kind {
+	case numberToken:
+		return parseNumber(token.text, stream)
+	case stringToken:
+		value, err := strconv.Unquote(token.text)
+
+		if err != nil {
+			return nil, stream.errorf("wrong string %s", token.text)
+		}
+
+		return Literal{value}, nil
+	case identToken, wordToken:
+		if token.kind == wordToken {
+			switch strings.ToLower(token.text) {
+			case "true":
+				return Literal{true}, nil
+			case "false":
+				return Literal{false}, nil
+			}
+		}
+
+		ident := Identifier{token.text, token.kind == identToken}
+
+		if !stream.isSymbol("(") {
+			return ident, nil
+		}
+
+		stream.next()
+		args, err := stream.parseElements(")")
+
+		return CallExpression{ident, args}, err
+	case symbolToken:
+		switch token.text {
+		case "(":
+			expr, err := stream.parseExpression()
+
+			if err == nil {
+				err = stream.expect(")")
+			}
+
+			return expr, err
+		case "[":
+			elements, err := stream.parseElements("]")
+			return ArrayLiteral{elements}, err
+		case "{":
+			elements, err := stream.parseElements("}")
+			return MapLiteral{elements}, err
+		}
+	}
+
+	if token.kind == endToken {
+		return nil, stream.errorf("expression expected")
+	}
+
+	return nil, stream.errorf("unexpected '%s'", token.text)
+}
+
+// Arguments and elements of literals until the closing symbol, which is skipped.
+// Elements can have keys: &X&: 1, "a": 2
+func (stream *stream) parseElements(end string) (Expressions, error) {
+	elements := Expressions{}
+
+	for !stream.isSymbol(end) {
+		if len(elements) > 0 {
+			if err := stream.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		element, err := stream.parseExpression()
+
+		if err == nil && stream.isSymbol(":") {
+			stream.next()
+			var value Expression
+
+			if value, err = stream.parseExpression(); err == nil {
+				element = KeyValueExpression{element, value}
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, element)
+	}
+
+	stream.next()
+	return elements, nil
+}
+
+// Numbers with point or exponent are real: 2.5, 1e+06
+func parseNumber(text string, stream *stream) (Expression, error) {
+	if strings.ContainsAny(text, ".eE") {
+		value, err := strconv.ParseFloat(text, 64)
+
+		if err != nil {
+			return nil, stream.errorf("wrong number %s", text)
+		}
+
+		return Literal{value}, nil
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+
+	if err != nil {
+		return nil, stream.errorf("wrong number %s", text)
+	}
+
+	return Literal{value}, nil
+}
@@ -0,0 +1,151 @@
+package lwiqa
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	endToken    tokenKind = iota
+	wordToken             // Keywords, functions and identifiers without '&'
+	identToken            // Identifier with '&', text is the name without them
+	numberToken
+	stringToken // Text is the literal with quotes and escapes
+	symbolToken // Operators and delimiters: := <> (
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	start int // Offset in the text of the line
+}
+
+// Longer symbols go first, so := isn't read as :
+var symbols = []string{":=", "<>", "<=", ">=", "<", ">", "=", "+", "-", "*", "/",
+	"(", ")", "[", "]", "{", "}", ",", ":", ".", ";"}
+
+// Text of the question or answer is split into tokens, comment at the end is skipped:
+//   &a& := &b& * 2 // main.go:12
+// Tokens end with endToken.
+func tokenize(text string) ([]token, *Error) {
+	var tokens []token
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case strings.HasPrefix(text[i:], "//"):
+			return append(tokens, token{endToken, "", i}), nil
+		case r == '&':
+			end := strings.IndexByte(text[i+1:], '&')
+
+			if end <= 0 {
+				return nil, newError("identifier isn't closed with '&'")
+			}
+
+			tokens = append(tokens, token{identToken, text[i+1 : i+1+end], i})
+			i += end + 2
+		case r == '"':
+			end := stringEnd(text, i)
+
+			if end < 0 {
+				return nil, newError("string isn't closed")
+			}
+
+			tokens = append(tokens, token{stringToken, text[i:end], i})
+			i = end
+		case unicode.IsDigit(r):
+			end := numberEnd(text, i)
+			tokens = append(tokens, token{numberToken, text[i:end], i})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+
+			for end < len(text) {
+				r, size := utf8.DecodeRuneInString(text[end:])
+
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+
+				end += size
+			}
+
+			tokens = append(tokens, token{wordToken, text[i:end], i})
+			i = end
+		default:
+			symbol := ""
+
+			for _, s := range symbols {
+				if strings.HasPrefix(text[i:], s) {
+					symbol = s
+					break
+				}
+			}
+
+			if symbol == "" {
+				return nil, newError("unexpected character '" + string(r) + "'")
+			}
+
+			tokens = append(tokens, token{symbolToken, symbol, i})
+			i += len(symbol)
+		}
+	}
+
+	return append(tokens, token{endToken, "", len(text)}), nil
+}
+
+// Offset after the closing quote, -1 if the string isn't closed
+func stringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// Numbers are written the way Go prints them: 2, 2.5, 1e+06
+func numberEnd(text string, start int) int {
+	end := digitsEnd(text, start)
+
+	if end+1 < len(text) && text[end] == '.' && isDigit(text[end+1]) {
+		end = digitsEnd(text, end+1)
+	}
+
+	if end < len(text) && (text[end] == 'e' || text[end] == 'E') {
+		exponent := end + 1
+
+		if exponent < len(text) && (text[exponent] == '+' || text[exponent] == '-') {
+			exponent++
+		}
+
+		if exponent < len(text) && isDigit(text[exponent]) {
+			end = digitsEnd(text, exponent)
+		}
+	}
+
+	return end
+}
+
+func digitsEnd(text string, start int) int {
+	for start < len(text) && isDigit(text[start]) {
+		start++
+	}
+
+	return start
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,557 @@
+package lwiqa
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Error of LWIQA text with the number of the line it's found in
+type Error struct {
+	Line    int
+	Message string
+}
+
+func newError(message string) *Error {
+	return &Error{0, message}
+}
+
+func (err *Error) Error() string {
+	if err.Line == 0 {
+		return err.Message
+	}
+
+	return fmt.Sprintf("line %d: %s", err.Line, err.Message)
+}
+
+// Question or answer with its index: Q1.1.3. &a& := 2
+type line struct {
+	Line
+	question bool
+	text     string // Text after the index
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+var (
+	headerPattern = regexp.MustCompile(`^Z\d+\s+(\S+)$`)
+	linePattern   = regexp.MustCompile(`^([QA])((?:\d+\.)+)\s*(.*)$`)
+)
+
+// Program is read line by line, the first error stops reading
+func Parse(text string) (*Program, error) {
+	program := &Program{}
+	parser := &parser{}
+
+	for number, str := range strings.Split(text, "\n") {
+		str = strings.TrimSpace(str)
+
+		if str == "" || strings.HasPrefix(str, "//") {
+			continue
+		}
+
+		if match := headerPattern.FindStringSubmatch(str); match != nil && len(parser.lines) == 0 {
+			program.Name = match[1]
+			continue
+		}
+
+		match := linePattern.FindStringSubmatch(str)
+
+		if match == nil {
+			return nil, &Error{number + 1, "question or answer expected, got '" + str + "'"}
+		}
+
+		parser.lines = append(parser.lines, line{Line{number + 1, match[2], str}, match[1] == "Q", match[3]})
+	}
+
+	for parser.pos < len(parser.lines) {
+		declaration, err := parser.parseDeclaration()
+
+		if err != nil {
+			return nil, err
+		}
+
+		program.Declarations = append(program.Declarations, declaration)
+	}
+
+	return program, nil
+}
+
+// Next question, answers are read with the questions they belong to
+func (parser *parser) nextQuestion() (line, *stream, error) {
+	if parser.pos >= len(parser.lines) {
+		last := parser.lines[len(parser.lines)-1]
+		return line{}, nil, &Error{last.Number, "unexpected end of program"}
+	}
+
+	question := parser.lines[parser.pos]
+	parser.pos++
+
+	if !question.question {
+		return line{}, nil, &Error{question.Number, "answer without question"}
+	}
+
+	tokens, err := tokenize(question.text)
+
+	if err != nil {
+		err.Line = question.Number
+		return line{}, nil, err
+	}
+
+	return question, &stream{tokens, 0, question.Number}, nil
+}
+
+// Answer to the question, if there is one
+func (parser *parser) answer(question *line) (*stream, error) {
+	if parser.pos >= len(parser.lines) {
+		return nil, nil
+	}
+
+	answer := parser.lines[parser.pos]
+
+	if answer.question || answer.Index != question.Index {
+		return nil, nil
+	}
+
+	parser.pos++
+	question.Text += "\n" + answer.Text
+	tokens, err := tokenize(answer.text)
+
+	if err != nil {
+		err.Line = answer.Number
+		return nil, err
+	}
+
+	return &stream{tokens, 0, answer.Number}, nil
+}
+
+// First word of the next question in upper case, empty if it's not a word
+func (parser *parser) nextKeyword() string {
+	if parser.pos >= len(parser.lines) || !parser.lines[parser.pos].question {
+		return ""
+	}
+
+	tokens, err := tokenize(parser.lines[parser.pos].text)
+
+	if err != nil || tokens[0].kind != wordToken {
+		return ""
+	}
+
+	return strings.ToUpper(tokens[0].text)
+}
+
+func (parser *parser) parseDeclaration() (Declaration, error) {
+	question, stream, err := parser.nextQuestion()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch stream.keyword() {
+	case "PROCEDURE", "RECURSIVE":
+		return parser.parseProcedure(question, stream)
+	case "RECORD":
+		stream.next()
+		name, err := stream.parseName()
+
+		if err != nil {
+			return nil, err
+		}
+
+		fields, err := parser.parseParameterLines("ENDREC")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &TypeDeclaration{question.Line, name, RecordType{name, fields}}, nil
+	case "TYPE":
+		stream.next()
+		name, err := stream.parseName()
+
+		if err == nil {
+			err = stream.expect("=")
+		}
+
+		var declType Type
+
+		if err == nil {
+			declType, err = stream.parseType()
+		}
+
+		if err == nil {
+			err = stream.expectEnd()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &TypeDeclaration{question.Line, name, declType}, nil
+	}
+
+	return nil, stream.errorf("PROCEDURE, RECORD or TYPE expected, got '%s'", stream.peek().text)
+}
+
+// Procedure header is followed by VAR and LOCAL lines, then the body ends with ENDPROC:
+//   Q1.2. RECURSIVE PROCEDURE &fact&(&n& : INTEGER) : INTEGER
+//   Q1.2.1. VAR ... ENDVAR
+//   Q1.2.2. LOCAL &r&
+//   ...
+//   Q1.2.6. ENDPROC &fact&
+func (parser *parser) parseProcedure(question line, stream *stream) (*Procedure, error) {
+	procedure := &Procedure{Line: question.Line}
+
+	if stream.keyword() == "RECURSIVE" {
+		procedure.Recursive = true
+		stream.next()
+	}
+
+	if err := stream.expectKeyword("PROCEDURE"); err != nil {
+		return nil, err
+	}
+
+	name, err := stream.parseName()
+
+	if err != nil {
+		return nil, err
+	}
+
+	procedure.Name = name
+
+	if stream.isSymbol("(") {
+		stream.next()
+
+		if procedure.Parameters, err = stream.parseParameters(",", ")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if stream.isSymbol(":") {
+		stream.next()
+
+		if procedure.Result, err = stream.parseType(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := stream.expectEnd(); err != nil {
+		return nil, err
+	}
+
+	for {
+		if keyword := parser.nextKeyword(); keyword == "VAR" {
+			parser.pos++
+
+			if procedure.Variables, err = parser.parseParameterLines("ENDVAR"); err != nil {
+				return nil, err
+			}
+		} else if keyword == "LOCAL" {
+			_, stream, _ := parser.nextQuestion()
+			stream.next()
+
+			if procedure.Locals, err = stream.parseNames(); err != nil {
+				return nil, err
+			}
+		} else {
+			break
+		}
+	}
+
+	if procedure.Body, err = parser.parseStatements(); err != nil {
+		return nil, err
+	}
+
+	_, stream, err = parser.nextQuestion()
+
+	if err == nil {
+		err = stream.expectKeyword("ENDPROC")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return procedure, nil
+}
+
+// Lines of VAR and RECORD are parameters until the line with the keyword: &X& : INTEGER
+func (parser *parser) parseParameterLines(end string) ([]Parameter, error) {
+	var params []Parameter
+
+	for {
+		_, stream, err := parser.nextQuestion()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if stream.keyword() == end {
+			return params, nil
+		}
+
+		param, err := stream.parseParameter()
+
+		if err == nil {
+			err = stream.expectEnd()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, param)
+	}
+}
+
+// Statements of the block end before END or ENDPROC
+func (parser *parser) parseStatements() (Statements, error) {
+	stmts := Statements{}
+
+	for {
+		if keyword := parser.nextKeyword(); keyword == "END" || keyword == "ENDPROC" {
+			return stmts, nil
+		}
+
+		stmt, err := parser.parseStatement()
+
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, stmt)
+	}
+}
+
+func (parser *parser) parseStatement() (Statement, error) {
+	question, stream, err := parser.nextQuestion()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stmt Statement
+
+	switch stream.keyword() {
+	case "IF":
+		return parser.parseIfStatement(question, stream)
+	case "ARRAY", "MAP":
+		return parser.parseDeclareStatement(question, stream)
+	case "RETURN":
+		stream.next()
+		var value Expression
+
+		if !stream.isEnd() {
+			value, err = stream.parseExpression()
+		}
+
+		stmt = ReturnStatement{question.Line, value}
+	case "OUTPUT", "OUTPUTLN":
+		newline := stream.keyword() == "OUTPUTLN"
+		stream.next()
+		var items Expressions
+
+		if !stream.isEnd() {
+			items, err = stream.parseExpressions()
+		}
+
+		stmt = OutputStatement{question.Line, items, newline}
+	case "INPUT":
+		stream.next()
+		var targets Expressions
+		targets, err = stream.parseExpressions()
+		stmt = InputStatement{question.Line, targets}
+	case "BREAK", "CONTINUE":
+		stmt = BranchStatement{question.Line, strings.ToLower(stream.next().text)}
+	default:
+		return parser.parseSimpleStatement(question, stream)
+	}
+
+	if err == nil {
+		err = stream.expectEnd()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+// Assignment, value given as the answer or call:
+//   Q1.1.1. &a&, &b& := &b&, &a&
+//   Q1.1.2. &c&
+//   A1.1.2. 3
+//   Q1.1.3. &divmod&(&a&, &b&, &q&, &r&)
+func (parser *parser) parseSimpleStatement(question line, stream *stream) (Statement, error) {
+	exprs, err := stream.parseExpressions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if stream.isSymbol(":=") {
+		stream.next()
+		values, err := stream.parseExpressions()
+
+		if err == nil {
+			err = stream.expectEnd()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return AssignStatement{question.Line, exprs, values, false}, nil
+	}
+
+	if err := stream.expectEnd(); err != nil {
+		return nil, err
+	}
+
+	answer, err := parser.answer(&question)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if answer != nil && len(exprs) == 1 {
+		value, err := answer.parseExpression()
+
+		if err == nil {
+			err = answer.expectEnd()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return AssignStatement{question.Line, exprs, Expressions{value}, true}, nil
+	}
+
+	if call, status := exprs[0].(CallExpression); status && len(exprs) == 1 && answer == nil {
+		return CallStatement{question.Line, call}, nil
+	}
+
+	return nil, &Error{question.Number, "statement expected, got '" + question.text + "'"}
+}
+
+//   Q1.1.1. ARRAY &a&[5] OF INTEGER
+//   Q1.1.2. MAP &m&[STRING] OF INTEGER
+//   A1.1.2. {"a": 1}
+func (parser *parser) parseDeclareStatement(question line, stream *stream) (Statement, error) {
+	isArray := stream.keyword() == "ARRAY"
+	stream.next()
+	target, err := stream.parseIdentifier()
+
+	if err == nil {
+		err = stream.expect("[")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var declType Type
+
+	if isArray {
+		declType, err = stream.parseArrayType()
+	} else {
+		declType, err = stream.parseMapType()
+	}
+
+	if err == nil {
+		err = stream.expectEnd()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := parser.answer(&question)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var value Expression
+
+	if answer != nil {
+		value, err = answer.parseExpression()
+
+		if err == nil {
+			err = answer.expectEnd()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return DeclareStatement{question.Line, target, declType, value}, nil
+}
+
+// Branches are started by IF, END ELSE IF and END ELSE lines, the last one is END
+func (parser *parser) parseIfStatement(question line, stream *stream) (Statement, error) {
+	var stmt IfStatement
+	isElse := false
+
+	for {
+		var condition Expression
+		var err error
+
+		if !isElse {
+			stream.next()
+
+			if condition, err = stream.parseExpression(); err == nil {
+				err = stream.expectKeyword("THEN")
+			}
+		}
+
+		if err == nil {
+			err = stream.expectKeyword("BEGIN")
+		}
+
+		if err == nil {
+			err = stream.expectEnd()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := parser.parseStatements()
+
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.Branches = append(stmt.Branches, Branch{question.Line, condition, body})
+
+		if question, stream, err = parser.nextQuestion(); err != nil {
+			return nil, err
+		}
+
+		if err := stream.expectKeyword("END"); err != nil {
+			return nil, err
+		}
+
+		if stream.isEnd() {
+			stmt.End = question.Line
+			return stmt, nil
+		}
+
+		if isElse {
+			return nil, stream.errorf("END expected after ELSE")
+		}
+
+		if err := stream.expectKeyword("ELSE"); err != nil {
+			return nil, err
+		}
+
+		isElse = stream.keyword() != "IF"
+	}
+}
@@ -1,19 +1,334 @@
 package main
 
 import (
+	"./cfg"
+	"./difftest"
+	"./evaluator"
+	"./interpreter"
+	"./lwiqa"
+	"./printer"
+	"./reverse"
 	"./translator"
+	"bufio"
+	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 func main() {
-	code, err := ioutil.ReadFile("test5.notgo")
+	var options translator.Options
+
+	backend := flag.String("backend", "lwiqa", "target of translation: lwiqa or pseudo")
+	sourceMap := flag.String("sourcemap", "", "write source map as JSON to this file")
+	start := flag.String("start", "1.1", "index of the first declaration")
+	flag.BoolVar(&options.Generator.SourceComments, "comments", false, "write source lines as comments after statements")
+	flag.IntVar(&options.Generator.IndentWidth, "indent", 0, "spaces for each level of nesting, tabs if 0")
+	flag.BoolVar(&options.Generator.OmitHeader, "no-header", false, "don't write program header: Z1 main")
+	flag.BoolVar(&options.Generator.LowercaseKeywords, "lowercase", false, "write keywords in lower case")
+	flag.BoolVar(&options.Generator.PlainIdentifiers, "plain", false, "write identifiers without '&'")
+	flag.BoolVar(&options.NoOptimization, "no-optimize", false, "keep constant expressions and dead branches")
+	flag.StringVar(&options.Frontend, "frontend", "native", "front end which reads the code: "+
+		strings.Join(translator.Frontends(), " or ")+", go uses go/parser and go/types")
+	diagrams := flag.String("cfg", "", "write control-flow graph of each function instead of translation: "+
+		strings.Join(cfg.Formats(), " or "))
+	diagramDir := flag.String("cfg-dir", ".", "directory for control-flow graphs")
+	run := flag.Bool("run", false, "run translated code with standard input and output instead of writing it, "+
+		"files with .lwiqa extension are run as they are")
+	eval := flag.Bool("eval", false, "run the code itself with standard input and output instead of translating it")
+	diffTest := flag.Bool("difftest", false, "compare running the code with running its LWIQA translation, "+
+		"fixtures of the repository are compared if no file is given")
+	var inputs inputList
+	flag.Var(&inputs, "input", "input for -difftest, can be repeated")
+	trace := flag.Bool("trace", false, "write executed lines and assigned values to standard error with -run")
+	format := flag.Bool("fmt", false, "write the code formatted the same way as gofmt does it, code with comments isn't formatted")
+	reverseLWIQA := flag.Bool("reverse", false, "translate LWIQA code back to Go, diagnostics are written to standard error")
+	flag.Parse()
+
+	file := "test5.notgo"
+
+	if flag.NArg() > 0 {
+		file = flag.Arg(0)
+	}
+
+	startIndex, err := parseIndex(*start)
+	if err != nil || options.Generator.IndentWidth < 0 {
+		fmt.Println("Wrong options")
+		return
+	}
+
+	if *diffTest {
+		options.Generator.StartIndex = startIndex
+		compareRuns(flag.Args(), inputs, options)
+		return
+	}
+
+	code, err := ioutil.ReadFile(file)
 	if err != nil {
 		fmt.Println("Could not open file")
 		return
 	}
 
-	genCode := translator.Translate(string(code))
+	if *diagrams != "" {
+		writeDiagrams(string(code), *diagrams, *diagramDir, options)
+		return
+	}
+
+	options.Generator.StartIndex = startIndex
+	options.Generator.SourceMap = *sourceMap != "" || options.Generator.SourceComments
+	options.Generator.SourceFile = filepath.Base(file)
+
+	if *format {
+		formatProgram(string(code))
+		return
+	}
+
+	if *reverseLWIQA {
+		reverseProgram(string(code))
+		return
+	}
+
+	if *eval {
+		evaluateProgram(string(code), options)
+		return
+	}
+
+	if *run {
+		runProgram(string(code), filepath.Ext(file) == ".lwiqa", *trace, options)
+		return
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	sm, err := translator.TranslateTo(out, string(code), *backend, options)
+
+	if err == nil {
+		err = out.WriteByte('\n')
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not write generated code")
+		return
+	}
+
+	if *sourceMap == "" || sm == nil {
+		return
+	}
+
+	data, err := sm.JSON()
+
+	if err == nil {
+		err = ioutil.WriteFile(*sourceMap, append(data, '\n'), 0644)
+	}
+
+	if err != nil {
+		fmt.Println("Could not write source map")
+	}
+}
+
+// Each function gets its own file named after it: max.dot, max.mmd.
+// Names of the written files are printed.
+func writeDiagrams(code string, formatName string, dir string, options translator.Options) {
+	format, status := cfg.GetFormat(formatName)
+
+	if !status {
+		fmt.Println("Unknown format '" + formatName + "', available: " + strings.Join(cfg.Formats(), ", "))
+		return
+	}
+
+	graphs, errors := translator.ControlFlowGraphs(code, options)
+
+	if errors != "" {
+		fmt.Print(errors)
+		return
+	}
+
+	for _, graph := range graphs {
+		file := filepath.Join(dir, graph.Name+"."+format.Extension)
+
+		if err := ioutil.WriteFile(file, []byte(format.Export(graph)), 0644); err != nil {
+			fmt.Println("Could not write " + file)
+			return
+		}
+
+		fmt.Println(file)
+	}
+}
+
+// Values of the flag which can be repeated: -input "1 2" -input "3 4"
+type inputList []string
+
+func (list *inputList) String() string {
+	return strings.Join(*list, ", ")
+}
+
+func (list *inputList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+// Each run is written with its differences, program exits with status 1 if there are any:
+//   test10.notgo, input "3 2.5": ok
+func compareRuns(files []string, inputs []string, options translator.Options) {
+	fixtures := difftest.Fixtures
+
+	if len(files) > 0 {
+		fixtures = nil
+
+		for _, file := range files {
+			fixtures = append(fixtures, difftest.Fixture{File: file, Inputs: inputs})
+		}
+	}
+
+	runs, failed := 0, 0
+
+	for _, fixture := range fixtures {
+		code, err := ioutil.ReadFile(fixture.File)
+
+		if err != nil {
+			fmt.Println("Could not open file " + fixture.File)
+			os.Exit(1)
+		}
+
+		results, errors := difftest.CompareFixture(fixture, string(code), options)
+
+		if errors != "" {
+			fmt.Println(fixture.File + ": not compared, the code has errors")
+			continue
+		}
+
+		for _, result := range results {
+			runs++
+			status := "ok"
+
+			if len(result.Differences) > 0 {
+				failed++
+				status = "differs"
+			}
+
+			fmt.Printf("%s, input %q: %s\n", fixture.File, result.Input, status)
+
+			for _, difference := range result.Differences {
+				fmt.Println("\t" + difference)
+			}
+		}
+	}
+
+	fmt.Printf("%d runs, %d differ\n", runs, failed)
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// Code is run by the reference evaluator, the same way as it would be run by Go
+func evaluateProgram(code string, options translator.Options) {
+	ast, errors := translator.SyntaxTree(code, options)
+
+	if errors != "" {
+		fmt.Print(errors)
+		return
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	_, err := evaluator.NewEvaluator(ast, os.Stdin, out, evaluator.Options{}).Run()
+
+	if flushErr := out.Flush(); flushErr != nil {
+		fmt.Fprintln(os.Stderr, "Could not write output")
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Runtime error: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+// Output of the program is flushed even if it stops with error, the error is written after it
+func runProgram(code string, isLWIQA bool, trace bool, options translator.Options) {
+	if !isLWIQA {
+		var errors string
+
+		if code, errors = translator.LWIQAProgram(code, options); errors != "" {
+			fmt.Print(errors)
+			return
+		}
+	}
+
+	program, err := lwiqa.Parse(code)
+
+	if err != nil {
+		fmt.Println("Syntax error: " + err.Error())
+		return
+	}
+
+	var runOptions interpreter.Options
+
+	if trace {
+		runOptions.Trace = os.Stderr
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	_, err = interpreter.NewInterpreter(program, os.Stdin, out, runOptions).Run()
+
+	if flushErr := out.Flush(); flushErr != nil {
+		fmt.Fprintln(os.Stderr, "Could not write output")
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Runtime error: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+// Program exits with status 1 if the code can't be formatted
+func formatProgram(code string) {
+	formatted, errors := translator.Format(code)
+	fmt.Print(formatted)
+
+	if errors != "" {
+		fmt.Fprint(os.Stderr, errors)
+		os.Exit(1)
+	}
+}
+
+// Constructs which can't be written in Go the same way are reported after the code
+func reverseProgram(code string) {
+	file, diagnostics, err := reverse.Translate(code)
+
+	if err != nil {
+		fmt.Println("Syntax error: " + err.Error())
+		return
+	}
+
+	goCode, err := printer.File(file)
+	fmt.Print(goCode)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not format Go code: "+err.Error())
+	}
+
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(os.Stderr, diagnostic.String())
+	}
+}
+
+// Index is written with dots: 1.1 or 1.1.
+func parseIndex(str string) ([]int, error) {
+	var index []int
+
+	for _, part := range strings.Split(strings.TrimSuffix(str, "."), ".") {
+		i, err := strconv.Atoi(part)
+
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("wrong index '%s'", str)
+		}
+
+		index = append(index, i)
+	}
 
-	fmt.Println(genCode)
+	return index, nil
 }
@@ -0,0 +1,92 @@
+package optimizer
+
+import (
+	"../parser"
+	"../semantic"
+	"go/constant"
+	"strconv"
+)
+
+// Operations with literals are replaced with their results: 1 * 5 / 10 <= 2 / 45 -> true.
+// Results are computed the same way Go computes constants, so 1 << 70 >> 68 is 4.
+// Results which don't fit into literals and operations which are errors, like division by zero, are kept.
+func foldExpression(expression parser.Expression) parser.Expression {
+	switch expression.(type) {
+	case parser.UnaryExpression, parser.BinaryExpression:
+		if value := semantic.ConstantValue(expression); value != nil {
+			if lit, status := literal(value); status {
+				lit.Position = parser.ExpressionPosition(expression)
+				return lit
+			}
+		}
+	}
+
+	if expr, status := expression.(parser.UnaryExpression); status && expr.Operand != nil {
+		expr.Operand = foldExpression(expr.Operand)
+		return expr
+	} else if expr, status := expression.(parser.BinaryExpression); status {
+		expr.LeftOperand = foldExpression(expr.LeftOperand)
+		expr.RightOperand = foldExpression(expr.RightOperand)
+		return expr
+	} else if expr, status := expression.(parser.IndexExpression); status {
+		expr.Expression = foldExpression(expr.Expression)
+		expr.Index = foldExpression(expr.Index)
+		return expr
+	} else if call, status := expression.(parser.CallExpression); status {
+		call.Arguments = foldExpressions(call.Arguments)
+		return call
+	} else if expr, status := expression.(parser.SelectorExpression); status {
+		expr.Expression = foldExpression(expr.Expression)
+		return expr
+	} else if lit, status := expression.(parser.CompositeLiteral); status {
+		lit.Elements = foldExpressions(lit.Elements)
+		return lit
+	} else if expr, status := expression.(parser.KeyValueExpression); status {
+		expr.Key = foldExpression(expr.Key)
+		expr.Value = foldExpression(expr.Value)
+		return expr
+	}
+
+	return expression
+}
+
+func foldExpressions(expressions parser.Expressions) parser.Expressions {
+	if expressions == nil {
+		return nil
+	}
+
+	folded := parser.Expressions{}
+
+	for _, expr := range expressions {
+		folded = append(folded, foldExpression(expr))
+	}
+
+	return folded
+}
+
+// Literal with the value of the constant, integers should fit into 64 bits.
+// Strings are written with escapes, the same way they're in the code.
+func literal(value constant.Value) (parser.Literal, bool) {
+	switch value.Kind() {
+	case constant.Int:
+		if i, exact := constant.Int64Val(value); exact {
+			return parser.Literal{Type: parser.IntegerLiteral, Value: i}, true
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return parser.Literal{Type: parser.FloatLiteral, Value: f}, true
+	case constant.String:
+		quoted := strconv.Quote(constant.StringVal(value))
+		return parser.Literal{Type: parser.StringLiteral, Value: quoted[1 : len(quoted)-1]}, true
+	case constant.Bool:
+		return parser.Literal{Type: parser.BooleanLiteral, Value: constant.BoolVal(value)}, true
+	}
+
+	return parser.Literal{}, false
+}
+
+// Literal is the same constant as another one: case 2 of switch 2
+func isEqual(x parser.Literal, y parser.Literal) bool {
+	value := semantic.ConstantValue(parser.BinaryExpression{LeftOperand: x, Operator: parser.GetType(parser.Eq), RightOperand: y})
+	return value != nil && constant.BoolVal(value)
+}
@@ -0,0 +1,250 @@
+package optimizer
+
+import (
+	"../parser"
+	"../semantic"
+)
+
+// Optimizer simplifies analyzed code before generation:
+//   - operations with literals are replaced with their results: 2 * 3 -> 6
+//   - branches of if with constant condition which can't be taken are removed
+//   - switch with constant tag and cases is replaced with the body of the matching case
+//   - statements after return are removed
+// Branches which are always taken stay blocks, so their variables keep their scopes.
+// Code should be free of errors, since it's analyzed before.
+func Optimize(tree parser.File) parser.File {
+	declarations := parser.Declarations{}
+
+	for _, declaration := range tree.Declarations {
+		if decl, status := declaration.(parser.FuncDeclaration); status {
+			decl.Body = optimizeBlock(decl.Body)
+			declaration = decl
+		}
+
+		declarations = append(declarations, declaration)
+	}
+
+	tree.Declarations = declarations
+
+	return tree
+}
+
+// Block without statements has nil statements, like a missing else
+func optimizeBlock(block parser.BlockStatement) parser.BlockStatement {
+	if block.Statements = optimizeStatements(block.Statements); len(block.Statements) == 0 {
+		block.Statements = nil
+	}
+
+	return block
+}
+
+// Nil if block is empty
+func takenBlock(block parser.BlockStatement) parser.Statement {
+	if block.Statements == nil {
+		return nil
+	}
+
+	return block
+}
+
+// Statements after the one which always returns are never executed
+func optimizeStatements(stmts parser.Statements) parser.Statements {
+	optimized := parser.Statements{}
+
+	for _, stmt := range stmts {
+		stmt = optimizeStatement(stmt)
+
+		if stmt == nil {
+			continue
+		}
+
+		optimized = append(optimized, stmt)
+
+		if semantic.IsTerminating(stmt) {
+			break
+		}
+	}
+
+	return optimized
+}
+
+// Nil if statement is removed
+func optimizeStatement(statement parser.Statement) parser.Statement {
+	if stmt, status := statement.(parser.AssignStatement); status {               // Assign
+		stmt.Targets = foldExpressions(stmt.Targets)
+
+		if values, status := stmt.Expression.(parser.Expressions); status {
+			stmt.Expression = foldExpressions(values)
+		} else {
+			stmt.Expression = foldExpression(stmt.Expression)
+		}
+
+		return stmt
+	} else if stmt, status := statement.(parser.VarStatement); status {         // Var
+		if stmt.Expression != nil {
+			stmt.Expression = foldExpression(stmt.Expression)
+		}
+
+		return stmt
+	} else if stmt, status := statement.(parser.ExpressionStatement); status {  // Call
+		stmt.Expression = foldExpression(stmt.Expression)
+		return stmt
+	} else if stmt, status := statement.(parser.ReturnStatement); status {      // Return
+		stmt.Results = foldExpressions(stmt.Results)
+		return stmt
+	} else if stmt, status := statement.(parser.BlockStatement); status {       // Block
+		return optimizeBlock(stmt)
+	} else if stmt, status := statement.(parser.IfStatement); status {          // If
+		return optimizeIfStatement(stmt)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {      // Switch
+		return optimizeSwitchStatement(stmt)
+	}
+
+	return statement
+}
+
+// If with constant condition becomes the block which is taken:
+//   if 1 < 2 { a = 1 } else { a = 2 }  ->  { a = 1 }
+// If without else which is never taken is removed.
+func optimizeIfStatement(stmt parser.IfStatement) parser.Statement {
+	stmt.Condition = foldExpression(stmt.Condition)
+	stmt.IfBody = optimizeBlock(stmt.IfBody)
+	stmt.ElseBody = optimizeBlock(stmt.ElseBody)
+
+	lit, status := stmt.Condition.(parser.Literal)
+
+	if !status {
+		return stmt
+	}
+
+	if lit.Value.(bool) {
+		return takenBlock(stmt.IfBody)
+	}
+
+	return takenBlock(stmt.ElseBody)
+}
+
+// Cases which can't match constant tag are removed. If the case which matches is known,
+// switch becomes its body:
+//   switch 2 { case 1: a = 1; case 2: a = 2 }  ->  { a = 2 }
+// Switch without tag is the same as switch true.
+// Switch with variable tag and default case only becomes the body of default,
+// call in the tag is left before it: switch f() { default: a = 1 }  ->  { f(); { a = 1 } }
+// Switch isn't replaced if the case has break, it would leave the enclosing block.
+func optimizeSwitchStatement(stmt parser.SwitchStatement) parser.Statement {
+	cases := parser.CaseStatements{}
+	defaultIndex := -1
+	tag, isConstant := parser.Literal{Type: parser.BooleanLiteral, Value: true}, true
+
+	if !isExpressionNil(stmt.Expression) {
+		stmt.Expression = foldExpression(stmt.Expression)
+		tag, isConstant = stmt.Expression.(parser.Literal)
+	}
+
+	isConstantTag, isMatched := isConstant, false
+
+	for _, caseStmt := range stmt.Body {
+		caseStmt.Body = optimizeBlock(caseStmt.Body)
+
+		if isExpressionNil(caseStmt.Expression) {
+			defaultIndex = len(cases)
+			cases = append(cases, caseStmt)
+			continue
+		}
+
+		caseStmt.Expression = foldExpression(caseStmt.Expression)
+		lit, status := caseStmt.Expression.(parser.Literal)
+
+		if isConstantTag && status && !isEqual(tag, lit) {
+			continue
+		}
+
+		cases = append(cases, caseStmt)
+
+		// Case which isn't constant may match before the constant one which does
+		if !status && !isMatched {
+			isConstant = false
+		}
+
+		isMatched = isMatched || status
+	}
+
+	stmt.Body = cases
+
+	// The first case which isn't removed matches, default is taken if there's no such case
+	matching := defaultIndex
+
+	for i, caseStmt := range cases {
+		if !isExpressionNil(caseStmt.Expression) {
+			matching = i
+			break
+		}
+	}
+
+	// Switch with default only always takes it, tag is still evaluated if it's a call
+	_, isCall := stmt.Expression.(parser.CallExpression)
+
+	if !isConstant && (matching != defaultIndex || !isSimpleExpression(stmt.Expression) && !isCall) {
+		return stmt
+	}
+
+	if matching != -1 && hasBranch(cases[matching].Body) {
+		return stmt
+	}
+
+	var taken parser.Statement
+
+	if matching != -1 {
+		taken = takenBlock(cases[matching].Body)
+	}
+
+	if !isCall {
+		return taken
+	}
+
+	call := parser.Statements{parser.ExpressionStatement{Expression: stmt.Expression, Position: stmt.Position}}
+
+	if taken != nil {
+		call = append(call, taken)
+	}
+
+	return parser.BlockStatement{Statements: call}
+}
+
+// Break or continue which isn't inside a nested switch
+func hasBranch(statement parser.Statement) bool {
+	if _, status := statement.(parser.BranchStatement); status {
+		return true
+	} else if stmt, status := statement.(parser.BlockStatement); status {
+		return hasBranch(stmt.Statements)
+	} else if stmts, status := statement.(parser.Statements); status {
+		for _, stmt := range stmts {
+			if hasBranch(stmt) {
+				return true
+			}
+		}
+	} else if stmt, status := statement.(parser.IfStatement); status {
+		return hasBranch(stmt.IfBody) || hasBranch(stmt.ElseBody)
+	}
+
+	return false
+}
+
+// Variables and literals can be evaluated any number of times, even zero
+func isSimpleExpression(expression parser.Expression) bool {
+	switch expression.(type) {
+	case parser.Identifier, parser.Literal:
+		return true
+	}
+
+	return isExpressionNil(expression)
+}
+
+// Default case has no expression
+func isExpressionNil(expression parser.Expression) bool {
+	if expr, status := expression.(parser.UnaryExpression); status {
+		return expr.Operand == nil
+	}
+
+	return false
+}
@@ -7,14 +7,57 @@ type Ast interface {
 	String() string
 }
 
+// Line and column in the source where statement or declaration begins.
+// Expressions have positions of their own tokens, so each of them has a different one:
+// operator, '(' of call, '[' of index, '.' of selector and '{' of composite literal.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (i Position) String() string {
+	return fmt.Sprintf("%d:%d", i.Line, i.Column)
+}
+
+// Zero position if expression wasn't parsed from the source
+func ExpressionPosition(expression Expression) Position {
+	switch expr := expression.(type) {
+	case Identifier:
+		return expr.Position
+	case Literal:
+		return expr.Position
+	case UnaryExpression:
+		return expr.Position
+	case BinaryExpression:
+		return expr.Position
+	case IndexExpression:
+		return expr.Position
+	case CallExpression:
+		return expr.Position
+	case SelectorExpression:
+		return expr.Position
+	case CompositeLiteral:
+		return expr.Position
+	}
+
+	return Position{}
+}
+
 type Package struct {
 	Name string
 }
 
+type ImportSpec struct {
+	Path string
+}
+
+type Imports []ImportSpec
+
 type Declarations []Declaration
 
 type File struct {
 	Package      Package
+	Imports      Imports
 	Declarations Declarations
 	Errors       Errors
 }
@@ -23,6 +66,20 @@ func (i Package) String() string {
 	return fmt.Sprintf("  Name: '%s'", i.Name)
 }
 
+func (i ImportSpec) String() string {
+	return fmt.Sprintf("  Path: '%s'", i.Path)
+}
+
+func (i Imports) String() string {
+	var str string
+
+	for _, item := range i {
+		str += item.String() + "\n"
+	}
+
+	return str
+}
+
 func (i Declarations) String() string {
 	var str string
 
@@ -38,8 +95,8 @@ func (i Declarations) String() string {
 }
 
 func (i File) String() string {
-	return fmt.Sprintf("Package:\n%s\nDeclarations:\n%s\nErrors:\n%s\n",
-		i.Package.String(), i.Declarations.String(), i.Errors.String())
+	return fmt.Sprintf("Package:\n%s\nImports:\n%s\nDeclarations:\n%s\nErrors:\n%s\n",
+		i.Package.String(), i.Imports.String(), i.Declarations.String(), i.Errors.String())
 }
 
 //-----------------------------------------------------------------------------
@@ -49,17 +106,22 @@ type Declaration interface {
 }
 
 type Identifier struct {
-	Name string
+	Name     string
+	Position Position
 }
 
 type Literal struct {
-	Type  TokenType
-	Value interface{}
+	Type     TokenType
+	Value    interface{}
+	Position Position
 }
 
 type FuncDeclaration struct {
-	Name Identifier
-	Body BlockStatement
+	Name       Identifier
+	Parameters []Field
+	Results    []Field // Names of unnamed results are empty
+	Body       BlockStatement
+	Position   Position
 }
 
 func (i Identifier) String() string {
@@ -85,9 +147,35 @@ func (i Literal) String() string {
 		litType, i.Value)
 }
 
+// Type declaration looks like: type Point struct { X, Y int }
+// Alias declaration has '=' after the name: type Number = int
+type TypeDeclaration struct {
+	Name     Identifier
+	Type     Expression
+	Alias    bool
+	Position Position
+}
+
+func (i TypeDeclaration) String() string {
+	return fmt.Sprintf("\nType declaration\n  Name: %s  Alias: %t\n  Type: %s",
+		i.Name.String(), i.Alias, i.Type.String())
+}
+
 func (i FuncDeclaration) String() string {
-	return fmt.Sprintf("\nFunction declaration\n  Type: %s\n  Value: %s",
-		i.Name.String(), i.Body.String())
+	var params string
+
+	for _, param := range i.Parameters {
+		params += param.String()
+	}
+
+	var results string
+
+	for _, result := range i.Results {
+		results += result.String()
+	}
+
+	return fmt.Sprintf("\nFunction declaration\n  Type: %s\n  Parameters: %s\n  Results: %s\n  Value: %s",
+		i.Name.String(), params, results, i.Body.String())
 }
 
 //------------------------------------------------------------------------------
@@ -99,12 +187,76 @@ type Expression interface {
 type UnaryExpression struct {
 	Operator string
 	Operand  Expression
+	Position Position
 }
 
 type BinaryExpression struct {
 	LeftOperand  Expression
 	Operator     string
 	RightOperand Expression
+	Position     Position
+}
+
+type Expressions []Expression
+
+// Array of fixed length: [5]int
+type ArrayType struct {
+	Length  Expression
+	Element Expression
+}
+
+// Array of dynamic length: []int
+type SliceType struct {
+	Element Expression
+}
+
+// map[string]int
+type MapType struct {
+	Key   Expression
+	Value Expression
+}
+
+// Fields with the same type can be grouped: X, Y int
+type Field struct {
+	Names []Identifier
+	Type  Expression
+}
+
+type StructType struct {
+	Fields []Field
+}
+
+// Field access: p.X
+type SelectorExpression struct {
+	Expression Expression
+	Selector   Identifier
+	Position   Position
+}
+
+// Element of composite literal with key: Point{X: 1}
+type KeyValueExpression struct {
+	Key   Expression
+	Value Expression
+}
+
+// Composite literal looks like: []int{1, 2, 3}
+type CompositeLiteral struct {
+	Type     Expression
+	Elements Expressions
+	Position Position
+}
+
+type IndexExpression struct {
+	Expression Expression
+	Index      Expression
+	Position   Position
+}
+
+// Used both for function calls and builtins like len(a)
+type CallExpression struct {
+	Function  Expression
+	Arguments Expressions
+	Position  Position
 }
 
 func (i UnaryExpression) String() string {
@@ -125,16 +277,105 @@ func (i BinaryExpression) String() string {
 		i.LeftOperand.String(), i.Operator, i.RightOperand.String())
 }
 
+func (i Expressions) String() string {
+	var str string
+
+	for _, expression := range i {
+		str += expression.String()
+	}
+
+	return str
+}
+
+func (i ArrayType) String() string {
+	return fmt.Sprintf("\nArray type:\n  Length:%s  Element:%s",
+		i.Length.String(), i.Element.String())
+}
+
+func (i SliceType) String() string {
+	return fmt.Sprintf("\nSlice type:\n  Element:%s", i.Element.String())
+}
+
+func (i MapType) String() string {
+	return fmt.Sprintf("\nMap type:\n  Key:%s  Value:%s", i.Key.String(), i.Value.String())
+}
+
+func (i Field) String() string {
+	var str string
+
+	for _, name := range i.Names {
+		str += name.String()
+	}
+
+	return fmt.Sprintf("\nField:\n  Names:%s  Type:%s", str, i.Type.String())
+}
+
+func (i StructType) String() string {
+	var str string
+
+	for _, field := range i.Fields {
+		str += field.String()
+	}
+
+	return fmt.Sprintf("\nStruct type:\n  Fields:%s", str)
+}
+
+func (i SelectorExpression) String() string {
+	return fmt.Sprintf("\nSelector expression:\n  Expression:%s  Selector:%s",
+		i.Expression.String(), i.Selector.String())
+}
+
+func (i KeyValueExpression) String() string {
+	return fmt.Sprintf("\nKey value expression:\n  Key:%s  Value:%s",
+		i.Key.String(), i.Value.String())
+}
+
+func (i CompositeLiteral) String() string {
+	return fmt.Sprintf("\nComposite literal:\n  Type:%s  Elements:%s",
+		i.Type.String(), i.Elements.String())
+}
+
+func (i IndexExpression) String() string {
+	return fmt.Sprintf("\nIndex expression:\n  Expression:%s  Index:%s",
+		i.Expression.String(), i.Index.String())
+}
+
+func (i CallExpression) String() string {
+	return fmt.Sprintf("\nCall expression:\n  Function:%s  Arguments:%s",
+		i.Function.String(), i.Arguments.String())
+}
+
 //------------------------------------------------------------------------------
 // Statements
 type Statement interface {
 	Ast
 }
 
+// Each target is an identifier, an index or a selector expression.
+// Several targets are assigned from a single expression: v, ok := m[k],
+// or from the same number of values, then expression is Expressions: a, b = b, a.
+// All values are evaluated before any of the targets is assigned.
 type AssignStatement struct {
-	Identifier Identifier
+	Targets    Expressions
 	Operator   string
 	Expression Expression
+	Position   Position
+}
+
+// Call which result isn't used: delete(m, k)
+type ExpressionStatement struct {
+	Expression Expression
+	Position   Position
+}
+
+// Declaration with 'var' keyword: var a [5]int.
+// Type is nil when it's inferred from expression
+// and expression is nil when variable is initialized with zero value.
+type VarStatement struct {
+	Identifier Identifier
+	Type       Expression
+	Expression Expression
+	Position   Position
 }
 
 type Statements []Statement
@@ -145,12 +386,19 @@ type BlockStatement struct {
 }
 
 type BranchStatement struct {
-	Keyword string
+	Keyword  string
+	Position Position
+}
+
+type ReturnStatement struct {
+	Results  Expressions // Empty if nothing is returned
+	Position Position
 }
 
 type CaseStatement struct {
 	Expression Expression
 	Body       BlockStatement
+	Position   Position
 }
 
 type CaseStatements []CaseStatement
@@ -158,17 +406,38 @@ type CaseStatements []CaseStatement
 type SwitchStatement struct {
 	Expression Expression
 	Body       CaseStatements
+	Position   Position
 }
 
 type IfStatement struct {
 	Condition Expression
 	IfBody    BlockStatement
 	ElseBody  BlockStatement
+	Position  Position
 }
 
 func (i AssignStatement) String() string {
-	return fmt.Sprintf("\nAssign statement\n  Identifier:\n%s\n  Operator: %s\n  Expression:\n%s",
-		i.Identifier.String(), i.Operator, i.Expression.String())
+	return fmt.Sprintf("\nAssign statement\n  Targets:\n%s\n  Operator: %s\n  Expression:\n%s",
+		i.Targets.String(), i.Operator, i.Expression.String())
+}
+
+func (i ExpressionStatement) String() string {
+	return fmt.Sprintf("\nExpression statement\n  Expression:\n%s", i.Expression.String())
+}
+
+func (i VarStatement) String() string {
+	var varType, expr string
+
+	if i.Type != nil {
+		varType = i.Type.String()
+	}
+
+	if i.Expression != nil {
+		expr = i.Expression.String()
+	}
+
+	return fmt.Sprintf("\nVar statement\n  Identifier:\n%s\n  Type:%s\n  Expression:\n%s",
+		i.Identifier.String(), varType, expr)
 }
 
 func (i Statements) String() string {
@@ -193,6 +462,10 @@ func (i BranchStatement) String() string {
 	return fmt.Sprintf("\nBranch statement:\n  Keyword: '%s'", i.Keyword)
 }
 
+func (i ReturnStatement) String() string {
+	return fmt.Sprintf("\nReturn statement:\n  Results: %s", i.Results.String())
+}
+
 func (i CaseStatements) String() string {
 	var str string
 
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"reflect"
+)
+
+var positionType = reflect.TypeOf(Position{})
+
+// Syntax trees are equal if they differ only in positions, so code which is written
+// in another way gives the same tree: a+b and a + b.
+// Empty lists are equal to missing ones.
+func Equal(x Ast, y Ast) bool {
+	return equalValues(reflect.ValueOf(x), reflect.ValueOf(y))
+}
+
+func equalValues(x reflect.Value, y reflect.Value) bool {
+	if !x.IsValid() || !y.IsValid() {
+		return x.IsValid() == y.IsValid()
+	}
+
+	if x.Type() != y.Type() {
+		return false
+	}
+
+	switch x.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+
+		return equalValues(x.Elem(), y.Elem())
+	case reflect.Struct:
+		if x.Type() == positionType {
+			return true
+		}
+
+		for i := 0; i < x.NumField(); i++ {
+			if !equalValues(x.Field(i), y.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Slice:
+		if x.Len() != y.Len() {
+			return false
+		}
+
+		for i := 0; i < x.Len(); i++ {
+			if !equalValues(x.Index(i), y.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(x.Interface(), y.Interface())
+}
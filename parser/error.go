@@ -11,6 +11,10 @@ const (
 	ExpectError
 	AssignError
 	MismatchedTypesError
+	CallError
+	ImportError
+	UnsupportedError // Construct of Go which the syntax tree has no node for or which isn't translatable
+	CheckError       // Error found by go/types
 )
 
 type Error struct {
@@ -10,10 +10,15 @@ type Parser struct {
 	currentIndex int
 	currentToken lexer.Token
 	errors       []*Error
+
+	// Negative inside 'if' and 'switch' headers, where '{' starts the body
+	// and can't be treated as a beginning of composite literal: if a == b {}.
+	// Increased inside any parentheses, so literals can be used there.
+	exprLevel int
 }
 
 func NewParser(tokens []lexer.Token) *Parser {
-	return &Parser{tokens, 0, tokens[0], []*Error{}}
+	return &Parser{tokens, 0, tokens[0], []*Error{}, 0}
 }
 
 func (parser *Parser) Parse() File {
@@ -27,8 +32,16 @@ func (parser *Parser) Parse() File {
 		return ast
 	}
 
+	ast.Imports = parser.parseImports()
+
 	for !parser.foundEndOfFile() {
 		parser.skipLineEndings()
+
+		if parser.isTokenOfType(Type) {
+			ast.Declarations = append(ast.Declarations, parser.parseTypeDeclaration())
+			continue
+		}
+
 		function := parser.parseFunctionDeclaration()
 
 		if function.Name.Name != "" {
@@ -100,11 +113,22 @@ func (parser *Parser) expectSemicolon() *Error {
 	return nil
 }
 
+// Position of the current token
+func (parser *Parser) position() Position {
+	return Position{parser.currentToken.Line, parser.currentToken.Column}
+}
+
 func (parser *Parser) isTokenOfType(tokenType TokenType) bool {
 	parser.skipLineEndings()
 	return parser.currentToken.Text == GetType(tokenType)
 }
 
+// Same as isTokenOfType, but line endings are not skipped.
+// Used inside expressions where new line ends the statement.
+func (parser *Parser) isCurrentToken(tokenType TokenType) bool {
+	return parser.currentToken.Text == GetType(tokenType)
+}
+
 func (parser *Parser) nextToken() {
 	parser.currentIndex++
 
@@ -128,6 +152,59 @@ func (parser *Parser) parsePackage() Package {
 	return Package{ident.Name}
 }
 
+// Imports go right after the package clause:
+//   import "fmt"
+//   import (
+//       "fmt"
+//       "math"
+//   )
+func (parser *Parser) parseImports() Imports {
+	var imports Imports
+
+	for parser.isTokenOfType(Import) {
+		parser.nextToken()
+
+		if !parser.isCurrentToken(LeftParen) {
+			imports = append(imports, parser.parseImportPath())
+			parser.isErrorFound(parser.expectSemicolon())
+			continue
+		}
+
+		parser.nextToken()
+
+		for !parser.isTokenOfType(RightParen) && !parser.foundEndOfFile() {
+			imports = append(imports, parser.parseImportPath())
+
+			if !parser.isCurrentToken(RightParen) {
+				parser.isErrorFound(parser.expectSemicolon())
+			}
+		}
+
+		parser.isErrorFound(parser.expect(RightParen))
+		parser.isErrorFound(parser.expectSemicolon())
+	}
+
+	return imports
+}
+
+func (parser *Parser) parseImportPath() ImportSpec {
+	token := parser.currentToken
+	lit, err := parser.parseLiteral(token)
+
+	if err == nil && lit.(Literal).Type != StringLiteral {
+		err = NewTypeError("Import path", token)
+	}
+
+	parser.isErrorFound(err)
+	parser.nextToken()
+
+	if err != nil {
+		return ImportSpec{}
+	}
+
+	return ImportSpec{lit.(Literal).Value.(string)}
+}
+
 //------------------------------------------------------------------------------------------
 // Parsing declarations
 func (parser *Parser) parseIdentifier() (Identifier, *Error) {
@@ -139,7 +216,7 @@ func (parser *Parser) parseIdentifier() (Identifier, *Error) {
 
 	parser.nextToken()
 
-	return Identifier{token.Text}, nil
+	return Identifier{token.Text, Position{token.Line, token.Column}}, nil
 }
 
 func (parser *Parser) parseLiteral(token lexer.Token) (Expression, *Error) {
@@ -147,14 +224,16 @@ func (parser *Parser) parseLiteral(token lexer.Token) (Expression, *Error) {
 		return Literal{}, NewTypeError(lexer.Literal, token)
 	}
 
+	position := Position{token.Line, token.Column}
+
 	if res, err := strconv.ParseInt(token.Text, 10, 32); err == nil {
-		return Literal{IntegerLiteral, res}, nil
+		return Literal{IntegerLiteral, res, position}, nil
 	} else if res, err := strconv.ParseBool(token.Text); err == nil {
-		return Literal{BooleanLiteral, res}, nil
+		return Literal{BooleanLiteral, res, position}, nil
 	} else if res, err := strconv.ParseFloat(token.Text, 64); err == nil {
-		return Literal{FloatLiteral, res}, nil
+		return Literal{FloatLiteral, res, position}, nil
 	} else if len(token.Text) > 1 { // String literal with quotes removed
-		return Literal{StringLiteral, token.Text[1 : len(token.Text)-1]}, nil
+		return Literal{StringLiteral, token.Text[1 : len(token.Text)-1], position}, nil
 	}
 
 	// For some reason switching to next token here
@@ -169,6 +248,7 @@ func (parser *Parser) parseFunctionDeclaration() FuncDeclaration {
 		return FuncDeclaration{}
 	}
 
+	position := parser.position()
 	err := parser.expect(Func)
 
 	if parser.isErrorFound(err) {
@@ -179,13 +259,155 @@ func (parser *Parser) parseFunctionDeclaration() FuncDeclaration {
 	parser.isErrorFound(err)
 
 	parser.isErrorFound(parser.expect(LeftParen))
+	params := parser.parseParameters()
 	parser.isErrorFound(parser.expect(RightParen))
 
+	results := parser.parseResults()
+
 	parser.isErrorFound(parser.expect(LeftBrace))
 	body := parser.parseBlockStatement()
 	parser.isErrorFound(parser.expect(RightBrace))
 
-	return FuncDeclaration{name, body}
+	return FuncDeclaration{name, params, results, body, position}
+}
+
+// Results are either a single type or a list in parentheses,
+// where all results are named or none of them:
+//   func f() int
+//   func f() (int, string)
+//   func f() (q, r int)
+func (parser *Parser) parseResults() []Field {
+	if parser.isCurrentToken(LeftBrace) {
+		return nil
+	}
+
+	if !parser.isCurrentToken(LeftParen) {
+		resultType, err := parser.parseType()
+		parser.isErrorFound(err)
+		return []Field{{Type: resultType}}
+	}
+
+	parser.nextToken()
+
+	// Each item is a type or a name followed by type.
+	// Until the list ends it's unknown whether first items are names or types.
+	var items []Field
+	named := false
+
+	for !parser.isCurrentToken(RightParen) && !parser.foundEndOfFile() {
+		first, err := parser.parseType()
+
+		if parser.isErrorFound(err) {
+			return nil
+		}
+
+		if parser.isCurrentToken(Comma) || parser.isCurrentToken(RightParen) {
+			items = append(items, Field{Type: first})
+		} else {
+			resultType, err := parser.parseType()
+
+			if parser.isErrorFound(err) {
+				return nil
+			}
+
+			items = append(items, Field{[]Identifier{toIdentifier(first)}, resultType})
+			named = true
+		}
+
+		if !parser.isCurrentToken(Comma) {
+			break
+		}
+
+		parser.nextToken()
+	}
+
+	parser.isErrorFound(parser.expect(RightParen))
+
+	if !named {
+		return items
+	}
+
+	// Names without types share the type of the next result: (q, r int)
+	var results []Field
+	var names []Identifier
+
+	for _, item := range items {
+		if item.Names == nil {
+			names = append(names, toIdentifier(item.Type))
+			continue
+		}
+
+		results = append(results, Field{append(names, item.Names...), item.Type})
+		names = nil
+	}
+
+	if names != nil {
+		parser.isErrorFound(NewExpectError("Result type", GetType(RightParen)))
+	}
+
+	return results
+}
+
+func toIdentifier(expression Expression) Identifier {
+	ident, _ := expression.(Identifier)
+	return ident
+}
+
+// Parameters of the same type can be grouped:
+//   func f(a, b int, c float64)
+func (parser *Parser) parseParameters() []Field {
+	var params []Field
+
+	for !parser.isCurrentToken(RightParen) && !parser.foundEndOfFile() {
+		names, err := parser.parseIdentifierList()
+
+		if parser.isErrorFound(err) {
+			return params
+		}
+
+		paramType, err := parser.parseType()
+
+		if parser.isErrorFound(err) {
+			return params
+		}
+
+		params = append(params, Field{names, paramType})
+
+		if !parser.isCurrentToken(Comma) {
+			break
+		}
+
+		parser.nextToken()
+	}
+
+	return params
+}
+
+// Type declaration can look like:
+//   type Point struct { X, Y int }
+//   type Celsius float64
+//   type Number = int (Alias)
+func (parser *Parser) parseTypeDeclaration() TypeDeclaration {
+	position := parser.position()
+	parser.nextToken()
+
+	name, err := parser.parseIdentifier()
+	parser.isErrorFound(err)
+
+	alias := parser.isCurrentToken(Assign)
+
+	if alias {
+		parser.nextToken()
+	}
+
+	declType, err := parser.parseType()
+	parser.isErrorFound(err)
+
+	if !parser.foundEndOfFile() {
+		parser.isErrorFound(parser.expectSemicolon())
+	}
+
+	return TypeDeclaration{name, declType, alias, position}
 }
 
 //------------------------------------------------------------------------------------------
@@ -194,13 +416,16 @@ func (parser *Parser) parseExpression() (Expression, *Error) {
 	return parser.parseBinaryExpression(LowestPrecedence)
 }
 
-// Recursively parsing expressions until non-operator found
+// Recursively parsing expressions until non-operator found.
+// Right operand takes only operators of higher precedence,
+// so operators of the same precedence are grouped from the left.
 func (parser *Parser) parseBinaryExpression(prec int) (Expression, *Error) {
 	left, _ := parser.parseUnaryExpression()
 
 	for {
 		otherPrec := precedence(parser.currentToken)
 		operator := parser.currentToken.Text
+		position := parser.position()
 
 		err := parser.expectType(lexer.Operator)
 
@@ -209,19 +434,20 @@ func (parser *Parser) parseBinaryExpression(prec int) (Expression, *Error) {
 		}
 
 		parser.nextToken()
-		right, err := parser.parseBinaryExpression(otherPrec)
+		right, err := parser.parseBinaryExpression(otherPrec + 1)
 
 		if parser.isErrorFound(err) {
 			return left, err
 		}
 
-		left = BinaryExpression{left, operator, right}
+		left = BinaryExpression{left, operator, right, position}
 	}
 }
 
 func (parser *Parser) parseUnaryExpression() (Expression, *Error) {
 	if parser.currentToken.TokenType == lexer.Operator {
 		operator := parser.currentToken.Text
+		position := parser.position()
 		parser.nextToken()
 		x, err := parser.parseUnaryExpression()
 
@@ -229,10 +455,10 @@ func (parser *Parser) parseUnaryExpression() (Expression, *Error) {
 			return UnaryExpression{}, err
 		}
 
-		return UnaryExpression{operator, x}, nil
+		return UnaryExpression{operator, x, position}, nil
 	}
 
-	return parser.parseSimpleExpression()
+	return parser.parsePrimaryExpression()
 }
 
 func (parser *Parser) parseSimpleExpression() (Expression, *Error) {
@@ -248,15 +474,248 @@ func (parser *Parser) parseSimpleExpression() (Expression, *Error) {
 		return lit, err
 	}
 
+	switch parser.currentToken.Text {
+	case GetType(LeftParen):
+		parser.nextToken()
+		parser.exprLevel++
+		expr, err := parser.parseExpression()
+		parser.exprLevel--
+		parser.isErrorFound(parser.expect(RightParen))
+		return expr, err
+	case GetType(LeftBracket), GetType(Struct), GetType(Map):
+		return parser.parseCompositeLiteral()
+	}
+
 	return UnaryExpression{}, NewExpectError("Expression", parser.currentToken.Text)
 }
 
+// Simple expression followed by any number of indices, calls or selectors:
+// a[i], len(a), p.X, Point{1, 2}
+func (parser *Parser) parsePrimaryExpression() (Expression, *Error) {
+	expr, err := parser.parseSimpleExpression()
+
+	if err != nil {
+		return expr, err
+	}
+
+	for {
+		position := parser.position()
+
+		switch {
+		case parser.isCurrentToken(LeftBracket):
+			parser.nextToken()
+			parser.exprLevel++
+			index, _ := parser.parseExpression()
+			parser.exprLevel--
+			parser.isErrorFound(parser.expect(RightBracket))
+			expr = IndexExpression{expr, index, position}
+		case parser.isCurrentToken(LeftParen):
+			parser.nextToken()
+			expr = CallExpression{expr, parser.parseExpressionList(RightParen), position}
+		case parser.isCurrentToken(Dot):
+			parser.nextToken()
+			selector, err := parser.parseIdentifier()
+
+			if parser.isErrorFound(err) {
+				return expr, err
+			}
+
+			expr = SelectorExpression{expr, selector, position}
+		case parser.isCurrentToken(LeftBrace) && parser.exprLevel >= 0 && isTypeName(expr):
+			parser.nextToken()
+			expr = CompositeLiteral{expr, parser.parseExpressionList(RightBrace), position}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+// Comma separated expressions ending with closing token: (a, b) or {1, 2, 3}.
+// Closing token itself is consumed too.
+func (parser *Parser) parseExpressionList(closing TokenType) Expressions {
+	var list Expressions
+
+	parser.exprLevel++
+	defer func() { parser.exprLevel-- }()
+
+	for !parser.isTokenOfType(closing) && !parser.foundEndOfFile() {
+		expr, _ := parser.parseExpression()
+
+		// Elements of composite literals can have keys: Point{X: 1}
+		if closing == RightBrace && parser.isCurrentToken(Colon) {
+			parser.nextToken()
+			value, _ := parser.parseExpression()
+			expr = KeyValueExpression{expr, value}
+		}
+
+		list = append(list, expr)
+
+		if !parser.isTokenOfType(Comma) {
+			break
+		}
+
+		parser.nextToken()
+	}
+
+	parser.isErrorFound(parser.expect(closing))
+
+	return list
+}
+
+func (parser *Parser) parseCompositeLiteral() (Expression, *Error) {
+	litType, err := parser.parseType()
+
+	if parser.isErrorFound(err) {
+		return UnaryExpression{}, err
+	}
+
+	position := parser.position()
+
+	if err := parser.expect(LeftBrace); parser.isErrorFound(err) {
+		return UnaryExpression{}, err
+	}
+
+	return CompositeLiteral{litType, parser.parseExpressionList(RightBrace), position}, nil
+}
+
+// Type can look like:
+//   int
+//   [5]int
+//   []int
+//   struct { X, Y int }
+//   map[string]int
+func (parser *Parser) parseType() (Expression, *Error) {
+	if parser.isCurrentToken(Struct) {
+		return parser.parseStructType()
+	}
+
+	if parser.isCurrentToken(Map) {
+		return parser.parseMapType()
+	}
+
+	if !parser.isCurrentToken(LeftBracket) {
+		return parser.parseIdentifier()
+	}
+
+	parser.nextToken()
+
+	if parser.isCurrentToken(RightBracket) {
+		parser.nextToken()
+		element, err := parser.parseType()
+		return SliceType{element}, err
+	}
+
+	length, _ := parser.parseExpression()
+
+	if err := parser.expect(RightBracket); err != nil {
+		return Identifier{}, err
+	}
+
+	element, err := parser.parseType()
+
+	return ArrayType{length, element}, err
+}
+
+// Fields are delimited with semicolons or new lines:
+//   struct { X, Y int; Name string }
+func (parser *Parser) parseStructType() (Expression, *Error) {
+	parser.nextToken()
+
+	if err := parser.expect(LeftBrace); err != nil {
+		return Identifier{}, err
+	}
+
+	var fields []Field
+
+	for !parser.isTokenOfType(RightBrace) && !parser.foundEndOfFile() {
+		names, err := parser.parseIdentifierList()
+
+		if err != nil {
+			return Identifier{}, err
+		}
+
+		fieldType, err := parser.parseType()
+
+		if err != nil {
+			return Identifier{}, err
+		}
+
+		fields = append(fields, Field{names, fieldType})
+
+		if !parser.isCurrentToken(RightBrace) {
+			if err := parser.expectSemicolon(); err != nil {
+				return Identifier{}, err
+			}
+		}
+	}
+
+	if err := parser.expect(RightBrace); err != nil {
+		return Identifier{}, err
+	}
+
+	return StructType{fields}, nil
+}
+
+// Comma separated names: a, b, c
+func (parser *Parser) parseIdentifierList() ([]Identifier, *Error) {
+	var names []Identifier
+
+	for {
+		name, err := parser.parseIdentifier()
+
+		if err != nil {
+			return names, err
+		}
+
+		names = append(names, name)
+
+		if !parser.isCurrentToken(Comma) {
+			return names, nil
+		}
+
+		parser.nextToken()
+	}
+}
+
+func (parser *Parser) parseMapType() (Expression, *Error) {
+	parser.nextToken()
+
+	if err := parser.expect(LeftBracket); err != nil {
+		return Identifier{}, err
+	}
+
+	key, err := parser.parseType()
+
+	if err != nil {
+		return Identifier{}, err
+	}
+
+	if err := parser.expect(RightBracket); err != nil {
+		return Identifier{}, err
+	}
+
+	value, err := parser.parseType()
+
+	return MapType{key, value}, err
+}
+
+// Only named types can be followed by braces of composite literal,
+// other types start with their own tokens and are parsed in parseCompositeLiteral.
+func isTypeName(expression Expression) bool {
+	switch expression.(type) {
+	case Identifier, SelectorExpression:
+		return true
+	}
+
+	return false
+}
+
 //------------------------------------------------------------------------------------------
 // Parsing statements
 func (parser *Parser) parseStatement() (Statement, *Error) {
 	switch parser.currentToken.TokenType {
 	case lexer.Identifier:
-		return parser.parseAssignStatement(), nil
+		return parser.parseSimpleStatement(), nil
 	case lexer.EndOfLine:
 		parser.nextToken()
 		return parser.parseStatement()
@@ -264,46 +723,142 @@ func (parser *Parser) parseStatement() (Statement, *Error) {
 
 	switch parser.currentToken.Text {
 	case GetType(Var):
-		return parser.parseAssignStatement(), nil
+		return parser.parseVarStatement(), nil
 	case GetType(Switch):
 		return parser.parseSwitchStatement(), nil
 	case GetType(If):
 		return parser.parseIfStatement(), nil
-	case GetType(Break), GetType(Continue), GetType(Return):
+	case GetType(Break), GetType(Continue):
 		keyword := parser.currentToken.Text
+		position := parser.position()
 		parser.nextToken()
-		return BranchStatement{keyword}, nil
+		return BranchStatement{keyword, position}, nil
+	case GetType(Return):
+		return parser.parseReturnStatement(), nil
 	}
 
 	return nil, NewExpectError("Statement", parser.currentToken.Text)
 }
 
-// Assign statement can look like:
-//   var a = 2 (Initializing)
+// Simple statement can look like:
 //   a := 2    (Initializing)
 //   a = 2
-func (parser *Parser) parseAssignStatement() AssignStatement {
-	if parser.isTokenOfType(Var) {
+//   a[i] = 2
+//   v, ok := m[k]
+//   a, b = b, a
+//   delete(m, k)
+func (parser *Parser) parseSimpleStatement() Statement {
+	var targets Expressions
+	position := parser.position()
+
+	for {
+		// Errors are already reported while parsing the expression
+		target, _ := parser.parsePrimaryExpression()
+		targets = append(targets, target)
+
+		if !parser.isCurrentToken(Comma) {
+			break
+		}
+
 		parser.nextToken()
 	}
 
-	ident, err := parser.parseIdentifier()
-	parser.isErrorFound(err)
+	if call, status := targets[0].(CallExpression); status && len(targets) == 1 &&
+		!parser.isCurrentToken(Assign) && !parser.isCurrentToken(Define) {
+		parser.isErrorFound(parser.expectSemicolon())
+		return ExpressionStatement{call, position}
+	}
 
 	if !parser.isTokenOfType(Assign) && !parser.isTokenOfType(Define) {
 		err := NewExpectError("':=' or '='", parser.currentToken.Text)
 		parser.isErrorFound(err)
-		return AssignStatement{Expression: UnaryExpression{}}
+		return AssignStatement{Targets: Expressions{Identifier{}}, Expression: UnaryExpression{}, Position: position}
 	}
 
 	operator := parser.currentToken.Text
 	parser.nextToken()
 	expr, _ := parser.parseExpression()
 
-	err = parser.expectSemicolon()
+	// Several values are kept together, they're assigned at once
+	if parser.isCurrentToken(Comma) {
+		values := Expressions{expr}
+
+		for parser.isCurrentToken(Comma) {
+			parser.nextToken()
+			value, _ := parser.parseExpression()
+			values = append(values, value)
+		}
+
+		expr = values
+	}
+
+	err := parser.expectSemicolon()
+	parser.isErrorFound(err)
+
+	return AssignStatement{targets, operator, expr, position}
+}
+
+// Var statement can look like:
+//   var a = 2
+//   var a [5]int
+//   var a []int = []int{1, 2}
+// Return without values ends with a new line, semicolon or closing brace
+func (parser *Parser) parseReturnStatement() ReturnStatement {
+	var results Expressions
+	position := parser.position()
+
+	parser.nextToken()
+
+	if parser.currentToken.TokenType == lexer.EndOfLine ||
+		parser.isCurrentToken(Semicolon) || parser.isCurrentToken(RightBrace) {
+		return ReturnStatement{Position: position}
+	}
+
+	for {
+		expr, err := parser.parseExpression()
+		parser.isErrorFound(err)
+		results = append(results, expr)
+
+		if !parser.isCurrentToken(Comma) {
+			return ReturnStatement{results, position}
+		}
+
+		parser.nextToken()
+	}
+}
+
+func (parser *Parser) parseVarStatement() VarStatement {
+	position := parser.position()
+	parser.nextToken()
+
+	ident, err := parser.parseIdentifier()
 	parser.isErrorFound(err)
 
-	return AssignStatement{ident, operator, expr}
+	var varType, expr Expression
+
+	if !parser.isCurrentToken(Assign) {
+		varType, err = parser.parseType()
+		parser.isErrorFound(err)
+	}
+
+	if parser.isCurrentToken(Assign) {
+		parser.nextToken()
+		expr, _ = parser.parseExpression()
+	}
+
+	parser.isErrorFound(parser.expectSemicolon())
+
+	return VarStatement{ident, varType, expr, position}
+}
+
+// Expression after 'if' or 'switch' keyword which is followed by the body
+func (parser *Parser) parseHeaderExpression() Expression {
+	exprLevel := parser.exprLevel
+	parser.exprLevel = -1
+	expr, _ := parser.parseExpression()
+	parser.exprLevel = exprLevel
+
+	return expr
 }
 
 func (parser *Parser) parseBlockStatement() BlockStatement {
@@ -325,9 +880,10 @@ func (parser *Parser) parseBlockStatement() BlockStatement {
 }
 
 func (parser *Parser) parseIfStatement() IfStatement {
+	position := parser.position()
 	parser.nextToken()
 
-	cond, _ := parser.parseExpression()
+	cond := parser.parseHeaderExpression()
 	parser.isErrorFound(parser.expect(LeftBrace))
 	ifBody := parser.parseBlockStatement()
 	parser.isErrorFound(parser.expect(RightBrace))
@@ -335,16 +891,23 @@ func (parser *Parser) parseIfStatement() IfStatement {
 
 	if parser.isTokenOfType(Else) {
 		parser.nextToken()
+
+		// else if is kept as else block with the single if statement
+		if parser.isTokenOfType(If) {
+			return IfStatement{cond, ifBody, BlockStatement{Statements{parser.parseIfStatement()}}, position}
+		}
+
 		parser.isErrorFound(parser.expect(LeftBrace))
 		elseBody = parser.parseBlockStatement()
 		parser.isErrorFound(parser.expect(RightBrace))
 	}
 
-	return IfStatement{cond, ifBody, elseBody}
+	return IfStatement{cond, ifBody, elseBody, position}
 }
 
 func (parser *Parser) parseCaseStatement() CaseStatement {
 	expr := Expression(UnaryExpression{})
+	position := parser.position()
 
 	if parser.isTokenOfType(Case) {
 		parser.nextToken()
@@ -361,15 +924,16 @@ func (parser *Parser) parseCaseStatement() CaseStatement {
 	parser.isErrorFound(parser.expect(Colon))
 	body := parser.parseBlockStatement()
 
-	return CaseStatement{expr, body}
+	return CaseStatement{expr, body, position}
 }
 
 func (parser *Parser) parseSwitchStatement() SwitchStatement {
+	position := parser.position()
 	parser.nextToken()
 	expression := Expression(UnaryExpression{})
 
 	if !parser.isTokenOfType(LeftBrace) { // If switch has parameters
-		expression, _ = parser.parseExpression()
+		expression = parser.parseHeaderExpression()
 	}
 
 	parser.isErrorFound(parser.expect(LeftBrace))
@@ -382,5 +946,5 @@ func (parser *Parser) parseSwitchStatement() SwitchStatement {
 	parser.isErrorFound(parser.expect(RightBrace))
 	parser.expectSemicolon()
 
-	return SwitchStatement{expression, cases}
+	return SwitchStatement{expression, cases, position}
 }
@@ -29,6 +29,13 @@ const (
 	Neq
 	Leq
 	Geq
+	BitAnd
+
+	BitOr
+	BitXor
+	AndNot
+	Shl
+	Shr
 
 	Define
 	Assign
@@ -36,11 +43,14 @@ const (
 	Comma
 	Colon
 	Semicolon
+	Dot
 
 	LeftBrace
 	RightBrace
 	LeftParen
 	RightParen
+	LeftBracket
+	RightBracket
 
 	Pkg
 	Func
@@ -54,6 +64,10 @@ const (
 	Break
 	Continue
 	Return
+	Type
+	Struct
+	Map
+	Import
 )
 
 var tokenTypes = map[TokenType]string{
@@ -74,17 +88,28 @@ var tokenTypes = map[TokenType]string{
 	Leq: "<=",
 	Geq: ">=",
 
+	BitAnd: "&", // Also takes address of variable: &a
+
+	BitOr:  "|",
+	BitXor: "^",
+	AndNot: "&^",
+	Shl:    "<<",
+	Shr:    ">>",
+
 	Define: ":=",
 	Assign: "=",
 
 	Comma:     ",",
 	Colon:     ":",
 	Semicolon: ";",
+	Dot:       ".",
 
-	LeftBrace:  "{",
-	RightBrace: "}",
-	LeftParen:  "(",
-	RightParen: ")",
+	LeftBrace:    "{",
+	RightBrace:   "}",
+	LeftParen:    "(",
+	RightParen:   ")",
+	LeftBracket:  "[",
+	RightBracket: "]",
 
 	Pkg:      "package",
 	Func:     "func",
@@ -98,10 +123,15 @@ var tokenTypes = map[TokenType]string{
 	Break:    "break",
 	Continue: "continue",
 	Return:   "return",
+	Type:     "type",
+	Struct:   "struct",
+	Map:      "map",
+	Import:   "import",
 }
 
 const (
 	LowestPrecedence = 0
+	UnaryPrecedence  = 6 // Unary operators bind tighter than binary ones
 )
 
 func GetType(tokenType TokenType) string {
@@ -109,16 +139,23 @@ func GetType(tokenType TokenType) string {
 }
 
 func precedence(token lexer.Token) int {
-	switch token.Text {
+	return Precedence(token.Text)
+}
+
+// Precedence of binary operator. Operators of the same precedence
+// are applied from left to right: a - b - c is (a - b) - c
+func Precedence(operator string) int {
+	switch operator {
 	case GetType(Or):
 		return 1
 	case GetType(And):
 		return 2
 	case GetType(Eq), GetType(Neq), GetType(Less), GetType(Leq), GetType(Greater), GetType(Geq):
 		return 3
-	case GetType(Plus), GetType(Minus):
+	case GetType(Plus), GetType(Minus), GetType(BitOr), GetType(BitXor):
 		return 4
-	case GetType(Mul), GetType(Div), GetType(Mod):
+	case GetType(Mul), GetType(Div), GetType(Mod),
+		GetType(Shl), GetType(Shr), GetType(BitAnd), GetType(AndNot):
 		return 5
 	}
 
@@ -0,0 +1,355 @@
+package printer
+
+import (
+	"../parser"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// Go code of the syntax tree, formatted the same way as gofmt does it.
+// Code is written by the printer and then passed to go/format,
+// if it can't be formatted it's returned as it's written with the error.
+func File(file parser.File) (string, error) {
+	printer := &printer{}
+	printer.file(file)
+	code := printer.builder.String()
+
+	formatted, err := format.Source([]byte(code))
+
+	if err != nil {
+		return code, err
+	}
+
+	return string(formatted), nil
+}
+
+// Text of nodes which can't be written as Go code
+const errorText = "!!!Error!!!"
+
+type printer struct {
+	builder strings.Builder
+	indent  int
+}
+
+// Line with the indentation of the current block
+func (printer *printer) line(format string, args ...interface{}) {
+	printer.builder.WriteString(strings.Repeat("\t", printer.indent))
+	fmt.Fprintf(&printer.builder, format, args...)
+	printer.builder.WriteByte('\n')
+}
+
+func (printer *printer) file(file parser.File) {
+	printer.line("package %s", file.Package.Name)
+
+	if len(file.Imports) == 1 {
+		printer.line("")
+		printer.line("import %s", strconv.Quote(file.Imports[0].Path))
+	} else if len(file.Imports) > 1 {
+		printer.line("")
+		printer.line("import (")
+
+		for _, spec := range file.Imports {
+			printer.line("\t%s", strconv.Quote(spec.Path))
+		}
+
+		printer.line(")")
+	}
+
+	for _, declaration := range file.Declarations {
+		printer.line("")
+
+		if decl, status := declaration.(parser.TypeDeclaration); status {            // Type
+			printer.typeDeclaration(decl)
+		} else if decl, status := declaration.(parser.FuncDeclaration); status {     // Function
+			printer.funcDeclaration(decl)
+		}
+	}
+}
+
+// Struct types of declarations have a field on each line:
+//   type Point struct {
+//   	X, Y int
+//   }
+func (printer *printer) typeDeclaration(decl parser.TypeDeclaration) {
+	assign := " "
+
+	if decl.Alias {
+		assign = " = "
+	}
+
+	structType, status := decl.Type.(parser.StructType)
+
+	if !status || len(structType.Fields) == 0 {
+		printer.line("type %s%s%s", decl.Name.Name, assign, Expression(decl.Type))
+		return
+	}
+
+	printer.line("type %s%sstruct {", decl.Name.Name, assign)
+
+	for _, field := range structType.Fields {
+		printer.line("\t%s", fieldString(field))
+	}
+
+	printer.line("}")
+}
+
+func (printer *printer) funcDeclaration(decl parser.FuncDeclaration) {
+	signature := decl.Name.Name + "(" + fieldsString(decl.Parameters) + ")"
+
+	if len(decl.Results) == 1 && len(decl.Results[0].Names) == 0 {
+		signature += " " + Expression(decl.Results[0].Type)
+	} else if len(decl.Results) > 0 {
+		signature += " (" + fieldsString(decl.Results) + ")"
+	}
+
+	printer.line("func %s {", signature)
+	printer.block(decl.Body)
+	printer.line("}")
+}
+
+// Statements of the block are indented, braces are written by the caller
+func (printer *printer) block(block parser.BlockStatement) {
+	printer.indent++
+	printer.statements(block.Statements)
+	printer.indent--
+}
+
+func (printer *printer) statements(stmts parser.Statements) {
+	for _, stmt := range stmts {
+		printer.statement(stmt)
+	}
+}
+
+func (printer *printer) statement(statement parser.Statement) {
+	if stmt, status := statement.(parser.BlockStatement); status {                 // Block
+		printer.line("{")
+		printer.block(stmt)
+		printer.line("}")
+	} else if stmt, status := statement.(parser.IfStatement); status {            // If
+		printer.line("if %s {", Expression(stmt.Condition))
+		printer.ifStatement(stmt)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {        // Switch
+		if stmt.Expression == nil || stmt.Expression.String() == "" {
+			printer.line("switch {")
+		} else {
+			printer.line("switch %s {", Expression(stmt.Expression))
+		}
+
+		for _, caseStmt := range stmt.Body {
+			if caseStmt.Expression == nil || caseStmt.Expression.String() == "" {
+				printer.line("default:")
+			} else {
+				printer.line("case %s:", Expression(caseStmt.Expression))
+			}
+
+			printer.block(caseStmt.Body)
+		}
+
+		printer.line("}")
+	} else if stmts, status := statement.(parser.Statements); status {            // Statements
+		printer.statements(stmts)
+	} else {
+		printer.line("%s", Statement(statement))
+	}
+}
+
+// Body of if and its else branches, else block with the single if is written as else if
+func (printer *printer) ifStatement(stmt parser.IfStatement) {
+	printer.block(stmt.IfBody)
+
+	if len(stmt.ElseBody.Statements) == 0 {
+		printer.line("}")
+		return
+	}
+
+	if len(stmt.ElseBody.Statements) == 1 {
+		if elseIf, status := stmt.ElseBody.Statements[0].(parser.IfStatement); status {
+			printer.line("} else if %s {", Expression(elseIf.Condition))
+			printer.ifStatement(elseIf)
+			return
+		}
+	}
+
+	printer.line("} else {")
+	printer.block(stmt.ElseBody)
+	printer.line("}")
+}
+
+// Simple statement on a single line:
+//   c := a[i] * (b + 1)
+func Statement(statement parser.Statement) string {
+	if stmt, status := statement.(parser.AssignStatement); status {                // Assign
+		if values, status := stmt.Expression.(parser.Expressions); status {
+			return expressionsString(stmt.Targets) + " " + stmt.Operator + " " + expressionsString(values)
+		}
+
+		return expressionsString(stmt.Targets) + " " + stmt.Operator + " " + Expression(stmt.Expression)
+	} else if stmt, status := statement.(parser.VarStatement); status {          // Var
+		str := "var " + stmt.Identifier.Name
+
+		if stmt.Type != nil {
+			str += " " + Expression(stmt.Type)
+		}
+		if stmt.Expression != nil {
+			str += " = " + Expression(stmt.Expression)
+		}
+
+		return str
+	} else if stmt, status := statement.(parser.ExpressionStatement); status {   // Call
+		return Expression(stmt.Expression)
+	} else if stmt, status := statement.(parser.ReturnStatement); status {       // Return
+		if len(stmt.Results) == 0 {
+			return "return"
+		}
+
+		return "return " + expressionsString(stmt.Results)
+	} else if stmt, status := statement.(parser.BranchStatement); status {       // Branch
+		return stmt.Keyword
+	}
+
+	return errorText
+}
+
+// Parentheses are added only where precedence needs them: a * (b + 1)
+func Expression(expression parser.Expression) string {
+	if expr, status := expression.(parser.Identifier); status {                   // Identifier
+		return expr.Name
+	} else if lit, status := expression.(parser.Literal); status {                // Literal
+		return literalString(lit)
+	} else if expr, status := expression.(parser.UnaryExpression); status {       // Unary
+		operand := operandString(expr.Operand, parser.UnaryPrecedence)
+
+		// Operators are kept apart, so they aren't read as decrement: - -x
+		if strings.HasPrefix(operand, expr.Operator) && (expr.Operator == "-" || expr.Operator == "+") {
+			return expr.Operator + " " + operand
+		}
+
+		return expr.Operator + operand
+	} else if expr, status := expression.(parser.BinaryExpression); status {      // Binary
+		// Operators of the same precedence are grouped from the left: a - (b - c)
+		precedence := parser.Precedence(expr.Operator)
+
+		return operandString(expr.LeftOperand, precedence) + " " + expr.Operator + " " +
+			operandString(expr.RightOperand, precedence+1)
+	} else if expr, status := expression.(parser.IndexExpression); status {       // Index
+		return operandString(expr.Expression, parser.UnaryPrecedence+1) + "[" + Expression(expr.Index) + "]"
+	} else if expr, status := expression.(parser.SelectorExpression); status {    // Selector
+		return operandString(expr.Expression, parser.UnaryPrecedence+1) + "." + expr.Selector.Name
+	} else if call, status := expression.(parser.CallExpression); status {        // Call
+		return operandString(call.Function, parser.UnaryPrecedence+1) + "(" + expressionsString(call.Arguments) + ")"
+	} else if lit, status := expression.(parser.CompositeLiteral); status {       // Composite literal
+		return Expression(lit.Type) + "{" + expressionsString(lit.Elements) + "}"
+	} else if expr, status := expression.(parser.KeyValueExpression); status {    // Key: value
+		return Expression(expr.Key) + ": " + Expression(expr.Value)
+	} else if expr, status := expression.(parser.ArrayType); status {             // [5]int
+		return "[" + Expression(expr.Length) + "]" + Expression(expr.Element)
+	} else if expr, status := expression.(parser.SliceType); status {             // []int
+		return "[]" + Expression(expr.Element)
+	} else if expr, status := expression.(parser.MapType); status {               // map[string]int
+		return "map[" + Expression(expr.Key) + "]" + Expression(expr.Value)
+	} else if expr, status := expression.(parser.StructType); status {            // struct{ X, Y int }
+		if len(expr.Fields) == 0 {
+			return "struct{}"
+		}
+
+		var fields []string
+
+		for _, field := range expr.Fields {
+			fields = append(fields, fieldString(field))
+		}
+
+		return "struct{ " + strings.Join(fields, "; ") + " }"
+	}
+
+	return errorText
+}
+
+func expressionsString(expressions parser.Expressions) string {
+	var strs []string
+
+	for _, expr := range expressions {
+		strs = append(strs, Expression(expr))
+	}
+
+	return strings.Join(strs, ", ")
+}
+
+// Operand is put in parentheses if its operator binds weaker than the enclosing one
+func operandString(operand parser.Expression, precedence int) string {
+	operandPrecedence := parser.UnaryPrecedence + 1
+
+	if expr, status := operand.(parser.BinaryExpression); status {
+		operandPrecedence = parser.Precedence(expr.Operator)
+	} else if _, status := operand.(parser.UnaryExpression); status {
+		operandPrecedence = parser.UnaryPrecedence
+	}
+
+	if operandPrecedence < precedence {
+		return "(" + Expression(operand) + ")"
+	}
+
+	return Expression(operand)
+}
+
+// Names with the same type are grouped: x, y int
+func fieldString(field parser.Field) string {
+	var names []string
+
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+
+	if len(names) == 0 {
+		return Expression(field.Type)
+	}
+
+	return strings.Join(names, ", ") + " " + Expression(field.Type)
+}
+
+func fieldsString(fields []parser.Field) string {
+	var strs []string
+
+	for _, field := range fields {
+		strs = append(strs, fieldString(field))
+	}
+
+	return strings.Join(strs, ", ")
+}
+
+// Strings keep escapes they are written with, floats keep the point: 2.0
+func literalString(lit parser.Literal) string {
+	switch value := lit.Value.(type) {
+	case string:
+		return "\"" + value + "\""
+	case float64:
+		str := strconv.FormatFloat(value, 'g', -1, 64)
+
+		if !strings.ContainsAny(str, ".eIN") {
+			str += ".0"
+		}
+
+		return str
+	}
+
+	return fmt.Sprint(lit.Value)
+}
+
+// Longer snippets are cut, so errors stay on a single line
+const snippetLength = 40
+
+// Code of expression or simple statement quoted in errors: a + b > 2
+func Snippet(node parser.Ast) string {
+	str := Expression(node)
+
+	if str == errorText {
+		str = Statement(node)
+	}
+
+	if runes := []rune(str); len(runes) > snippetLength {
+		str = string(runes[:snippetLength-3]) + "..."
+	}
+
+	return str
+}
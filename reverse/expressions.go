@@ -0,0 +1,464 @@
+package reverse
+
+import (
+	"../lwiqa"
+	"../parser"
+	"../semantic"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type of constants, they take the type of the operand they're used with: 2 in &x& * 2
+type untyped struct {
+	basic lwiqa.BasicType
+}
+
+// Basic type of constant or underlying type of typed value, nil if it isn't known
+func (translator *translator) kind(t lwiqa.Type) lwiqa.Type {
+	if u, status := t.(untyped); status {
+		return u.basic
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return translator.underlying(t)
+}
+
+// Type which constant gets when it's assigned to a new variable: 2 is INTEGER, 2.5 is REAL
+func defaultType(t lwiqa.Type) lwiqa.Type {
+	if u, status := t.(untyped); status {
+		return u.basic
+	}
+
+	return t
+}
+
+func isTyped(t lwiqa.Type) bool {
+	_, status := t.(untyped)
+	return t != nil && !status
+}
+
+var unaryOperators = map[string]string{"-": "-", "+": "+", "NOT": "!", "BITNOT": "^"}
+
+var binaryOperators = map[string]string{
+	"OR": "||", "AND": "&&", "=": "==", "<>": "!=", "<": "<", "<=": "<=", ">": ">", ">=": ">=",
+	"+": "+", "-": "-", "*": "*", "/": "/", "DIV": "/", "MOD": "%", "SHL": "<<", "SHR": ">>",
+	"BITAND": "&", "BITOR": "|", "BITXOR": "^", "CONCAT": "+",
+}
+
+// Expression is translated with the type it's expected to have, so literals of arrays, maps
+// and records get their types: [1, 2] assigned to ARRAY[] OF INTEGER   ->   []int{1, 2}.
+// Type of the translated expression is returned, nil if it isn't known.
+func (translator *translator) expression(expression lwiqa.Expression, want lwiqa.Type) (parser.Expression, lwiqa.Type) {
+	if expr, status := expression.(lwiqa.Identifier); status {                        // Identifier
+		if expr.Name == semantic.Blank || translator.blanks[expr.Name] {
+			return parser.Identifier{Name: semantic.Blank}, nil
+		}
+
+		return translator.identifier(expr.Name), translator.variables[expr.Name]
+	} else if expr, status := expression.(lwiqa.Literal); status {                   // Literal
+		return literal(expr.Value)
+	} else if expr, status := expression.(lwiqa.UnaryExpression); status {           // Unary
+		if expr.Operator == "NOT" {
+			want = lwiqa.Boolean
+		}
+
+		operand, t := translator.expression(expr.Operand, want)
+
+		return parser.UnaryExpression{Operator: unaryOperators[expr.Operator], Operand: operand}, t
+	} else if expr, status := expression.(lwiqa.BinaryExpression); status {          // Binary
+		return translator.binaryExpression(expr, want)
+	} else if expr, status := expression.(lwiqa.IndexExpression); status {           // Index
+		container, t := translator.expression(expr.Expression, nil)
+		var key, element lwiqa.Type
+
+		switch u := translator.kind(t).(type) {
+		case lwiqa.ArrayType:
+			element = u.Element
+		case lwiqa.MapType:
+			key, element = u.Key, u.Value
+		}
+
+		index, _ := translator.expression(expr.Index, key)
+
+		return parser.IndexExpression{Expression: container, Index: index}, element
+	} else if expr, status := expression.(lwiqa.SelectorExpression); status {        // Field
+		record, t := translator.expression(expr.Expression, nil)
+		var field lwiqa.Type
+
+		if u, status := translator.kind(t).(lwiqa.RecordType); status {
+			field = fieldType(u, expr.Field.Name)
+		}
+
+		return parser.SelectorExpression{Expression: record, Selector: translator.identifier(expr.Field.Name)}, field
+	} else if expr, status := expression.(lwiqa.CallExpression); status {            // Call
+		return translator.callExpression(expr, want)
+	} else if expr, status := expression.(lwiqa.ArrayLiteral); status {              // Array
+		return translator.arrayLiteral(expr, want)
+	} else if expr, status := expression.(lwiqa.MapLiteral); status {                // Map
+		return translator.mapLiteral(expr, want)
+	}
+
+	translator.report("expression can't be translated")
+	return parser.Identifier{Name: semantic.Blank}, nil
+}
+
+// Integers, reals, strings and booleans, strings get their escapes back: "a\n"
+func literal(value interface{}) (parser.Expression, lwiqa.Type) {
+	switch value := value.(type) {
+	case int64:
+		return integerLiteral(value), untyped{lwiqa.Integer}
+	case float64:
+		return parser.Literal{Type: parser.FloatLiteral, Value: value}, untyped{lwiqa.Real}
+	case string:
+		return stringLiteral(value), untyped{lwiqa.String}
+	case bool:
+		return parser.Literal{Type: parser.BooleanLiteral, Value: value}, untyped{lwiqa.Boolean}
+	}
+
+	return parser.Literal{Type: parser.IntegerLiteral, Value: int64(0)}, nil
+}
+
+func integerLiteral(value int64) parser.Literal {
+	return parser.Literal{Type: parser.IntegerLiteral, Value: value}
+}
+
+func stringLiteral(value string) parser.Literal {
+	quoted := strconv.Quote(value)
+	return parser.Literal{Type: parser.StringLiteral, Value: quoted[1 : len(quoted)-1]}
+}
+
+// Typed operand gives its type to the result, constants are typed by the other operand
+func (translator *translator) binaryExpression(expr lwiqa.BinaryExpression, want lwiqa.Type) (parser.Expression, lwiqa.Type) {
+	operator := binaryOperators[expr.Operator]
+	var t lwiqa.Type
+
+	switch expr.Operator {
+	case "OR", "AND":
+		want, t = lwiqa.Boolean, lwiqa.Boolean
+	case "=", "<>", "<", "<=", ">", ">=":
+		want, t = nil, lwiqa.Boolean
+	}
+
+	left, leftType := translator.expression(expr.LeftOperand, want)
+	right, rightType := translator.expression(expr.RightOperand, want)
+
+	if operator == "" {
+		translator.report("operator %s can't be translated", expr.Operator)
+	}
+
+	if t == nil {
+		switch {
+		case expr.Operator == "SHL" || expr.Operator == "SHR" || isTyped(leftType):
+			t = leftType
+		case isTyped(rightType):
+			t = rightType
+		case leftType == nil || rightType == nil:
+			t = nil
+		case leftType == (untyped{lwiqa.Real}) || rightType == (untyped{lwiqa.Real}):
+			t = untyped{lwiqa.Real}
+		default:
+			t = leftType
+		}
+	}
+
+	return parser.BinaryExpression{LeftOperand: left, Operator: operator, RightOperand: right}, t
+}
+
+// Calls of procedures, records and conversions to declared types, standard functions and conversions:
+//   &Point&(1, 2) -> Point{1, 2}, LENGTH(&a&) -> len(a), SQRT(&x&) -> math.Sqrt(x)
+func (translator *translator) callExpression(call lwiqa.CallExpression, want lwiqa.Type) (parser.Expression, lwiqa.Type) {
+	name := call.Function.Name
+
+	if procedure := translator.program.Procedure(name); procedure != nil && call.Function.Marked {
+		var args parser.Expressions
+
+		for _, param := range procedure.Parameters {
+			if param.Output {
+				translator.report("procedure %s has OUT parameters, its call can't be a part of expression", name)
+				break
+			}
+		}
+
+		for i, arg := range call.Arguments {
+			var t lwiqa.Type
+
+			if i < len(procedure.Parameters) {
+				t = procedure.Parameters[i].Type
+			}
+
+			expr, _ := translator.expression(arg, t)
+			args = append(args, expr)
+		}
+
+		return parser.CallExpression{Function: translator.identifier(name), Arguments: args}, procedure.Result
+	}
+
+	if decl := translator.program.Type(name); decl != nil && call.Function.Marked {
+		t := lwiqa.NamedType{Name: name}
+
+		if record, status := decl.Type.(lwiqa.RecordType); status {
+			return translator.recordLiteral(call, t, record), t
+		}
+
+		return translator.conversion(call, t)
+	}
+
+	switch upper := builtin(call); upper {
+	case "INTEGER", "REAL", "STRING", "BOOLEAN":
+		t := lwiqa.Type(lwiqa.BasicType(upper))
+
+		// Conversion to declared type: REAL(&x&) assigned to &Celsius&   ->   Celsius(x)
+		if _, status := want.(lwiqa.NamedType); status && translator.kind(want) == t {
+			t = want
+		}
+
+		return translator.conversion(call, t)
+	case "RECORD":
+		if record, status := translator.kind(want).(lwiqa.RecordType); status {
+			return translator.recordLiteral(call, want, record), want
+		}
+
+		translator.report("type of RECORD(...) isn't known")
+		return translator.recordLiteral(call, lwiqa.RecordType{}, lwiqa.RecordType{}), nil
+	case "LENGTH", "CAPACITY":
+		function := map[string]string{"LENGTH": semantic.Len, "CAPACITY": semantic.Cap}[upper]
+		return translator.builtinCall(function, call, nil), lwiqa.Integer
+	case "APPEND":
+		var t lwiqa.Type
+		var args parser.Expressions
+
+		for i, arg := range call.Arguments {
+			var expr parser.Expression
+
+			if i == 0 {
+				expr, t = translator.expression(arg, want)
+			} else if array, status := translator.kind(t).(lwiqa.ArrayType); status {
+				expr, _ = translator.expression(arg, array.Element)
+			} else {
+				expr, _ = translator.expression(arg, nil)
+			}
+
+			args = append(args, expr)
+		}
+
+		return parser.CallExpression{Function: parser.Identifier{Name: semantic.Append}, Arguments: args}, t
+	case "DELETE":
+		return translator.builtinCall(semantic.Delete, call, nil), nil
+	case "SQRT", "ABS", "FLOOR", "POWER", "MAX", "MIN":
+		translator.imports[semantic.Math] = true
+		var args parser.Expressions
+
+		for _, arg := range call.Arguments {
+			args = append(args, translator.realArgument(arg))
+		}
+
+		return packageCall(semantic.Math, mathFunctions[upper], args), lwiqa.Real
+	case "FIXED":
+		translator.imports[semantic.Fmt] = true
+		translator.report("FIXED is written with fmt.Sprintf, which isn't a part of the Go subset")
+		verb, args := translator.fixed(call)
+
+		return packageCall(semantic.Fmt, "Sprintf", append(parser.Expressions{stringLiteral(verb)}, args...)), lwiqa.String
+	case "CONTAINS", "LOOKUP":
+		translator.report("%s can be written in Go only as assignment of map index", name)
+		return translator.builtinCall(strings.ToLower(upper), call, nil), lwiqa.Boolean
+	}
+
+	translator.report("%s is not declared", name)
+	return translator.builtinCall(name, call, nil), nil
+}
+
+var mathFunctions = map[string]string{
+	"SQRT": semantic.Sqrt, "ABS": semantic.Abs, "FLOOR": semantic.Floor,
+	"POWER": semantic.Pow, "MAX": semantic.Max, "MIN": semantic.Min,
+}
+
+// Name of standard function or conversion in upper case, empty for procedures and types
+func builtin(call lwiqa.CallExpression) string {
+	if call.Function.Marked {
+		return ""
+	}
+
+	return strings.ToUpper(call.Function.Name)
+}
+
+func (translator *translator) builtinCall(name string, call lwiqa.CallExpression, want lwiqa.Type) parser.CallExpression {
+	var args parser.Expressions
+
+	for _, arg := range call.Arguments {
+		expr, _ := translator.expression(arg, want)
+		args = append(args, expr)
+	}
+
+	return parser.CallExpression{Function: parser.Identifier{Name: name}, Arguments: args}
+}
+
+// Element of map: &m&, &k&   ->   m[k]
+func (translator *translator) indexExpression(m lwiqa.Expression, key lwiqa.Expression) parser.Expression {
+	expr, _ := translator.expression(lwiqa.IndexExpression{Expression: m, Index: key}, nil)
+	return expr
+}
+
+//   fmt.Println, math.Sqrt
+func packageCall(pkg string, name string, args parser.Expressions) parser.CallExpression {
+	function := parser.SelectorExpression{Expression: parser.Identifier{Name: pkg}, Selector: parser.Identifier{Name: name}}
+	return parser.CallExpression{Function: function, Arguments: args}
+}
+
+func (translator *translator) conversion(call lwiqa.CallExpression, t lwiqa.Type) (parser.Expression, lwiqa.Type) {
+	if len(call.Arguments) != 1 {
+		translator.report("conversion to %s takes 1 argument, got %d", call.Function.Name, len(call.Arguments))
+	}
+
+	return translator.builtinCall(typeString(translator.typeExpression(t)), call, nil), t
+}
+
+// Functions of math take reals, integer variables are converted: float64(n)
+func (translator *translator) realArgument(arg lwiqa.Expression) parser.Expression {
+	expr, t := translator.expression(arg, lwiqa.Real)
+
+	if isTyped(t) && translator.kind(t) == lwiqa.Integer {
+		return parser.CallExpression{Function: parser.Identifier{Name: "float64"}, Arguments: parser.Expressions{expr}}
+	}
+
+	return expr
+}
+
+// Verb of real written with the digits: FIXED(&x&, 2)   ->   %.2f, x.
+// Digits which aren't constant are given as argument: %.*f
+func (translator *translator) fixed(call lwiqa.CallExpression) (string, parser.Expressions) {
+	if len(call.Arguments) != 2 {
+		translator.report("FIXED takes 2 arguments, got %d", len(call.Arguments))
+		return "%v", nil
+	}
+
+	x := translator.realArgument(call.Arguments[0])
+
+	if lit, status := call.Arguments[1].(lwiqa.Literal); status {
+		if digits, status := lit.Value.(int64); status {
+			if digits == 6 {
+				return "%f", parser.Expressions{x}
+			}
+
+			return fmt.Sprintf("%%.%df", digits), parser.Expressions{x}
+		}
+	}
+
+	digits, _ := translator.expression(call.Arguments[1], lwiqa.Integer)
+
+	return "%.*f", parser.Expressions{digits, x}
+}
+
+// Fields are given in order or by their names: Point{1, 2}, Point{X: 1}
+func (translator *translator) recordLiteral(call lwiqa.CallExpression, t lwiqa.Type, record lwiqa.RecordType) parser.CompositeLiteral {
+	var elements parser.Expressions
+
+	for i, arg := range call.Arguments {
+		if pair, status := arg.(lwiqa.KeyValueExpression); status {
+			key, _ := pair.Key.(lwiqa.Identifier)
+			value, _ := translator.expression(pair.Value, fieldType(record, key.Name))
+			elements = append(elements, parser.KeyValueExpression{Key: translator.identifier(key.Name), Value: value})
+			continue
+		}
+
+		var field lwiqa.Type
+
+		if i < len(record.Fields) {
+			field = record.Fields[i].Type
+		}
+
+		value, _ := translator.expression(arg, field)
+		elements = append(elements, value)
+	}
+
+	return parser.CompositeLiteral{Type: translator.typeExpression(t), Elements: elements}
+}
+
+// Nil if the record has no such field
+func fieldType(record lwiqa.RecordType, name string) lwiqa.Type {
+	for _, field := range record.Fields {
+		if field.Name == name {
+			return field.Type
+		}
+	}
+
+	return nil
+}
+
+// Elements can have indices as keys: [1, 4: 2]
+func (translator *translator) arrayLiteral(lit lwiqa.ArrayLiteral, want lwiqa.Type) (parser.Expression, lwiqa.Type) {
+	array, status := translator.kind(want).(lwiqa.ArrayType)
+
+	if !status {
+		array = lwiqa.ArrayType{Length: -1, Element: translator.elementType(lit.Elements, false)}
+		want = array
+		translator.report("type of array literal is taken from its first element: %s", typeString(translator.silentType(want)))
+	}
+
+	var elements parser.Expressions
+
+	for _, elem := range lit.Elements {
+		if pair, status := elem.(lwiqa.KeyValueExpression); status {
+			key, _ := translator.expression(pair.Key, lwiqa.Integer)
+			value, _ := translator.expression(pair.Value, array.Element)
+			elements = append(elements, parser.KeyValueExpression{Key: key, Value: value})
+		} else {
+			value, _ := translator.expression(elem, array.Element)
+			elements = append(elements, value)
+		}
+	}
+
+	return parser.CompositeLiteral{Type: translator.typeExpression(want), Elements: elements}, want
+}
+
+func (translator *translator) mapLiteral(lit lwiqa.MapLiteral, want lwiqa.Type) (parser.Expression, lwiqa.Type) {
+	m, status := translator.kind(want).(lwiqa.MapType)
+
+	if !status {
+		m = lwiqa.MapType{Key: translator.elementType(lit.Elements, true), Value: translator.elementType(lit.Elements, false)}
+		want = m
+		translator.report("type of map literal is taken from its first element: %s", typeString(translator.silentType(want)))
+	}
+
+	var elements parser.Expressions
+
+	for _, elem := range lit.Elements {
+		pair, _ := elem.(lwiqa.KeyValueExpression)
+		key, _ := translator.expression(pair.Key, m.Key)
+		value, _ := translator.expression(pair.Value, m.Value)
+		elements = append(elements, parser.KeyValueExpression{Key: key, Value: value})
+	}
+
+	return parser.CompositeLiteral{Type: translator.typeExpression(want), Elements: elements}, want
+}
+
+// Type of the first element or its key, INTEGER if it isn't known
+func (translator *translator) elementType(elements lwiqa.Expressions, key bool) lwiqa.Type {
+	if len(elements) == 0 {
+		return lwiqa.Integer
+	}
+
+	elem := elements[0]
+
+	if pair, status := elem.(lwiqa.KeyValueExpression); status {
+		elem = pair.Value
+
+		if key {
+			elem = pair.Key
+		}
+	}
+
+	count := len(translator.diagnostics)
+	_, t := translator.expression(elem, nil)
+	translator.diagnostics = translator.diagnostics[:count]
+
+	if t == nil {
+		return lwiqa.Integer
+	}
+
+	return defaultType(t)
+}
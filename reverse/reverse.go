@@ -0,0 +1,271 @@
+package reverse
+
+import (
+	"../lwiqa"
+	"../parser"
+	"../printer"
+	"../semantic"
+	"fmt"
+	"sort"
+)
+
+// Construct of LWIQA which can't be written in Go the same way, with the line it's written on.
+// Code is still translated, the diagnostic tells what should be checked in it.
+type Diagnostic struct {
+	Line    lwiqa.Line
+	Message string
+}
+
+func (diagnostic Diagnostic) String() string {
+	return fmt.Sprintf("line %d (Q%s): %s", diagnostic.Line.Number, diagnostic.Line.Index, diagnostic.Message)
+}
+
+// LWIQA program is read and translated back to the syntax tree of Go code, which is written by printer.File.
+// Error is returned if the program can't be read.
+//   Q1.1.5. OUTPUTLN &q&, &r&   ->   fmt.Println(q, r)
+func Translate(text string) (parser.File, []Diagnostic, error) {
+	program, err := lwiqa.Parse(text)
+
+	if err != nil {
+		return parser.File{}, nil, err
+	}
+
+	translator := &translator{program: program, imports: map[string]bool{}, renamed: map[string]bool{}}
+	file := parser.File{Package: parser.Package{Name: "main"}}
+
+	for _, declaration := range program.Declarations {
+		if decl, status := declaration.(*lwiqa.TypeDeclaration); status {                // Type
+			file.Declarations = append(file.Declarations, translator.translateType(decl))
+		}
+	}
+
+	for _, declaration := range program.Declarations {
+		if procedure, status := declaration.(*lwiqa.Procedure); status {                 // Procedure
+			file.Declarations = append(file.Declarations, translator.translateProcedure(procedure))
+		}
+	}
+
+	var imports []string
+
+	for path := range translator.imports {
+		imports = append(imports, path)
+	}
+
+	sort.Strings(imports)
+
+	for _, path := range imports {
+		file.Imports = append(file.Imports, parser.ImportSpec{Path: path})
+	}
+
+	return file, translator.diagnostics, nil
+}
+
+type translator struct {
+	program     *lwiqa.Program
+	imports     map[string]bool // Packages used by the code: fmt, math
+	diagnostics []Diagnostic
+	line        lwiqa.Line      // Line which is translated
+	renamed     map[string]bool // Names which are reported as renamed
+
+	// Procedure which is translated
+	procedure *lwiqa.Procedure
+	variables map[string]lwiqa.Type // Parameters and variables of VAR block
+	declareAt map[int]string        // Variables declared by the statement on the line, instead of VAR
+	blanks    map[string]bool       // Variables which are never read, they're blank in Go
+	outputs   []string              // Output parameters, they're named results in Go
+	names     map[string]bool       // Names used in the procedure, temporaries don't take them
+}
+
+func (translator *translator) report(format string, args ...interface{}) {
+	translator.diagnostics = append(translator.diagnostics, Diagnostic{translator.line, fmt.Sprintf(format, args...)})
+}
+
+// Records become structs, other types are declared with their underlying types:
+//   TYPE &Celsius& = REAL   ->   type Celsius float64
+func (translator *translator) translateType(decl *lwiqa.TypeDeclaration) parser.TypeDeclaration {
+	translator.line = decl.Line
+	t := decl.Type
+
+	// Record declared with RECORD lines has the fields of the declaration
+	if record, status := t.(lwiqa.RecordType); status {
+		t = lwiqa.RecordType{Fields: record.Fields}
+	}
+
+	return parser.TypeDeclaration{Name: translator.identifier(decl.Name), Type: translator.typeExpression(t)}
+}
+
+// Output parameters are results with their names, result of the procedure is the first one:
+//   PROCEDURE &divmod&(&a& : INTEGER, &b& : INTEGER, OUT &q& : INTEGER, OUT &r& : INTEGER)
+//   ->   func divmod(a, b int) (q, r int)
+func (translator *translator) translateProcedure(procedure *lwiqa.Procedure) parser.FuncDeclaration {
+	translator.line = procedure.Line
+	translator.procedure = procedure
+	translator.variables = map[string]lwiqa.Type{}
+	translator.outputs = nil
+	translator.names = map[string]bool{}
+
+	var parameters, results []lwiqa.Parameter
+
+	for _, param := range procedure.Parameters {
+		translator.variables[param.Name] = param.Type
+
+		if param.Output {
+			results = append(results, param)
+			translator.outputs = append(translator.outputs, param.Name)
+		} else {
+			parameters = append(parameters, param)
+		}
+	}
+
+	for _, variable := range procedure.Variables {
+		translator.variables[variable.Name] = variable.Type
+	}
+
+	for name := range translator.variables {
+		translator.names[name] = true
+	}
+
+	for _, declaration := range translator.program.Declarations {
+		if decl, status := declaration.(*lwiqa.Procedure); status {
+			translator.names[decl.Name] = true
+		} else if decl, status := declaration.(*lwiqa.TypeDeclaration); status {
+			translator.names[decl.Name] = true
+		}
+	}
+
+	decl := parser.FuncDeclaration{Name: translator.identifier(procedure.Name)}
+	decl.Parameters = translator.fields(parameters)
+
+	if procedure.Result != nil && len(results) == 0 {
+		decl.Results = []parser.Field{{Type: translator.typeExpression(procedure.Result)}}
+	} else {
+		if procedure.Result != nil {
+			results = append([]lwiqa.Parameter{{Name: "result", Type: procedure.Result}}, results...)
+		}
+
+		decl.Results = translator.fields(results)
+	}
+
+	decl.Body = translator.translateBody(procedure)
+
+	return decl
+}
+
+// Neighbour parameters with the same type are grouped: a, b int
+func (translator *translator) fields(params []lwiqa.Parameter) []parser.Field {
+	var fields []parser.Field
+	previous := ""
+
+	for _, param := range params {
+		t := translator.typeExpression(param.Type)
+		name := translator.identifier(param.Name)
+
+		if str := typeString(t); len(fields) > 0 && str == previous {
+			last := &fields[len(fields)-1]
+			last.Names = append(last.Names, name)
+		} else {
+			fields = append(fields, parser.Field{Names: []parser.Identifier{name}, Type: t})
+			previous = str
+		}
+	}
+
+	return fields
+}
+
+// Names which are Go keywords or predeclared names used by the translation get '_' at the end
+var reserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "int": true, "float64": true, "string": true, "bool": true, "true": true,
+	"false": true, "nil": true, semantic.Len: true, semantic.Cap: true, semantic.Append: true,
+	semantic.Delete: true, semantic.Fmt: true, semantic.Math: true,
+}
+
+func (translator *translator) identifier(name string) parser.Identifier {
+	if reserved[name] {
+		if !translator.renamed[name] {
+			translator.report("%s is reserved in Go, it's renamed to %s_", name, name)
+			translator.renamed[name] = true
+		}
+
+		name += "_"
+	}
+
+	return parser.Identifier{Name: name}
+}
+
+//------------------------------------------------------------------------------------------
+// Types
+//   INTEGER -> int, ARRAY[] OF REAL -> []float64, MAP[STRING] OF &Point& -> map[string]Point
+func (translator *translator) typeExpression(t lwiqa.Type) parser.Expression {
+	switch t := t.(type) {
+	case lwiqa.BasicType:
+		return parser.Identifier{Name: basicTypes[t]}
+	case lwiqa.ArrayType:
+		if t.Length < 0 {
+			return parser.SliceType{Element: translator.typeExpression(t.Element)}
+		}
+
+		return parser.ArrayType{Length: integerLiteral(int64(t.Length)), Element: translator.typeExpression(t.Element)}
+	case lwiqa.MapType:
+		return parser.MapType{Key: translator.typeExpression(t.Key), Value: translator.typeExpression(t.Value)}
+	case lwiqa.RecordType:
+		return parser.StructType{Fields: translator.fields(t.Fields)}
+	case lwiqa.NamedType:
+		return translator.identifier(t.Name)
+	}
+
+	translator.report("type is not known")
+	return parser.Identifier{Name: "int"}
+}
+
+var basicTypes = map[lwiqa.BasicType]string{
+	lwiqa.Integer: "int", lwiqa.Real: "float64", lwiqa.String: "string", lwiqa.Boolean: "bool",
+}
+
+// Types are compared by the way they're written in Go
+func typeString(t parser.Expression) string {
+	return printer.Expression(t)
+}
+
+// Declared type is replaced by its underlying type, nil if it isn't declared
+func (translator *translator) underlying(t lwiqa.Type) lwiqa.Type {
+	seen := map[string]bool{}
+
+	for {
+		named, status := t.(lwiqa.NamedType)
+
+		if !status {
+			return t
+		}
+
+		decl := translator.program.Type(named.Name)
+
+		if decl == nil || seen[named.Name] {
+			return nil
+		}
+
+		seen[named.Name] = true
+		t = decl.Type
+	}
+}
+
+// Names of the same types are the same: &Point& and &Point&
+func (translator *translator) sameType(x lwiqa.Type, y lwiqa.Type) bool {
+	if x == nil || y == nil {
+		return false
+	}
+
+	return typeString(translator.silentType(x)) == typeString(translator.silentType(y))
+}
+
+// Type expression which doesn't report anything, used for comparisons
+func (translator *translator) silentType(t lwiqa.Type) parser.Expression {
+	count := len(translator.diagnostics)
+	expr := translator.typeExpression(t)
+	translator.diagnostics = translator.diagnostics[:count]
+
+	return expr
+}
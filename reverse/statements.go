@@ -0,0 +1,758 @@
+package reverse
+
+import (
+	"../lwiqa"
+	"../parser"
+	"../semantic"
+	"fmt"
+	"strings"
+)
+
+// Variables of VAR block are declared by the statement which assigns them first,
+// if they aren't used before it and outside of its block:
+//   Q1.1.2. &n&
+//   A1.1.2. 3       ->   n := 3
+// Other variables are declared at the start of the body.
+// Variables which are never read aren't declared, Go rejects them, they're blank instead:
+//   Q1.1.3. &divmod&(1, 1, &q&, &r&)   ->   _, _ = divmod(1, 1)
+// Variables assigned without VAR block get the type of the value they're assigned first.
+func (translator *translator) translateBody(procedure *lwiqa.Procedure) parser.BlockStatement {
+	body := procedure.Body
+	translator.declareAt = map[int]string{}
+	translator.blanks = map[string]bool{}
+
+	// Output parameters are results in Go, they start with zero values
+	for len(body) > 0 && translator.isOutputInitialization(body[0]) {
+		body = body[1:]
+	}
+
+	var stmts parser.Statements
+
+	for _, variable := range append(append([]lwiqa.Parameter{}, procedure.Variables...), translator.undeclared(body)...) {
+		translator.line = procedure.Line
+
+		if _, reads := translator.uses(body, variable.Name); reads == 0 {
+			translator.blanks[variable.Name] = true
+		} else if line, status := translator.declaringLine(body, variable.Name); status {
+			translator.declareAt[line] = variable.Name
+		} else {
+			stmts = append(stmts, translator.zeroDeclaration(variable.Name, variable.Type))
+		}
+	}
+
+	return parser.BlockStatement{Statements: append(stmts, translator.translateStatements(body)...)}
+}
+
+// Variables which are assigned, but not declared, in order of their first assignment.
+// Their types are inferred from the first assignment, which should be a simple one:
+//   Q1.1.1. &x&
+//   A1.1.1. 5       ->   x := 5
+func (translator *translator) undeclared(body lwiqa.Statements) []lwiqa.Parameter {
+	var variables []lwiqa.Parameter
+
+	for _, name := range translator.assignedNames(body, nil) {
+		t := translator.inferredType(body, name)
+
+		if t == nil {
+			translator.report("type of %s can't be inferred, it should be declared in VAR block", name)
+			t = lwiqa.Integer
+		}
+
+		translator.variables[name] = t
+		translator.names[name] = true
+		variables = append(variables, lwiqa.Parameter{Name: name, Type: t})
+	}
+
+	return variables
+}
+
+func (translator *translator) assignedNames(stmts lwiqa.Statements, names []string) []string {
+	for _, statement := range stmts {
+		if stmt, status := statement.(lwiqa.IfStatement); status {
+			for _, branch := range stmt.Branches {
+				names = translator.assignedNames(branch.Body, names)
+			}
+
+			continue
+		}
+
+		all, assigned := translator.expressions(statement)
+
+		if _, status := statement.(lwiqa.InputStatement); status {
+			assigned = all
+		}
+
+		for _, expr := range assigned {
+			ident, status := expr.(lwiqa.Identifier)
+
+			if !status || ident.Name == semantic.Blank || contains(names, ident.Name) {
+				continue
+			}
+
+			if _, status := translator.variables[ident.Name]; !status {
+				names = append(names, ident.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Type of the value assigned by the first statement which uses the variable, nil if it's unknown.
+// Diagnostics of the value are reported when it's translated, not here.
+func (translator *translator) inferredType(body lwiqa.Statements, name string) lwiqa.Type {
+	stmts, i, status := translator.firstUse(body, name)
+
+	if !status {
+		return nil
+	}
+
+	translator.line = stmts[i].Source()
+
+	if stmt, status := stmts[i].(lwiqa.DeclareStatement); status && stmt.Target.Name == name {
+		return stmt.Type
+	}
+
+	stmt, status := stmts[i].(lwiqa.AssignStatement)
+
+	if !status || len(stmt.Targets) != 1 || len(stmt.Values) != 1 || count(stmt.Values[0], name) > 0 {
+		return nil
+	}
+
+	if target, status := stmt.Targets[0].(lwiqa.Identifier); !status || target.Name != name {
+		return nil
+	}
+
+	diagnostics := translator.diagnostics
+	_, t := translator.expression(stmt.Values[0], nil)
+	translator.diagnostics = diagnostics
+
+	return defaultType(t)
+}
+
+// Zero value assigned to output parameter:
+//   Q1.2.1. &q&
+//   A1.2.1. 0
+func (translator *translator) isOutputInitialization(statement lwiqa.Statement) bool {
+	stmt, status := statement.(lwiqa.AssignStatement)
+
+	if !status || !stmt.Answer || len(stmt.Targets) != 1 {
+		return false
+	}
+
+	target, status := stmt.Targets[0].(lwiqa.Identifier)
+
+	if !status || !isZeroLiteral(stmt.Values[0]) {
+		return false
+	}
+
+	for _, name := range translator.outputs {
+		if name == target.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Maps are nil, the same way LWIQA declares them: var counts map[string]int
+func (translator *translator) zeroDeclaration(name string, t lwiqa.Type) parser.Statement {
+	return parser.VarStatement{Identifier: translator.identifier(name), Type: translator.typeExpression(t)}
+}
+
+// Line of the statement which can declare the variable, it's the first one which uses the variable
+// and all other uses are after it in the same block
+func (translator *translator) declaringLine(body lwiqa.Statements, name string) (int, bool) {
+	stmts, i, status := translator.firstUse(body, name)
+
+	if !status {
+		return 0, false
+	}
+
+	if stmt, status := stmts[i].(lwiqa.AssignStatement); status {                     // Assign
+		target, status := stmt.Targets[0].(lwiqa.Identifier)
+
+		if len(stmt.Targets) != 1 || len(stmt.Values) != 1 || !status || target.Name != name ||
+			count(stmt.Values[0], name) > 0 {
+			return 0, false
+		}
+	} else if stmt, status := stmts[i].(lwiqa.DeclareStatement); status {             // Array or map
+		if stmt.Target.Name != name || stmt.Value != nil && count(stmt.Value, name) > 0 {
+			return 0, false
+		}
+	} else {
+		return 0, false
+	}
+
+	total, _ := translator.uses(body, name)
+	after, _ := translator.uses(stmts[i:], name)
+
+	return stmts[i].Source().Number, total == after
+}
+
+// Block with the first statement which uses the variable and index of the statement in it
+func (translator *translator) firstUse(stmts lwiqa.Statements, name string) (lwiqa.Statements, int, bool) {
+	for i, statement := range stmts {
+		if stmt, status := statement.(lwiqa.IfStatement); status {
+			for _, branch := range stmt.Branches {
+				if branch.Condition != nil && count(branch.Condition, name) > 0 {
+					return stmts, i, true
+				}
+
+				if block, j, status := translator.firstUse(branch.Body, name); status {
+					return block, j, true
+				}
+			}
+		} else if mentions, _ := translator.uses(lwiqa.Statements{statement}, name); mentions > 0 {
+			return stmts, i, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+// Uses of the variable in the statements, reads don't count assignments to the variable itself
+func (translator *translator) uses(stmts lwiqa.Statements, name string) (mentions int, reads int) {
+	for _, statement := range stmts {
+		if stmt, status := statement.(lwiqa.IfStatement); status {
+			for _, branch := range stmt.Branches {
+				if branch.Condition != nil {
+					n := count(branch.Condition, name)
+					mentions, reads = mentions+n, reads+n
+				}
+
+				m, r := translator.uses(branch.Body, name)
+				mentions, reads = mentions+m, reads+r
+			}
+
+			continue
+		}
+
+		all, assigned := translator.expressions(statement)
+
+		for _, expr := range all {
+			n := count(expr, name)
+			mentions, reads = mentions+n, reads+n
+		}
+
+		for _, expr := range assigned {
+			if ident, status := expr.(lwiqa.Identifier); status && ident.Name == name {
+				reads--
+			}
+		}
+	}
+
+	return
+}
+
+// Expressions of the statement and the ones which are only assigned by it.
+// Results of procedures and LOOKUP are assigned, as they're targets in Go.
+func (translator *translator) expressions(statement lwiqa.Statement) (all lwiqa.Expressions, assigned lwiqa.Expressions) {
+	switch stmt := statement.(type) {
+	case lwiqa.AssignStatement:
+		return append(append(all, stmt.Targets...), stmt.Values...), stmt.Targets
+	case lwiqa.DeclareStatement:
+		all = lwiqa.Expressions{stmt.Target}
+
+		if stmt.Value != nil {
+			all = append(all, stmt.Value)
+		}
+
+		return all, lwiqa.Expressions{stmt.Target}
+	case lwiqa.CallStatement:
+		if !stmt.Call.Function.Marked && strings.ToUpper(stmt.Call.Function.Name) == "LOOKUP" &&
+			len(stmt.Call.Arguments) == 4 {
+			assigned = stmt.Call.Arguments[2:]
+		}
+
+		if procedure := translator.program.Procedure(stmt.Call.Function.Name); procedure != nil && stmt.Call.Function.Marked {
+			for i, param := range procedure.Parameters {
+				if param.Output && i < len(stmt.Call.Arguments) {
+					assigned = append(assigned, stmt.Call.Arguments[i])
+				}
+			}
+		}
+
+		return stmt.Call.Arguments, assigned
+	case lwiqa.OutputStatement:
+		return stmt.Items, nil
+	case lwiqa.InputStatement:
+		return stmt.Targets, nil
+	case lwiqa.ReturnStatement:
+		if stmt.Value != nil {
+			return lwiqa.Expressions{stmt.Value}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Times the variable is used in the expression, names of functions and fields aren't counted
+func count(expression lwiqa.Expression, name string) int {
+	switch expr := expression.(type) {
+	case lwiqa.Identifier:
+		if expr.Name == name {
+			return 1
+		}
+	case lwiqa.UnaryExpression:
+		return count(expr.Operand, name)
+	case lwiqa.BinaryExpression:
+		return count(expr.LeftOperand, name) + count(expr.RightOperand, name)
+	case lwiqa.IndexExpression:
+		return count(expr.Expression, name) + count(expr.Index, name)
+	case lwiqa.SelectorExpression:
+		return count(expr.Expression, name)
+	case lwiqa.CallExpression:
+		n := 0
+
+		for _, arg := range expr.Arguments {
+			// Keys of records are fields
+			if pair, status := arg.(lwiqa.KeyValueExpression); status {
+				arg = pair.Value
+			}
+
+			n += count(arg, name)
+		}
+
+		return n
+	case lwiqa.KeyValueExpression:
+		return count(expr.Key, name) + count(expr.Value, name)
+	case lwiqa.ArrayLiteral:
+		return countAll(expr.Elements, name)
+	case lwiqa.MapLiteral:
+		return countAll(expr.Elements, name)
+	}
+
+	return 0
+}
+
+func countAll(exprs lwiqa.Expressions, name string) int {
+	n := 0
+
+	for _, expr := range exprs {
+		n += count(expr, name)
+	}
+
+	return n
+}
+
+//------------------------------------------------------------------------------------------
+// Statements
+func (translator *translator) translateStatements(stmts lwiqa.Statements) parser.Statements {
+	var result parser.Statements
+
+	for _, stmt := range stmts {
+		translator.line = stmt.Source()
+		result = append(result, translator.translateStatement(stmt)...)
+	}
+
+	return result
+}
+
+func (translator *translator) translateStatement(statement lwiqa.Statement) parser.Statements {
+	if stmt, status := statement.(lwiqa.AssignStatement); status {                   // Assign
+		return translator.translateAssignStatement(stmt)
+	} else if stmt, status := statement.(lwiqa.DeclareStatement); status {          // Array or map
+		return parser.Statements{translator.translateDeclareStatement(stmt)}
+	} else if stmt, status := statement.(lwiqa.CallStatement); status {             // Call
+		return parser.Statements{translator.translateCallStatement(stmt)}
+	} else if stmt, status := statement.(lwiqa.OutputStatement); status {           // Output
+		return parser.Statements{translator.translateOutputStatement(stmt)}
+	} else if stmt, status := statement.(lwiqa.InputStatement); status {            // Input
+		translator.imports[semantic.Fmt] = true
+		var args parser.Expressions
+
+		for _, target := range stmt.Targets {
+			expr, _ := translator.expression(target, nil)
+			args = append(args, parser.UnaryExpression{Operator: "&", Operand: expr})
+		}
+
+		return parser.Statements{parser.ExpressionStatement{Expression: packageCall(semantic.Fmt, semantic.Scan, args)}}
+	} else if stmt, status := statement.(lwiqa.ReturnStatement); status {           // Return
+		return parser.Statements{translator.translateReturnStatement(stmt)}
+	} else if stmt, status := statement.(lwiqa.BranchStatement); status {           // Branch
+		translator.report("%s has nothing to leave in Go, it's left out", strings.ToLower(stmt.Keyword))
+		return nil
+	} else if stmt, status := statement.(lwiqa.IfStatement); status {               // If
+		return parser.Statements{translator.translateBranches(stmt.Branches)}
+	}
+
+	translator.report("statement can't be translated")
+	return nil
+}
+
+// Values are assigned one by one, the ones which use variables assigned before them
+// are kept in temporaries first:
+//   &a&, &b& := &b&, &a&   ->   tmp := a; a = b; b = tmp
+func (translator *translator) translateAssignStatement(stmt lwiqa.AssignStatement) parser.Statements {
+	if len(stmt.Targets) != len(stmt.Values) {
+		translator.report("%d values are assigned to %d targets", len(stmt.Values), len(stmt.Targets))
+		return nil
+	}
+
+	if len(stmt.Targets) == 1 {
+		return parser.Statements{translator.translateAssignment(stmt.Targets[0], stmt.Values[0])}
+	}
+
+	var temporaries, assignments parser.Statements
+	var assigned []string
+
+	for i, target := range stmt.Targets {
+		value := stmt.Values[i]
+
+		if usesAny(value, assigned) {
+			name := translator.temporary()
+			expr, _ := translator.expression(value, nil)
+			temporaries = append(temporaries, parser.AssignStatement{Targets: parser.Expressions{parser.Identifier{Name: name}},
+				Operator: ":=", Expression: expr})
+			value = lwiqa.Identifier{Name: name}
+		}
+
+		assignments = append(assignments, translator.translateAssignment(target, value))
+		assigned = append(assigned, rootName(target))
+	}
+
+	return append(temporaries, assignments...)
+}
+
+// CONTAINS is the second result of map index: &found& := CONTAINS(&m&, &k&)   ->   _, found = m[k]
+func (translator *translator) translateAssignment(target lwiqa.Expression, value lwiqa.Expression) parser.Statement {
+	name := translator.declareAt[translator.line.Number]
+	operator := "="
+
+	if ident, status := target.(lwiqa.Identifier); !status || ident.Name != name {
+		name = ""
+	}
+
+	if name != "" {
+		operator = ":="
+	}
+
+	if call, status := value.(lwiqa.CallExpression); status && builtin(call) == "CONTAINS" && len(call.Arguments) == 2 {
+		expr, _ := translator.expression(target, nil)
+		index := translator.indexExpression(call.Arguments[0], call.Arguments[1])
+
+		return parser.AssignStatement{Targets: parser.Expressions{parser.Identifier{Name: semantic.Blank}, expr},
+			Operator: operator, Expression: index}
+	}
+
+	if name != "" {
+		return translator.declaration(name, value)
+	}
+
+	targetExpr, t := translator.expression(target, nil)
+	expr, _ := translator.expression(value, t)
+
+	return parser.AssignStatement{Targets: parser.Expressions{targetExpr}, Operator: operator, Expression: expr}
+}
+
+// Variable is declared with := if the value has its type, zero value is declared with var:
+//   n := 3, var total float64, var c Celsius = 1.5
+func (translator *translator) declaration(name string, value lwiqa.Expression) parser.Statement {
+	t := translator.variables[name]
+	ident := translator.identifier(name)
+
+	if isZeroLiteral(value) {
+		if _, status := translator.kind(t).(lwiqa.BasicType); status {
+			return parser.VarStatement{Identifier: ident, Type: translator.typeExpression(t)}
+		}
+	}
+
+	// Integer constant assigned to real variable keeps the point: 3.0
+	if lit, status := value.(lwiqa.Literal); status && translator.kind(t) == lwiqa.Real {
+		if i, status := lit.Value.(int64); status {
+			value = lwiqa.Literal{Value: float64(i)}
+		}
+	}
+
+	expr, valueType := translator.expression(value, t)
+
+	if translator.sameType(defaultType(valueType), t) {
+		return parser.AssignStatement{Targets: parser.Expressions{ident}, Operator: ":=", Expression: expr}
+	}
+
+	return parser.VarStatement{Identifier: ident, Type: translator.typeExpression(t), Expression: expr}
+}
+
+// Type of variable from VAR block is used for its literal, if there is one
+func (translator *translator) translateDeclareStatement(stmt lwiqa.DeclareStatement) parser.Statement {
+	name := stmt.Target.Name
+	t, status := translator.variables[name]
+
+	if !status {
+		t = stmt.Type
+	}
+
+	if stmt.Value == nil {
+		if translator.declareAt[stmt.Line.Number] == name {
+			return translator.zeroDeclaration(name, t)
+		}
+
+		target, _ := translator.expression(stmt.Target, nil)
+
+		return parser.AssignStatement{Targets: parser.Expressions{target}, Operator: "=",
+			Expression: parser.CompositeLiteral{Type: translator.typeExpression(t)}}
+	}
+
+	operator := "="
+
+	if translator.declareAt[stmt.Line.Number] == name {
+		operator = ":="
+	}
+
+	target, _ := translator.expression(stmt.Target, nil)
+	expr, _ := translator.expression(stmt.Value, t)
+
+	return parser.AssignStatement{Targets: parser.Expressions{target}, Operator: operator, Expression: expr}
+}
+
+// Output parameters are results in Go: &divmod&(17, 5, &q&, &r&)   ->   q, r = divmod(17, 5).
+// LOOKUP gives the element of map and whether it's found: LOOKUP(&m&, &k&, &v&, &ok&)   ->   v, ok = m[k]
+func (translator *translator) translateCallStatement(stmt lwiqa.CallStatement) parser.Statement {
+	call := stmt.Call
+
+	if procedure := translator.program.Procedure(call.Function.Name); procedure != nil && call.Function.Marked {
+		if len(call.Arguments) != len(procedure.Parameters) {
+			translator.report("%s takes %d arguments, got %d", procedure.Name, len(procedure.Parameters), len(call.Arguments))
+		}
+
+		var args, targets parser.Expressions
+
+		for i, param := range procedure.Parameters {
+			if i >= len(call.Arguments) {
+				break
+			}
+
+			if param.Output {
+				expr, _ := translator.expression(call.Arguments[i], nil)
+				targets = append(targets, expr)
+			} else {
+				expr, _ := translator.expression(call.Arguments[i], param.Type)
+				args = append(args, expr)
+			}
+		}
+
+		callExpr := parser.CallExpression{Function: translator.identifier(procedure.Name), Arguments: args}
+
+		if len(targets) == 0 {
+			return parser.ExpressionStatement{Expression: callExpr}
+		}
+
+		if procedure.Result != nil {
+			targets = append(parser.Expressions{parser.Identifier{Name: semantic.Blank}}, targets...)
+		}
+
+		return parser.AssignStatement{Targets: targets, Operator: "=", Expression: callExpr}
+	}
+
+	switch builtin(call) {
+	case "LOOKUP":
+		if len(call.Arguments) == 4 {
+			value, _ := translator.expression(call.Arguments[2], nil)
+			found, _ := translator.expression(call.Arguments[3], nil)
+
+			return parser.AssignStatement{Targets: parser.Expressions{value, found}, Operator: "=",
+				Expression: translator.indexExpression(call.Arguments[0], call.Arguments[1])}
+		}
+	case "DELETE":
+	default:
+		translator.report("value of %s isn't used, Go rejects it", call.Function.Name)
+	}
+
+	expr, _ := translator.expression(call, nil)
+
+	return parser.ExpressionStatement{Expression: expr}
+}
+
+// Value is returned with output parameters, as they're results in Go
+func (translator *translator) translateReturnStatement(stmt lwiqa.ReturnStatement) parser.Statement {
+	if stmt.Value == nil {
+		return parser.ReturnStatement{}
+	}
+
+	if translator.procedure.Result == nil {
+		translator.report("procedure %s has no result, but a value is returned", translator.procedure.Name)
+	}
+
+	expr, _ := translator.expression(stmt.Value, translator.procedure.Result)
+	results := parser.Expressions{expr}
+
+	for _, name := range translator.outputs {
+		results = append(results, translator.identifier(name))
+	}
+
+	return parser.ReturnStatement{Results: results}
+}
+
+// ELSE IF becomes else block with the single if statement, which is written as else if
+func (translator *translator) translateBranches(branches []lwiqa.Branch) parser.IfStatement {
+	branch := branches[0]
+	translator.line = branch.Line
+	condition, _ := translator.expression(branch.Condition, lwiqa.Boolean)
+	stmt := parser.IfStatement{Condition: condition, IfBody: parser.BlockStatement{Statements: translator.translateStatements(branch.Body)}}
+
+	if len(branches) == 1 {
+		return stmt
+	}
+
+	if branches[1].Condition == nil {
+		stmt.ElseBody.Statements = translator.translateStatements(branches[1].Body)
+	} else {
+		stmt.ElseBody.Statements = parser.Statements{translator.translateBranches(branches[1:])}
+	}
+
+	return stmt
+}
+
+// Temporary variable with the name which isn't used in the procedure: tmp, tmp1, tmp2
+func (translator *translator) temporary() string {
+	name := "tmp"
+
+	for i := 1; translator.names[name]; i++ {
+		name = fmt.Sprint("tmp", i)
+	}
+
+	translator.names[name] = true
+
+	return name
+}
+
+// Variable which is assigned by the target: &a&[&i&].&X&   ->   a
+func rootName(target lwiqa.Expression) string {
+	switch expr := target.(type) {
+	case lwiqa.Identifier:
+		return expr.Name
+	case lwiqa.IndexExpression:
+		return rootName(expr.Expression)
+	case lwiqa.SelectorExpression:
+		return rootName(expr.Expression)
+	}
+
+	return ""
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func usesAny(expr lwiqa.Expression, names []string) bool {
+	for _, name := range names {
+		if name != "" && count(expr, name) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isZeroLiteral(expr lwiqa.Expression) bool {
+	lit, status := expr.(lwiqa.Literal)
+
+	if !status {
+		return false
+	}
+
+	switch value := lit.Value.(type) {
+	case int64:
+		return value == 0
+	case float64:
+		return value == 0
+	case string:
+		return value == ""
+	case bool:
+		return !value
+	}
+
+	return false
+}
+
+// OUTPUT is written with fmt.Print, OUTPUTLN with fmt.Println.
+// Output with FIXED or empty strings between values is written with fmt.Printf,
+// spaces are added where fmt.Print adds them, between values which aren't strings:
+//   OUTPUT &name&, " costs ", FIXED(&total&, 2), "\n"   ->   fmt.Printf("%s costs %.2f\n", name, total)
+//   OUTPUT &n&, "", &n& + 1   ->   fmt.Printf("%d%d", n, n+1)
+func (translator *translator) translateOutputStatement(stmt lwiqa.OutputStatement) parser.Statement {
+	translator.imports[semantic.Fmt] = true
+	formatted := false
+
+	for _, item := range stmt.Items {
+		if call, status := item.(lwiqa.CallExpression); status && builtin(call) == "FIXED" {
+			formatted = true
+		} else if lit, status := item.(lwiqa.Literal); status && lit.Value == "" && !stmt.Newline {
+			formatted = true
+		}
+	}
+
+	if !formatted {
+		name := semantic.Print
+
+		if stmt.Newline {
+			name = semantic.Println
+		}
+
+		var args parser.Expressions
+
+		for _, item := range stmt.Items {
+			expr, _ := translator.expression(item, nil)
+			args = append(args, expr)
+		}
+
+		return parser.ExpressionStatement{Expression: packageCall(semantic.Fmt, name, args)}
+	}
+
+	var format strings.Builder
+	var args parser.Expressions
+	previousString := true
+
+	for i, item := range stmt.Items {
+		var verb string
+		var values parser.Expressions
+		isString := true
+
+		if call, status := item.(lwiqa.CallExpression); status && builtin(call) == "FIXED" {
+			verb, values = translator.fixed(call)
+		} else if lit, status := item.(lwiqa.Literal); status {
+			verb = strings.Replace(fmt.Sprint(lit.Value), "%", "%%", -1)
+			_, isString = lit.Value.(string)
+		} else {
+			expr, t := translator.expression(item, nil)
+			values = parser.Expressions{expr}
+
+			switch translator.kind(t) {
+			case lwiqa.Integer:
+				verb, isString = "%d", false
+			case lwiqa.String:
+				verb = "%s"
+			case lwiqa.Boolean:
+				verb, isString = "%t", false
+			case nil:
+				translator.report("type of output value isn't known, it's written with %%v")
+				fallthrough
+			default:
+				verb, isString = "%v", false
+			}
+		}
+
+		if stmt.Newline && i > 0 || !isString && !previousString {
+			format.WriteString(" ")
+		}
+
+		format.WriteString(verb)
+		args = append(args, values...)
+		previousString = isString
+	}
+
+	if stmt.Newline {
+		format.WriteString("\n")
+	}
+
+	args = append(parser.Expressions{stringLiteral(format.String())}, args...)
+
+	return parser.ExpressionStatement{Expression: packageCall(semantic.Fmt, semantic.Printf, args)}
+}
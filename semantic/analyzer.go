@@ -1,26 +1,71 @@
 package semantic
 
-import "../parser"
+import (
+	"../parser"
+	"fmt"
+)
 
 type Analyzer struct {
 	variables  Variables
+	types      map[string]Type
+	imports    map[string]bool // Whether imported package is used
+	functions  map[string]*Function
+	current    *Function // Function which body is analyzed
+	callGraph  *CallGraph
+	info       *TypeInfo
 	syntaxTree parser.File
 	errors     parser.Errors
 }
 
 func NewAnalyzer(tree parser.File) *Analyzer {
-	return &Analyzer{Variables{}, tree, parser.Errors{}}
+	types := map[string]Type{}
+
+	for name, predeclared := range predeclaredTypes {
+		types[name] = predeclared
+	}
+
+	return &Analyzer{Variables{}, types, map[string]bool{}, map[string]*Function{},
+		nil, NewCallGraph(), NewTypeInfo(), tree, parser.Errors{}}
 }
 
+// Variables of all functions are returned together by their scopes,
+// each of them knows the function which declares it
 func (analyzer *Analyzer) Analyze() (Variables, parser.Errors) {
-	for _, function := range analyzer.syntaxTree.Declarations {
-		stmt := function.(parser.FuncDeclaration).Body
-		analyzer.traverseStatement(stmt, 0)
+	analyzer.declareImports()
+	analyzer.declareTypes()
+	analyzer.declareFunctions()
+
+	variables := Variables{}
+	analyzed := map[string]bool{}
+
+	for _, declaration := range analyzer.syntaxTree.Declarations {
+		// Functions declared again are already reported
+		if function, status := declaration.(parser.FuncDeclaration); status && !analyzed[function.Name.Name] {
+			analyzed[function.Name.Name] = true
+			analyzer.analyzeFunction(function)
+
+			for scope, vars := range analyzer.variables {
+				variables[scope] = append(variables[scope], vars...)
+			}
+		}
 	}
 
+	analyzer.variables = variables
+	analyzer.validateImportsUsage()
+
 	return analyzer.variables, analyzer.errors
 }
 
+// Calls between functions, available after analysis
+func (analyzer *Analyzer) CallGraph() *CallGraph {
+	return analyzer.callGraph
+}
+
+// Types of expressions and variables of identifiers, available after analysis
+func (analyzer *Analyzer) TypeInfo() *TypeInfo {
+	return analyzer.info
+}
+
 func (analyzer *Analyzer) traverseStatement(statement parser.Statement, scope Scope) {
 	if stmt, status := statement.(parser.SwitchStatement); status {         // Switch
 		analyzer.getExpressionType(stmt.Expression, scope) // Validating condition
@@ -28,8 +73,18 @@ func (analyzer *Analyzer) traverseStatement(statement parser.Statement, scope Sc
 	} else if stmt, status := statement.(parser.CaseStatement); status {    // Case
 		analyzer.getExpressionType(stmt.Expression, scope) // Validating condition
 		analyzer.traverseStatement(stmt.Body, scope+1)
+
+		if hasNestedBranch(stmt.Body, false) {
+			analyzer.errors = append(analyzer.errors, newNestedBranchError())
+		}
 	} else if stmt, status := statement.(parser.AssignStatement); status {  // Assign
 		analyzer.validateAssignStatement(stmt, scope)
+	} else if stmt, status := statement.(parser.VarStatement); status {     // Var
+		analyzer.validateVarStatement(stmt, scope)
+	} else if stmt, status := statement.(parser.ExpressionStatement); status { // Call
+		analyzer.validateExpressionStatement(stmt, scope)
+	} else if stmt, status := statement.(parser.ReturnStatement); status {  // Return
+		analyzer.validateReturnStatement(stmt, scope)
 	} else if stmt, status := statement.(parser.BlockStatement); status {   // Block
 		analyzer.traverseStatement(stmt.Statements, scope)
 	} else if stmts, status := statement.(parser.CaseStatements); status {  // Cases
@@ -41,8 +96,8 @@ func (analyzer *Analyzer) traverseStatement(statement parser.Statement, scope Sc
 			analyzer.traverseStatement(stmt, scope)
 		}
 	} else if stmt, status := statement.(parser.IfStatement); status {      // If
-		if analyzer.getExpressionType(stmt.Condition, scope) != Bool {
-			analyzer.errors = append(analyzer.errors, newNonBoolError())
+		if underlying(analyzer.getExpressionType(stmt.Condition, scope)) != Bool {
+			analyzer.errors = append(analyzer.errors, newNonBoolError(stmt.Condition))
 		}
 
 		analyzer.traverseStatement(stmt.IfBody, scope+1)
@@ -51,24 +106,245 @@ func (analyzer *Analyzer) traverseStatement(statement parser.Statement, scope Sc
 }
 
 func (analyzer *Analyzer) validateAssignStatement(assign parser.AssignStatement, scope Scope) {
-	identifier := assign.Identifier
+	if _, status := assign.Expression.(parser.Expressions); status || len(assign.Targets) > 1 {
+		analyzer.validateTupleAssignStatement(assign, scope)
+		return
+	}
+
+	target := assign.Targets[0]
+	identifier, isIdentifier := target.(parser.Identifier)
 
 	if assign.Operator == parser.GetType(parser.Assign) {
+		if !isIdentifier { // Assigning to element or field: a[i] = 2, p.X = 2
+			analyzer.assignElement(target, analyzer.getValueType(assign.Expression, scope), assign.Expression, scope)
+			return
+		}
+
+		if identifier.Name == Blank {
+			analyzer.getValueType(assign.Expression, scope)
+			return
+		}
+
 		variable := analyzer.findVariableSomewhere(identifier, scope)
 
 		if variable != nil {
-			analyzer.assignVariable(variable, assign.Expression, scope)
+			analyzer.assignVariable(variable, analyzer.getValueType(assign.Expression, scope), assign.Expression)
 		}
 	} else if assign.Operator == parser.GetType(parser.Define) {
+		if !isIdentifier {
+			analyzer.errors = append(analyzer.errors, newNonNameDefineError())
+			return
+		}
+
+		if identifier.Name == Blank {
+			analyzer.errors = append(analyzer.errors, newNoNewVariablesError())
+			return
+		}
+
 		if analyzer.findVariableAtScope(identifier, scope) != nil {
 			analyzer.errors = append(analyzer.errors, newAlreadyDefinedError(identifier))
 			return
 		}
 
-		analyzer.defineVariable(assign, scope)
+		analyzer.defineValue(identifier, analyzer.getValueType(assign.Expression, scope), assign.Expression, scope)
 	}
 }
 
+// Constant gets its default type, its value should fit into it: a := 1 << 70
+func (analyzer *Analyzer) defineValue(identifier parser.Identifier, valueType Type, value parser.Expression, scope Scope) {
+	if x := ConstantValue(value); x != nil && valueType != Undefined && !isRepresentable(x, valueType) {
+		analyzer.errors = append(analyzer.errors, newOverflowError(value, valueType))
+	}
+
+	analyzer.defineVariable(identifier, valueType, scope)
+}
+
+// Several targets get values from a single expression: v, ok := m[k],
+// or from the same number of values: a, b = b, a.
+// When defining, at least one of the targets should be a new variable,
+// others are just assigned.
+func (analyzer *Analyzer) validateTupleAssignStatement(assign parser.AssignStatement, scope Scope) {
+	valueTypes, values := analyzer.getTupleValues(assign.Expression, len(assign.Targets), scope)
+
+	if valueTypes == nil {
+		return
+	}
+
+	if assign.Operator == parser.GetType(parser.Assign) {
+		for i, target := range assign.Targets {
+			if identifier, status := target.(parser.Identifier); !status {
+				analyzer.assignElement(target, valueTypes[i], values[i], scope)
+			} else if identifier.Name != Blank {
+				if variable := analyzer.findVariableSomewhere(identifier, scope); variable != nil {
+					analyzer.assignVariable(variable, valueTypes[i], values[i])
+				}
+			}
+		}
+
+		return
+	}
+
+	defined := false
+
+	for i, target := range assign.Targets {
+		identifier, status := target.(parser.Identifier)
+
+		if !status {
+			analyzer.errors = append(analyzer.errors, newNonNameDefineError())
+			return
+		}
+
+		if identifier.Name == Blank {
+			continue
+		}
+
+		if variable := analyzer.findVariableAtScope(identifier, scope); variable != nil {
+			analyzer.info.addSymbol(identifier, variable)
+			analyzer.assignVariable(variable, valueTypes[i], values[i])
+			continue
+		}
+
+		analyzer.defineValue(identifier, valueTypes[i], values[i], scope)
+		defined = true
+	}
+
+	if !defined {
+		analyzer.errors = append(analyzer.errors, newNoNewVariablesError())
+	}
+}
+
+// Types of values for several targets and the values themselves, if they're given one by one.
+// Values are nil if they come from a single expression.
+func (analyzer *Analyzer) getTupleValues(
+	expression parser.Expression,
+	count int,
+	scope Scope,
+) ([]Type, parser.Expressions) {
+	values, status := expression.(parser.Expressions)
+
+	if !status {
+		return analyzer.getTupleTypes(expression, count, scope), make(parser.Expressions, count)
+	}
+
+	var valueTypes []Type
+
+	for _, value := range values {
+		valueTypes = append(valueTypes, analyzer.getValueType(value, scope))
+	}
+
+	if len(values) != count {
+		analyzer.errors = append(analyzer.errors, newAssignCountError(count, len(values)))
+		return nil, nil
+	}
+
+	return valueTypes, values
+}
+
+// Types of values for several targets.
+// Map index gives two values: element and whether it was found,
+// call of function with several results gives all of them.
+// Returns nil if expression can't be assigned to that many targets.
+func (analyzer *Analyzer) getTupleTypes(expression parser.Expression, count int, scope Scope) []Type {
+	if call, status := expression.(parser.CallExpression); status {
+		callType := analyzer.getExpressionType(call, scope)
+
+		if tuple, status := callType.(*Tuple); status {
+			if len(tuple.Types) == count {
+				return tuple.Types
+			}
+
+			analyzer.errors = append(analyzer.errors, newAssignCountError(count, len(tuple.Types)))
+			return nil
+		}
+
+		if callType == Void {
+			analyzer.errors = append(analyzer.errors, newVoidValueError())
+		} else if callType != Undefined {
+			analyzer.errors = append(analyzer.errors, newAssignCountError(count, 1))
+		}
+
+		return nil
+	}
+
+	if index, status := expression.(parser.IndexExpression); status && count == 2 {
+		containerType := analyzer.getExpressionType(index.Expression, scope)
+
+		if isMap(containerType) {
+			return []Type{analyzer.getMapValueType(containerType, index.Index, scope), Bool}
+		}
+
+		if containerType != Undefined {
+			analyzer.errors = append(analyzer.errors, newAssignCountError(count, 1))
+		}
+
+		return nil
+	}
+
+	if analyzer.getValueType(expression, scope) != Undefined {
+		analyzer.errors = append(analyzer.errors, newAssignCountError(count, 1))
+	}
+
+	return nil
+}
+
+// Same as getExpressionType, but calls without result can't be used as values
+func (analyzer *Analyzer) getValueType(expression parser.Expression, scope Scope) Type {
+	exprType := analyzer.getExpressionType(expression, scope)
+
+	if exprType == Void {
+		analyzer.errors = append(analyzer.errors, newVoidValueError())
+		return Undefined
+	}
+
+	if _, status := exprType.(*Tuple); status {
+		analyzer.errors = append(analyzer.errors, newMultipleValueError())
+		return Undefined
+	}
+
+	return exprType
+}
+
+// Only calls can be used as statements and their results can be omitted,
+// except for builtins which don't have side effects.
+func (analyzer *Analyzer) validateExpressionStatement(stmt parser.ExpressionStatement, scope Scope) {
+	analyzer.getExpressionType(stmt.Expression, scope)
+
+	if call, status := stmt.Expression.(parser.CallExpression); status {
+		if ident, status := call.Function.(parser.Identifier); status && isPure(ident.Name) {
+			analyzer.errors = append(analyzer.errors, newUnusedResultError(ident.Name))
+		} else if selector, status := call.Function.(parser.SelectorExpression); status {
+			pkg, status := selector.Expression.(parser.Identifier)
+
+			if status && isPurePackageFunction(pkg.Name, selector.Selector.Name) {
+				analyzer.errors = append(analyzer.errors, newUnusedResultError(pkg.Name+"."+selector.Selector.Name))
+			}
+		}
+	}
+}
+
+func (analyzer *Analyzer) validateVarStatement(stmt parser.VarStatement, scope Scope) {
+	if analyzer.findVariableAtScope(stmt.Identifier, scope) != nil {
+		analyzer.errors = append(analyzer.errors, newAlreadyDefinedError(stmt.Identifier))
+		return
+	}
+
+	if stmt.Type == nil { // Type is inferred: var a = 2
+		analyzer.defineVariable(stmt.Identifier, analyzer.getValueType(stmt.Expression, scope), scope)
+		return
+	}
+
+	varType := analyzer.resolveType(stmt.Type)
+
+	// Variable is not visible in its own initializer,
+	// so it's defined only after checking the expression.
+	if stmt.Expression != nil && varType != Undefined {
+		variable := &Variable{stmt.Identifier.Name, varType, analyzer.current.Name}
+		analyzer.assignVariable(variable, analyzer.getValueType(stmt.Expression, scope), stmt.Expression)
+	}
+
+	analyzer.defineVariable(stmt.Identifier, varType, scope)
+}
+
 // Fairly bad name for a function which finds
 // vars in scopes which are less or equal than current
 func (analyzer *Analyzer) findVariableSomewhere(ident parser.Identifier, scope Scope) *Variable {
@@ -86,6 +362,7 @@ func (analyzer *Analyzer) findVariableSomewhere(ident parser.Identifier, scope S
 		variable := analyzer.findVariableAtScope(ident, otherScope)
 
 		if variable != nil {
+			analyzer.info.addSymbol(ident, variable)
 			return variable
 		}
 	}
@@ -104,34 +381,80 @@ func (analyzer *Analyzer) findVariableAtScope(ident parser.Identifier, scope Sco
 	return nil
 }
 
-func (analyzer *Analyzer) defineVariable(assign parser.AssignStatement, scope Scope) {
-	identifier := assign.Identifier.Name
-	varType := analyzer.getExpressionType(assign.Expression, scope)
-
-	variable := &Variable{identifier, varType}
+func (analyzer *Analyzer) defineVariable(identifier parser.Identifier, varType Type, scope Scope) {
+	variable := &Variable{identifier.Name, varType, analyzer.current.Name}
 	analyzer.variables[scope] = append(analyzer.variables[scope], variable)
+	analyzer.info.addSymbol(identifier, variable)
 }
 
-func (analyzer *Analyzer) assignVariable(
-	variable *Variable,
-	expression parser.Expression,
-	scope Scope,
-) bool {
-	varType := analyzer.getExpressionType(expression, scope)
+// Value is nil if it's one of several values: v, ok = m[k]
+func (analyzer *Analyzer) assignVariable(variable *Variable, valueType Type, value parser.Expression) bool {
+	if !analyzer.isAssignable(variable.Type, valueType, value) {
+		if Identical(variable.Type, valueType) {
+			analyzer.errors = append(analyzer.errors, newOverflowError(value, variable.Type))
+			return false
+		}
 
-	if variable.Type != varType {
-		analyzer.errors = append(analyzer.errors, newAssignError(variable, varType))
+		analyzer.errors = append(analyzer.errors, newAssignError(variable, valueType))
 		return false
 	}
 
 	return true
 }
 
-func (analyzer *Analyzer) getExpressionType(expression parser.Expression, scope Scope) string {
+func (analyzer *Analyzer) assignElement(target parser.Expression, valueType Type, value parser.Expression, scope Scope) bool {
+	targetType := analyzer.getExpressionType(target, scope)
+
+	if targetType == Undefined || valueType == Undefined {
+		return false
+	}
+
+	if !analyzer.isAssignable(targetType, valueType, value) {
+		if selector, status := target.(parser.SelectorExpression); status {
+			analyzer.errors = append(analyzer.errors, newFieldError(selector.Selector.Name, targetType, valueType))
+		} else {
+			analyzer.errors = append(analyzer.errors, newElementError(targetType, valueType))
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// Type of every expression is recorded in type info
+func (analyzer *Analyzer) getExpressionType(expression parser.Expression, scope Scope) Type {
+	exprType := analyzer.findExpressionType(expression, scope)
+	analyzer.info.addType(expression, exprType)
+
+	return exprType
+}
+
+func (analyzer *Analyzer) findExpressionType(expression parser.Expression, scope Scope) Type {
 	if expr, status := expression.(parser.UnaryExpression); status {
-		return analyzer.getExpressionType(expr.Operand, scope)
+		if expr.Operator == parser.GetType(parser.BitAnd) {
+			analyzer.getExpressionType(expr.Operand, scope)
+			analyzer.errors = append(analyzer.errors, newAddressError())
+			return Undefined
+		}
+
+		operandType := analyzer.getExpressionType(expr.Operand, scope)
+
+		// Bitwise complement: ^a
+		if expr.Operator == parser.GetType(parser.BitXor) && operandType != Undefined && underlying(operandType) != Int {
+			analyzer.errors = append(analyzer.errors, newBitwiseOperandError(expr.Operator, operandType))
+			return Undefined
+		}
+
+		return operandType
 	} else if expr, status := expression.(parser.BinaryExpression); status {
-		return analyzer.getBinaryExpressionType(expr, scope)
+		exprType := analyzer.getBinaryExpressionType(expr, scope)
+
+		if exprType != Undefined && isZeroDivisor(expr, exprType) {
+			analyzer.errors = append(analyzer.errors, newDivisionByZeroError(expr))
+		}
+
+		return exprType
 	} else if lit, status := expression.(parser.Literal); status {
 		return intToType(int(lit.Type))
 	} else if ident, status := expression.(parser.Identifier); status {
@@ -142,33 +465,216 @@ func (analyzer *Analyzer) getExpressionType(expression parser.Expression, scope
 		}
 
 		return variable.Type
+	} else if lit, status := expression.(parser.CompositeLiteral); status {
+		return analyzer.getCompositeLiteralType(lit, scope)
+	} else if expr, status := expression.(parser.IndexExpression); status {
+		return analyzer.getIndexExpressionType(expr, scope)
+	} else if call, status := expression.(parser.CallExpression); status {
+		return analyzer.getCallExpressionType(call, scope)
+	} else if expr, status := expression.(parser.SelectorExpression); status {
+		return analyzer.getSelectorExpressionType(expr, scope)
 	}
 
 	return Undefined
 }
 
-func (analyzer *Analyzer) getBinaryExpressionType(expr parser.BinaryExpression, scope Scope) string {
+func (analyzer *Analyzer) getBinaryExpressionType(expr parser.BinaryExpression, scope Scope) Type {
+	if isBitwise(expr.Operator) {
+		return analyzer.getBitwiseExpressionType(expr, scope)
+	}
+
+	left := analyzer.getExpressionType(expr.LeftOperand, scope)
+	right := analyzer.getExpressionType(expr.RightOperand, scope)
+
+	exprType := left
+
+	// Operands of different types are allowed only if one of them is a constant,
+	// which takes the type of the other operand: t + 1.5, n == "x", i / 2.0.
+	// Operation of integer and float constants is a float constant: 7 / 2.0
+	if !Identical(left, right) {
+		isLeftConstant, isRightConstant := isConstant(expr.LeftOperand), isConstant(expr.RightOperand)
+
+		switch {
+		case isLeftConstant && isRightConstant && isNumber(left) && isNumber(right):
+			exprType = Float
+		case isRightConstant && analyzer.isAssignable(left, right, expr.RightOperand):
+			exprType = left
+		case isLeftConstant && analyzer.isAssignable(right, left, expr.LeftOperand):
+			exprType = right
+		default:
+			analyzer.errors = append(analyzer.errors, newExpressionError(left, right, expr))
+			return Undefined
+		}
+	}
+
+	if isComparison(expr.Operator) {
+		return Bool
+	}
+
+	return exprType
+}
+
+// Bitwise operations and shifts work only with integers: a & b, a << 2.
+// Shift count can be of any integer type, but can't be negative constant.
+func (analyzer *Analyzer) getBitwiseExpressionType(expr parser.BinaryExpression, scope Scope) Type {
 	left := analyzer.getExpressionType(expr.LeftOperand, scope)
 	right := analyzer.getExpressionType(expr.RightOperand, scope)
 
-	if left != right {
-		if isNumber(left) && isNumber(right) {
-			if isComparison(expr.Operator) {
-				return Bool
-			}
+	if left == Undefined || right == Undefined {
+		return Undefined
+	}
 
-			// Non-comparison operation between int and float
-			// results in float type
-			return Float
+	for _, operandType := range []Type{left, right} {
+		if underlying(operandType) != Int {
+			analyzer.errors = append(analyzer.errors, newBitwiseOperandError(expr.Operator, operandType))
+			return Undefined
 		}
+	}
 
-		analyzer.errors = append(analyzer.errors, newExpressionError(left, right))
-		return Undefined
-	} else { // Operand types are equal
-		if isComparison(expr.Operator) {
-			return Bool
+	if isShift(expr.Operator) {
+		if count, status := constantInteger(expr.RightOperand); status && count < 0 {
+			analyzer.errors = append(analyzer.errors, newNegativeShiftError(count))
 		}
 
 		return left
 	}
+
+	if Identical(left, right) {
+		return left
+	}
+
+	// Constants take the named type of the other operand: flags | 1
+	if isConstant(expr.RightOperand) {
+		return left
+	} else if isConstant(expr.LeftOperand) {
+		return right
+	}
+
+	analyzer.errors = append(analyzer.errors, newExpressionError(left, right, expr))
+	return Undefined
+}
+
+func (analyzer *Analyzer) getCompositeLiteralType(lit parser.CompositeLiteral, scope Scope) Type {
+	litType := analyzer.resolveType(lit.Type)
+
+	if litType == Undefined {
+		return Undefined
+	}
+
+	if _, status := underlying(litType).(*Struct); status {
+		analyzer.validateStructLiteral(litType, lit.Elements, scope)
+		return litType
+	}
+
+	if isMap(litType) {
+		analyzer.validateMapLiteral(litType, lit.Elements, scope)
+		return litType
+	}
+
+	element := elementType(litType)
+
+	if element == Undefined {
+		analyzer.errors = append(analyzer.errors, newInvalidLiteralTypeError(litType))
+		return Undefined
+	}
+
+	for _, expr := range lit.Elements {
+		if _, status := expr.(parser.KeyValueExpression); status {
+			analyzer.errors = append(analyzer.errors, newUnexpectedKeyError(litType))
+			continue
+		}
+
+		exprType := analyzer.getExpressionType(expr, scope)
+
+		if exprType != Undefined && !analyzer.isAssignable(element, exprType, expr) {
+			analyzer.errors = append(analyzer.errors, newElementError(element, exprType))
+		}
+	}
+
+	if length := arrayLength(litType); isArray(litType) && len(lit.Elements) > length {
+		analyzer.errors = append(analyzer.errors, newIndexOutOfRangeError(len(lit.Elements)-1, length))
+	}
+
+	return litType
+}
+
+func (analyzer *Analyzer) getIndexExpressionType(expr parser.IndexExpression, scope Scope) Type {
+	containerType := analyzer.getExpressionType(expr.Expression, scope)
+
+	if isMap(containerType) {
+		return analyzer.getMapValueType(containerType, expr.Index, scope)
+	}
+
+	indexType := analyzer.getExpressionType(expr.Index, scope)
+
+	if containerType == Undefined {
+		return Undefined
+	}
+
+	element := elementType(containerType)
+
+	if element == Undefined {
+		analyzer.errors = append(analyzer.errors, newNotIndexableError(containerType, expr))
+		return Undefined
+	}
+
+	if underlying(indexType) != Int {
+		analyzer.errors = append(analyzer.errors, newNonIntegerIndexError(indexType, expr))
+	} else if lit, status := expr.Index.(parser.Literal); status && isArray(containerType) {
+		// Constant indices of arrays are checked at compile time
+		index, length := int(lit.Value.(int64)), arrayLength(containerType)
+
+		if index < 0 || index >= length {
+			analyzer.errors = append(analyzer.errors, newIndexOutOfRangeError(index, length))
+		}
+	}
+
+	return element
+}
+
+func (analyzer *Analyzer) getMapValueType(mapType Type, key parser.Expression, scope Scope) Type {
+	m := underlying(mapType).(Map)
+	keyType := analyzer.getExpressionType(key, scope)
+
+	if keyType != Undefined && !analyzer.isAssignable(m.Key, keyType, key) {
+		analyzer.errors = append(analyzer.errors, newMapKeyError(m.Key, keyType))
+	}
+
+	return m.Value
+}
+
+// All elements of map literal have keys and constant keys can't be repeated
+func (analyzer *Analyzer) validateMapLiteral(mapType Type, elements parser.Expressions, scope Scope) {
+	m := underlying(mapType).(Map)
+	keys := map[string]bool{}
+
+	for _, element := range elements {
+		keyValue, status := element.(parser.KeyValueExpression)
+
+		if !status {
+			analyzer.errors = append(analyzer.errors, newMissingKeyError(mapType))
+			continue
+		}
+
+		keyType := analyzer.getExpressionType(keyValue.Key, scope)
+		valueType := analyzer.getExpressionType(keyValue.Value, scope)
+
+		if keyType != Undefined && !analyzer.isAssignable(m.Key, keyType, keyValue.Key) {
+			analyzer.errors = append(analyzer.errors, newMapKeyError(m.Key, keyType))
+		}
+
+		if valueType != Undefined && !analyzer.isAssignable(m.Value, valueType, keyValue.Value) {
+			analyzer.errors = append(analyzer.errors, newElementError(m.Value, valueType))
+		}
+
+		if lit, status := keyValue.Key.(parser.Literal); status {
+			key := fmt.Sprintf("%v", lit.Value)
+
+			if keys[key] {
+				analyzer.errors = append(analyzer.errors, newDuplicateKeyError(key))
+			}
+
+			keys[key] = true
+		}
+	}
 }
@@ -0,0 +1,129 @@
+package semantic
+
+import "../parser"
+
+const (
+	Len    = "len"
+	Cap    = "cap"
+	Append = "append"
+	Delete = "delete"
+)
+
+// Declared functions, builtins, conversions
+// and functions of supported packages can be called
+func (analyzer *Analyzer) getCallExpressionType(call parser.CallExpression, scope Scope) Type {
+	if selector, status := call.Function.(parser.SelectorExpression); status {
+		if pkg, status := analyzer.getPackageName(selector.Expression); status {
+			return analyzer.getPackageCallType(pkg, selector.Selector.Name, call.Arguments, scope)
+		}
+	}
+
+	ident, status := call.Function.(parser.Identifier)
+
+	if !status {
+		analyzer.errors = append(analyzer.errors, newNonFunctionCallError())
+		return Undefined
+	}
+
+	var argTypes []Type
+
+	for _, arg := range call.Arguments {
+		argTypes = append(argTypes, analyzer.getExpressionType(arg, scope))
+	}
+
+	// Calls of type names are conversions: float64(a)
+	if target, status := analyzer.types[ident.Name]; status {
+		return analyzer.getConversionType(target, call.Arguments, argTypes)
+	}
+
+	if function, status := analyzer.functions[ident.Name]; status {
+		return analyzer.getFunctionCallType(function, call.Arguments, argTypes)
+	}
+
+	switch ident.Name {
+	case Len:
+		return analyzer.getLengthType(ident.Name, argTypes, true)
+	case Cap:
+		return analyzer.getLengthType(ident.Name, argTypes, false)
+	case Append:
+		return analyzer.getAppendType(argTypes, call.Arguments)
+	case Delete:
+		return analyzer.getDeleteType(argTypes, call.Arguments)
+	}
+
+	analyzer.errors = append(analyzer.errors, newNotCallableError(ident.Name))
+	return Undefined
+}
+
+// len(a) accepts arrays, slices, maps and strings, cap(a) only arrays and slices
+func (analyzer *Analyzer) getLengthType(name string, argTypes []Type, isLen bool) Type {
+	if len(argTypes) != 1 {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(name, 1, len(argTypes)))
+		return Int
+	}
+
+	argType := argTypes[0]
+
+	if argType != Undefined && elementType(argType) == Undefined &&
+		!(isLen && (underlying(argType) == String || isMap(argType))) {
+		analyzer.errors = append(analyzer.errors, newArgumentTypeError(name, argType))
+	}
+
+	return Int
+}
+
+// append(s, 1, 2) results in the type of its first argument
+func (analyzer *Analyzer) getAppendType(argTypes []Type, args parser.Expressions) Type {
+	if len(argTypes) == 0 {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(Append, 1, 0))
+		return Undefined
+	}
+
+	sliceType := argTypes[0]
+
+	if sliceType == Undefined {
+		return Undefined
+	}
+
+	if !isSlice(sliceType) {
+		analyzer.errors = append(analyzer.errors, newArgumentTypeError(Append, sliceType))
+		return Undefined
+	}
+
+	element := elementType(sliceType)
+
+	for i, argType := range argTypes[1:] {
+		if argType != Undefined && !analyzer.isAssignable(element, argType, args[i+1]) {
+			analyzer.errors = append(analyzer.errors, newElementError(element, argType))
+		}
+	}
+
+	return sliceType
+}
+
+// delete(m, k) removes key from the map and has no result
+func (analyzer *Analyzer) getDeleteType(argTypes []Type, args parser.Expressions) Type {
+	if len(argTypes) != 2 {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(Delete, 2, len(argTypes)))
+		return Void
+	}
+
+	mapType, keyType := argTypes[0], argTypes[1]
+
+	if mapType == Undefined {
+		return Void
+	}
+
+	if !isMap(mapType) {
+		analyzer.errors = append(analyzer.errors, newArgumentTypeError(Delete, mapType))
+	} else if key := underlying(mapType).(Map).Key; keyType != Undefined && !analyzer.isAssignable(key, keyType, args[1]) {
+		analyzer.errors = append(analyzer.errors, newMapKeyError(key, keyType))
+	}
+
+	return Void
+}
+
+// Builtins which only compute values, so calling them without using result is an error
+func isPure(name string) bool {
+	return name == Len || name == Cap || name == Append
+}
@@ -0,0 +1,84 @@
+package semantic
+
+// Calls between declared functions.
+// Functions and their callees are kept in order of appearance.
+type CallGraph struct {
+	Functions []string
+	Calls     map[string][]string
+}
+
+func NewCallGraph() *CallGraph {
+	return &CallGraph{[]string{}, map[string][]string{}}
+}
+
+func (graph *CallGraph) addFunction(name string) {
+	graph.Functions = append(graph.Functions, name)
+}
+
+func (graph *CallGraph) addCall(caller string, callee string) {
+	for _, name := range graph.Calls[caller] {
+		if name == callee {
+			return
+		}
+	}
+
+	graph.Calls[caller] = append(graph.Calls[caller], callee)
+}
+
+// Groups of functions which call each other directly or through other functions.
+// Function calling itself forms a group alone: [fact], [isEven isOdd].
+// Groups and functions in them are in declaration order.
+func (graph *CallGraph) Cycles() [][]string {
+	var cycles [][]string
+	grouped := map[string]bool{}
+
+	for _, name := range graph.Functions {
+		if grouped[name] {
+			continue
+		}
+
+		var cycle []string
+
+		for _, other := range graph.Functions {
+			if !grouped[other] && graph.reaches(name, other) && graph.reaches(other, name) {
+				cycle = append(cycle, other)
+				grouped[other] = true
+			}
+		}
+
+		if len(cycle) > 0 {
+			cycles = append(cycles, cycle)
+		}
+	}
+
+	return cycles
+}
+
+// Whether function can be invoked again while it's still running
+func (graph *CallGraph) IsRecursive(name string) bool {
+	return graph != nil && graph.reaches(name, name)
+}
+
+// Whether there is a path of one or more calls from one function to another
+func (graph *CallGraph) reaches(from string, to string) bool {
+	visited := map[string]bool{}
+	queue := append([]string{}, graph.Calls[from]...)
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if name == to {
+			return true
+		}
+
+		if visited[name] {
+			continue
+		}
+
+		visited[name] = true
+		queue = append(queue, graph.Calls[name]...)
+	}
+
+	return false
+}
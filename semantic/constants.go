@@ -0,0 +1,184 @@
+package semantic
+
+import (
+	"../parser"
+	"go/constant"
+	"go/token"
+	"strconv"
+)
+
+// Operators of the syntax tree are written the same way as tokens of go/token
+var operators = map[string]token.Token{}
+
+func init() {
+	for tok := token.ADD; tok <= token.GEQ; tok++ {
+		operators[tok.String()] = tok
+	}
+}
+
+// Largest shift count of constants, Go compilers limit it the same way
+const maxShift = 1023
+
+// Value of expression made of literals, computed exactly the way Go computes constants:
+//   1 << 70 >> 68 -> 4
+//   7 / 2 -> 3, 7 / 2.0 -> 3.5
+// Nil if the expression isn't constant or its operation is invalid, like division by zero.
+func ConstantValue(expression parser.Expression) constant.Value {
+	switch expr := expression.(type) {
+	case parser.Literal:
+		return literalValue(expr)
+	case parser.UnaryExpression:
+		if expr.Operand == nil {
+			return nil
+		}
+
+		return unaryValue(expr.Operator, ConstantValue(expr.Operand))
+	case parser.BinaryExpression:
+		x := ConstantValue(expr.LeftOperand)
+
+		if x == nil {
+			return nil
+		}
+
+		return binaryValue(x, expr.Operator, ConstantValue(expr.RightOperand))
+	}
+
+	return nil
+}
+
+// Strings keep escapes they're written with: "a\n".
+// Floats are exact decimals written the shortest way, so 0.1 + 0.2 is exactly 0.3
+func literalValue(lit parser.Literal) constant.Value {
+	switch value := lit.Value.(type) {
+	case int64:
+		return constant.MakeInt64(value)
+	case float64:
+		return constant.MakeFromLiteral(strconv.FormatFloat(value, 'g', -1, 64), token.FLOAT, 0)
+	case bool:
+		return constant.MakeBool(value)
+	case string:
+		if str, err := strconv.Unquote(`"` + value + `"`); err == nil {
+			return constant.MakeString(str)
+		}
+	}
+
+	return nil
+}
+
+func unaryValue(operator string, x constant.Value) constant.Value {
+	if x == nil {
+		return nil
+	}
+
+	tok := operators[operator]
+
+	switch {
+	case (tok == token.ADD || tok == token.SUB) && isNumeric(x),
+		tok == token.XOR && x.Kind() == constant.Int,
+		tok == token.NOT && x.Kind() == constant.Bool:
+		return constant.UnaryOp(tok, x, 0)
+	}
+
+	return nil
+}
+
+// Kinds of operands are checked before, go/constant panics on invalid operations
+func binaryValue(x constant.Value, operator string, y constant.Value) constant.Value {
+	if y == nil {
+		return nil
+	}
+
+	tok, status := operators[operator]
+
+	if !status {
+		return nil
+	}
+
+	switch tok {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if isNumeric(x) && isNumeric(y) || x.Kind() == y.Kind() && x.Kind() == constant.String ||
+			x.Kind() == y.Kind() && x.Kind() == constant.Bool && (tok == token.EQL || tok == token.NEQ) {
+			return constant.MakeBool(constant.Compare(x, tok, y))
+		}
+	case token.SHL, token.SHR:
+		if x.Kind() != constant.Int || y.Kind() != constant.Int {
+			return nil
+		}
+
+		if count, exact := constant.Uint64Val(y); exact && count <= maxShift {
+			return constant.Shift(x, tok, uint(count))
+		}
+	case token.LAND, token.LOR:
+		if x.Kind() == constant.Bool && y.Kind() == constant.Bool {
+			return constant.BinaryOp(x, tok, y)
+		}
+	case token.ADD:
+		if isNumeric(x) && isNumeric(y) || x.Kind() == y.Kind() && x.Kind() == constant.String {
+			return constant.BinaryOp(x, tok, y)
+		}
+	case token.SUB, token.MUL:
+		if isNumeric(x) && isNumeric(y) {
+			return constant.BinaryOp(x, tok, y)
+		}
+	case token.QUO:
+		if !isNumeric(x) || !isNumeric(y) || constant.Sign(y) == 0 {
+			return nil
+		}
+
+		// Integer constants are divided the same way as integers
+		if x.Kind() == constant.Int && y.Kind() == constant.Int {
+			tok = token.QUO_ASSIGN
+		}
+
+		return constant.BinaryOp(x, tok, y)
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		if x.Kind() != constant.Int || y.Kind() != constant.Int || tok == token.REM && constant.Sign(y) == 0 {
+			return nil
+		}
+
+		return constant.BinaryOp(x, tok, y)
+	}
+
+	return nil
+}
+
+func isNumeric(x constant.Value) bool {
+	return x.Kind() == constant.Int || x.Kind() == constant.Float
+}
+
+// Whether the constant can be a value of the type: 2 can be float64, 2.5 can't be int, 5.0 can
+func isRepresentable(x constant.Value, t Type) bool {
+	switch underlying(t) {
+	case Int:
+		if x = constant.ToInt(x); x.Kind() != constant.Int {
+			return false
+		}
+
+		_, exact := constant.Int64Val(x)
+		return exact
+	case Float:
+		return isNumeric(x)
+	case String:
+		return x.Kind() == constant.String
+	case Bool:
+		return x.Kind() == constant.Bool
+	}
+
+	return false
+}
+
+// Division by constant zero is an error for integers and for constants,
+// only division of float variable gives infinity: f / 0.0
+func isZeroDivisor(expr parser.BinaryExpression, resultType Type) bool {
+	if expr.Operator != parser.GetType(parser.Div) && expr.Operator != parser.GetType(parser.Mod) {
+		return false
+	}
+
+	y := ConstantValue(expr.RightOperand)
+
+	if y == nil || !isNumeric(y) || constant.Sign(y) != 0 {
+		return false
+	}
+
+	return underlying(resultType) == Int || ConstantValue(expr.LeftOperand) != nil
+}
@@ -0,0 +1,54 @@
+package semantic
+
+import (
+	"../parser"
+	"go/constant"
+)
+
+// Conversion has exactly one argument and results in the target type
+func (analyzer *Analyzer) getConversionType(target Type, args parser.Expressions, argTypes []Type) Type {
+	if len(argTypes) != 1 {
+		analyzer.errors = append(analyzer.errors, newConversionCountError(target, len(argTypes)))
+		return target
+	}
+
+	argType := argTypes[0]
+
+	if argType == Undefined {
+		return target
+	}
+
+	if !isConvertible(argType, target) {
+		analyzer.errors = append(analyzer.errors, newConversionError(argType, target))
+		return target
+	}
+
+	// Constants should fit into integers without losing fractional part: int(5.0 / 2)
+	if x := ConstantValue(args[0]); x != nil && underlying(target) == Int && !isRepresentable(x, target) {
+		if constant.ToInt(x).Kind() == constant.Int {
+			analyzer.errors = append(analyzer.errors, newOverflowError(args[0], target))
+		} else {
+			analyzer.errors = append(analyzer.errors, newTruncatedConstantError(args[0]))
+		}
+	}
+
+	return target
+}
+
+// Conversions are allowed between:
+//   types with identical underlying types: Celsius(f)
+//   numeric types: float64(1)
+//   integers and strings, which gives a string with one character: string(65)
+func isConvertible(from Type, to Type) bool {
+	from, to = underlying(from), underlying(to)
+
+	if Identical(from, to) {
+		return true
+	}
+
+	if isNumber(from) && isNumber(to) {
+		return true
+	}
+
+	return from == Int && to == String
+}
@@ -0,0 +1,250 @@
+package semantic
+
+import "../parser"
+
+// Named types are declared before analyzing functions,
+// so they can be used regardless of declaration order.
+func (analyzer *Analyzer) declareTypes() {
+	var declarations []parser.TypeDeclaration
+	var named []*Named
+
+	for _, declaration := range analyzer.syntaxTree.Declarations {
+		if decl, status := declaration.(parser.TypeDeclaration); status {
+			declarations = append(declarations, decl)
+		}
+	}
+
+	declared := map[string]bool{}
+
+	for _, decl := range declarations {
+		if declared[decl.Name.Name] {
+			analyzer.errors = append(analyzer.errors, newTypeAlreadyDefinedError(decl.Name.Name))
+			continue
+		}
+
+		declared[decl.Name.Name] = true
+
+		if !decl.Alias {
+			analyzer.types[decl.Name.Name] = &Named{Name: decl.Name.Name}
+		}
+	}
+
+	for _, decl := range declarations {
+		declType := analyzer.resolveType(decl.Type)
+
+		if decl.Alias { // Alias is just another name for the same type
+			analyzer.types[decl.Name.Name] = declType
+		} else if typ, status := analyzer.types[decl.Name.Name].(*Named); status && typ.Underlying == nil {
+			typ.Underlying = declType
+			named = append(named, typ)
+		}
+	}
+
+	// Types declared with other named types get their underlying types: type B A
+	for _, typ := range named {
+		for i := 0; i <= len(named); i++ {
+			if other, status := typ.Underlying.(*Named); status {
+				typ.Underlying = other.Underlying
+			}
+		}
+
+		if _, status := typ.Underlying.(*Named); status {
+			analyzer.errors = append(analyzer.errors, newRecursiveTypeError(typ.Name))
+			typ.Underlying = Undefined
+		}
+	}
+
+	// Values of types which contain themselves would be infinite: type L struct { next L }
+	for _, typ := range named {
+		if containsNamed(typ.Underlying, typ, map[*Named]bool{}) {
+			analyzer.errors = append(analyzer.errors, newRecursiveTypeError(typ.Name))
+			typ.Underlying = Undefined
+		}
+	}
+}
+
+// Whether values of the type hold values of the named type in themselves.
+// Slices and maps refer to their elements, so they can be recursive: type T []T
+func containsNamed(t Type, named *Named, visited map[*Named]bool) bool {
+	switch t := t.(type) {
+	case *Named:
+		if t == named {
+			return true
+		}
+
+		if visited[t] {
+			return false
+		}
+
+		visited[t] = true
+		return containsNamed(t.Underlying, named, visited)
+	case Array:
+		return containsNamed(t.Element, named, visited)
+	case *Struct:
+		for _, field := range t.Fields {
+			if containsNamed(field.Type, named, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Converts type from the syntax tree to analyzer's type: []int -> []Integer
+func (analyzer *Analyzer) resolveType(expression parser.Expression) Type {
+	if ident, status := expression.(parser.Identifier); status {
+		if typ, status := analyzer.types[ident.Name]; status {
+			// Invalid named types are already reported
+			if named, status := typ.(*Named); status && named.Underlying == Undefined {
+				return Undefined
+			}
+
+			return typ
+		}
+
+		analyzer.errors = append(analyzer.errors, newUnknownTypeError(ident.Name))
+	} else if array, status := expression.(parser.ArrayType); status {
+		length, status := array.Length.(parser.Literal)
+
+		if !status || length.Type != parser.IntegerLiteral {
+			analyzer.errors = append(analyzer.errors, newArrayLengthError())
+			return Undefined
+		}
+
+		if element := analyzer.resolveType(array.Element); element != Undefined {
+			return Array{int(length.Value.(int64)), element}
+		}
+	} else if slice, status := expression.(parser.SliceType); status {
+		if element := analyzer.resolveType(slice.Element); element != Undefined {
+			return Slice{element}
+		}
+	} else if structType, status := expression.(parser.StructType); status {
+		return analyzer.resolveStructType(structType)
+	} else if mapType, status := expression.(parser.MapType); status {
+		key, value := analyzer.resolveType(mapType.Key), analyzer.resolveType(mapType.Value)
+
+		if key == Undefined || value == Undefined {
+			return Undefined
+		}
+
+		if !isComparable(key) {
+			analyzer.errors = append(analyzer.errors, newInvalidMapKeyError(key))
+			return Undefined
+		}
+
+		return Map{key, value}
+	}
+
+	return Undefined
+}
+
+func (analyzer *Analyzer) resolveStructType(structType parser.StructType) Type {
+	result := &Struct{}
+
+	for _, field := range structType.Fields {
+		fieldType := analyzer.resolveType(field.Type)
+
+		for _, name := range field.Names {
+			if result.Field(name.Name) != nil {
+				analyzer.errors = append(analyzer.errors, newDuplicateFieldError(name.Name))
+				continue
+			}
+
+			result.Fields = append(result.Fields, Field{name.Name, fieldType})
+		}
+	}
+
+	return result
+}
+
+func (analyzer *Analyzer) getSelectorExpressionType(expr parser.SelectorExpression, scope Scope) Type {
+	if pkg, status := analyzer.getPackageName(expr.Expression); status {
+		analyzer.errors = append(analyzer.errors, newPackageValueError(pkg))
+		return Undefined
+	}
+
+	baseType := analyzer.getExpressionType(expr.Expression, scope)
+
+	if baseType == Undefined {
+		return Undefined
+	}
+
+	if structType, status := underlying(baseType).(*Struct); status {
+		if field := structType.Field(expr.Selector.Name); field != nil {
+			return field.Type
+		}
+	}
+
+	analyzer.errors = append(analyzer.errors, newNoFieldError(baseType, expr.Selector.Name))
+	return Undefined
+}
+
+// Struct literal contains either values for all fields in order: Point{1, 2}
+// or values for some fields with their names: Point{X: 1}
+func (analyzer *Analyzer) validateStructLiteral(litType Type, elements parser.Expressions, scope Scope) {
+	structType := underlying(litType).(*Struct)
+
+	if len(elements) == 0 {
+		return
+	}
+
+	if _, keyed := elements[0].(parser.KeyValueExpression); !keyed {
+		if len(elements) != len(structType.Fields) {
+			analyzer.errors = append(analyzer.errors, newStructLiteralCountError(litType))
+		}
+
+		for i, element := range elements {
+			if _, status := element.(parser.KeyValueExpression); status {
+				analyzer.errors = append(analyzer.errors, newMixedStructLiteralError())
+				return
+			}
+
+			if i < len(structType.Fields) {
+				analyzer.validateField(structType.Fields[i], element, scope)
+			}
+		}
+
+		return
+	}
+
+	specified := map[string]bool{}
+
+	for _, element := range elements {
+		keyValue, status := element.(parser.KeyValueExpression)
+
+		if !status {
+			analyzer.errors = append(analyzer.errors, newMixedStructLiteralError())
+			return
+		}
+
+		key, status := keyValue.Key.(parser.Identifier)
+
+		if !status {
+			analyzer.errors = append(analyzer.errors, newInvalidFieldNameError())
+			continue
+		}
+
+		field := structType.Field(key.Name)
+
+		if field == nil {
+			analyzer.errors = append(analyzer.errors, newNoFieldError(litType, key.Name))
+			continue
+		}
+
+		if specified[key.Name] {
+			analyzer.errors = append(analyzer.errors, newDuplicateFieldError(key.Name))
+		}
+
+		specified[key.Name] = true
+		analyzer.validateField(*field, keyValue.Value, scope)
+	}
+}
+
+func (analyzer *Analyzer) validateField(field Field, value parser.Expression, scope Scope) {
+	valueType := analyzer.getExpressionType(value, scope)
+
+	if valueType != Undefined && !analyzer.isAssignable(field.Type, valueType, value) {
+		analyzer.errors = append(analyzer.errors, newFieldError(field.Name, field.Type, valueType))
+	}
+}
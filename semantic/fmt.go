@@ -0,0 +1,213 @@
+package semantic
+
+import (
+	"../parser"
+	"strings"
+)
+
+const (
+	Print   = "Print"
+	Println = "Println"
+	Printf  = "Printf"
+	Scan    = "Scan"
+)
+
+// Part of Printf format: either plain text or a verb
+// which formats the next argument: "a = %d\n" -> "a = ", 'd', "\n"
+type FormatPart struct {
+	Text string
+	Verb byte // 0 for plain text
+}
+
+// Only simple verbs without flags, width or precision are supported
+var verbs = map[byte]Type{
+	'd': Int,
+	'f': Float,
+	's': String,
+	't': Bool,
+	'v': Undefined, // Any printable value
+}
+
+// Flags, width and precision aren't supported, they are reported as part of the verb
+const verbModifiers = "+-# 0123456789.*"
+
+// Output functions print values of basic types and have no result
+func (analyzer *Analyzer) validatePrintCall(args parser.Expressions, scope Scope) {
+	analyzer.validatePrintArguments(Fmt+"."+Print, args, scope)
+}
+
+func (analyzer *Analyzer) validatePrintlnCall(args parser.Expressions, scope Scope) {
+	analyzer.validatePrintArguments(Fmt+"."+Println, args, scope)
+}
+
+func (analyzer *Analyzer) validatePrintArguments(function string, args parser.Expressions, scope Scope) {
+	for _, arg := range args {
+		analyzer.validatePrintArgument(function, analyzer.getValueType(arg, scope))
+	}
+}
+
+// Scan reads values into variables passed by address: fmt.Scan(&a, &b)
+func (analyzer *Analyzer) validateScanCall(args parser.Expressions, scope Scope) {
+	if len(args) == 0 {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(Fmt+"."+Scan, 1, 0))
+	}
+
+	for _, arg := range args {
+		analyzer.validateScanArgument(arg, scope)
+	}
+}
+
+func (analyzer *Analyzer) validatePrintArgument(function string, argType Type) {
+	if argType != Undefined && !isPrintable(argType) {
+		analyzer.errors = append(analyzer.errors, newArgumentTypeError(function, argType))
+	}
+}
+
+// Format of Printf should be a string literal,
+// so its verbs can be checked against arguments.
+func (analyzer *Analyzer) validatePrintfCall(args parser.Expressions, scope Scope) {
+	function := Fmt + "." + Printf
+
+	if len(args) == 0 {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(function, 1, 0))
+		return
+	}
+
+	var argTypes []Type
+
+	for _, arg := range args[1:] {
+		argTypes = append(argTypes, analyzer.getValueType(arg, scope))
+	}
+
+	format, status := args[0].(parser.Literal)
+
+	if !status || format.Type != parser.StringLiteral {
+		analyzer.getExpressionType(args[0], scope)
+		analyzer.errors = append(analyzer.errors, newNonConstantFormatError())
+		return
+	}
+
+	parts, err := SplitFormat(format.Value.(string))
+
+	if err != nil {
+		analyzer.errors = append(analyzer.errors, err)
+		return
+	}
+
+	count := 0
+
+	for _, part := range parts {
+		if part.Verb == 0 {
+			continue
+		}
+
+		if count < len(argTypes) {
+			analyzer.validateVerb(part.Verb, argTypes[count])
+		}
+
+		count++
+	}
+
+	if count != len(argTypes) {
+		analyzer.errors = append(analyzer.errors, newFormatCountError(count, len(argTypes)))
+	}
+}
+
+func (analyzer *Analyzer) validateVerb(verb byte, argType Type) {
+	if argType == Undefined {
+		return
+	}
+
+	if expected := verbs[verb]; !isPrintable(argType) ||
+		expected != Undefined && underlying(argType) != expected {
+		analyzer.errors = append(analyzer.errors, newVerbError(verb, argType))
+	}
+}
+
+// Scan arguments are addresses of variables, elements or fields: &a, &a[i], &p.X
+func (analyzer *Analyzer) validateScanArgument(arg parser.Expression, scope Scope) {
+	address, status := arg.(parser.UnaryExpression)
+
+	if !status || address.Operator != parser.GetType(parser.BitAnd) || !isAddressable(address.Operand) {
+		analyzer.getExpressionType(arg, scope)
+		analyzer.errors = append(analyzer.errors, newScanArgumentError())
+		return
+	}
+
+	argType := analyzer.getExpressionType(address.Operand, scope)
+
+	if argType != Undefined && !isPrintable(argType) {
+		analyzer.errors = append(analyzer.errors, newArgumentTypeError(Fmt+"."+Scan, argType))
+	}
+}
+
+// Splits Printf format into text and verbs, '%%' is the percent sign itself
+func SplitFormat(format string) ([]FormatPart, *parser.Error) {
+	var parts []FormatPart
+	var text string
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			text += string(format[i])
+			continue
+		}
+
+		if i+1 == len(format) {
+			return nil, newVerbMissingError()
+		}
+
+		i++
+
+		if format[i] == '%' {
+			text += "%"
+			continue
+		}
+
+		// Flags, width and precision go before the letter of verb: %.2f
+		start := i - 1
+
+		for i < len(format) && strings.IndexByte(verbModifiers, format[i]) >= 0 {
+			i++
+		}
+
+		if i == len(format) {
+			return nil, newVerbMissingError()
+		}
+
+		if _, status := verbs[format[i]]; !status || i != start+1 {
+			return nil, newUnsupportedVerbError(format[start : i+1])
+		}
+
+		if text != "" {
+			parts = append(parts, FormatPart{Text: text})
+			text = ""
+		}
+
+		parts = append(parts, FormatPart{Verb: format[i]})
+	}
+
+	if text != "" {
+		parts = append(parts, FormatPart{Text: text})
+	}
+
+	return parts, nil
+}
+
+// Only values of basic types can be printed and scanned
+func isPrintable(typ Type) bool {
+	switch underlying(typ) {
+	case Int, Float, String, Bool:
+		return true
+	}
+
+	return false
+}
+
+func isAddressable(expression parser.Expression) bool {
+	switch expression.(type) {
+	case parser.Identifier, parser.IndexExpression, parser.SelectorExpression:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,232 @@
+package semantic
+
+import "../parser"
+
+// Signature of declared function
+type Function struct {
+	Name    string
+	Params  []*Variable
+	Results []*Variable // Names of unnamed results are empty
+	Result  Type        // Void, type of the only result or tuple of several
+}
+
+// Functions are declared before analyzing their bodies,
+// so they can call each other regardless of declaration order.
+func (analyzer *Analyzer) declareFunctions() {
+	for _, declaration := range analyzer.syntaxTree.Declarations {
+		decl, status := declaration.(parser.FuncDeclaration)
+
+		if !status {
+			continue
+		}
+
+		if _, status := analyzer.functions[decl.Name.Name]; status {
+			analyzer.errors = append(analyzer.errors, newFunctionAlreadyDefinedError(decl.Name.Name))
+			continue
+		}
+
+		function := &Function{Name: decl.Name.Name, Result: Void}
+
+		for _, param := range decl.Parameters {
+			paramType := analyzer.resolveType(param.Type)
+
+			for _, name := range param.Names {
+				function.Params = append(function.Params, &Variable{name.Name, paramType, decl.Name.Name})
+			}
+		}
+
+		for _, result := range decl.Results {
+			resultType := analyzer.resolveType(result.Type)
+
+			if len(result.Names) == 0 {
+				function.Results = append(function.Results, &Variable{"", resultType, decl.Name.Name})
+			}
+
+			for _, name := range result.Names {
+				function.Results = append(function.Results, &Variable{name.Name, resultType, decl.Name.Name})
+			}
+		}
+
+		if len(function.Results) == 1 {
+			function.Result = function.Results[0].Type
+		} else if len(function.Results) > 1 {
+			tuple := &Tuple{}
+
+			for _, result := range function.Results {
+				tuple.Types = append(tuple.Types, result.Type)
+			}
+
+			function.Result = tuple
+		}
+
+		analyzer.functions[function.Name] = function
+		analyzer.callGraph.addFunction(function.Name)
+	}
+}
+
+// Parameters and named results are defined in the same scope as top-level
+// variables of the body, so they can't be redeclared there: func f(a int) { a := 2 }
+func (analyzer *Analyzer) analyzeFunction(decl parser.FuncDeclaration) {
+	analyzer.current = analyzer.functions[decl.Name.Name]
+	analyzer.variables = Variables{}
+
+	variables := append(append([]*Variable{}, analyzer.current.Params...), analyzer.current.Results...)
+
+	for _, param := range variables {
+		ident := parser.Identifier{Name: param.Name}
+
+		if param.Name == "" || param.Name == Blank {
+			continue
+		}
+
+		if analyzer.findVariableAtScope(ident, 0) != nil {
+			analyzer.errors = append(analyzer.errors, newAlreadyDefinedError(ident))
+			continue
+		}
+
+		analyzer.defineVariable(ident, param.Type, 0)
+	}
+
+	analyzer.traverseStatement(decl.Body, 0)
+
+	if analyzer.current.Result != Void && !IsTerminating(decl.Body) {
+		analyzer.errors = append(analyzer.errors, newMissingReturnError(decl.Name.Name))
+	}
+}
+
+func (analyzer *Analyzer) getFunctionCallType(function *Function, args parser.Expressions, argTypes []Type) Type {
+	analyzer.callGraph.addCall(analyzer.current.Name, function.Name)
+
+	if len(args) != len(function.Params) {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(function.Name, len(function.Params), len(args)))
+	}
+
+	for i, argType := range argTypes {
+		if i >= len(function.Params) || argType == Undefined || function.Params[i].Type == Undefined {
+			continue
+		}
+
+		if argType == Void {
+			analyzer.errors = append(analyzer.errors, newVoidValueError())
+		} else if _, status := argType.(*Tuple); status {
+			analyzer.errors = append(analyzer.errors, newMultipleValueError())
+		} else if !analyzer.isAssignable(function.Params[i].Type, argType, args[i]) {
+			analyzer.errors = append(analyzer.errors, newArgumentTypeError(function.Name, argType))
+		}
+	}
+
+	return function.Result
+}
+
+// Function with several results can return results of another call: return divmod(a, b).
+// Return without values is allowed when results are named.
+func (analyzer *Analyzer) validateReturnStatement(stmt parser.ReturnStatement, scope Scope) {
+	function := analyzer.current
+	values := stmt.Results
+
+	if len(values) == 0 {
+		if function.Result != Void && function.Results[0].Name == "" {
+			analyzer.errors = append(analyzer.errors, newMissingReturnValueError(function.Name))
+		}
+
+		return
+	}
+
+	if function.Result == Void {
+		for _, value := range values {
+			analyzer.getExpressionType(value, scope)
+		}
+
+		analyzer.errors = append(analyzer.errors, newUnexpectedReturnValueError(function.Name))
+		return
+	}
+
+	if _, status := values[0].(parser.CallExpression); status && len(values) == 1 && len(function.Results) > 1 {
+		valueType := analyzer.getExpressionType(values[0], scope)
+
+		if tuple, status := valueType.(*Tuple); status && len(tuple.Types) == len(function.Results) {
+			for i, resultType := range tuple.Types {
+				analyzer.validateReturnValue(function.Results[i].Type, resultType, values[0])
+			}
+		} else if tuple, status := valueType.(*Tuple); status {
+			analyzer.errors = append(analyzer.errors, newReturnCountError(function.Name, len(function.Results), len(tuple.Types)))
+		} else if valueType != Undefined {
+			analyzer.errors = append(analyzer.errors, newReturnCountError(function.Name, len(function.Results), 1))
+		}
+
+		return
+	}
+
+	if len(values) != len(function.Results) {
+		for _, value := range values {
+			analyzer.getExpressionType(value, scope)
+		}
+
+		analyzer.errors = append(analyzer.errors, newReturnCountError(function.Name, len(function.Results), len(values)))
+		return
+	}
+
+	for i, value := range values {
+		analyzer.validateReturnValue(function.Results[i].Type, analyzer.getValueType(value, scope), value)
+	}
+}
+
+func (analyzer *Analyzer) validateReturnValue(resultType Type, valueType Type, value parser.Expression) {
+	if valueType != Undefined && resultType != Undefined && !analyzer.isAssignable(resultType, valueType, value) {
+		analyzer.errors = append(analyzer.errors, newReturnTypeError(analyzer.current.Name, resultType, valueType))
+	}
+}
+
+// Function with result should end with terminating statement:
+// return or if-else and switch with default where all branches terminate.
+func IsTerminating(statement parser.Statement) bool {
+	if _, status := statement.(parser.ReturnStatement); status {
+		return true
+	} else if stmt, status := statement.(parser.BlockStatement); status {
+		return IsTerminating(stmt.Statements)
+	} else if stmts, status := statement.(parser.Statements); status {
+		return len(stmts) > 0 && IsTerminating(stmts[len(stmts)-1])
+	} else if stmt, status := statement.(parser.IfStatement); status {
+		return IsTerminating(stmt.IfBody) && IsTerminating(stmt.ElseBody)
+	} else if stmt, status := statement.(parser.SwitchStatement); status {
+		hasDefault := false
+
+		for _, caseStmt := range stmt.Body {
+			if expr, status := caseStmt.Expression.(parser.UnaryExpression); status && expr.Operand == nil {
+				hasDefault = true
+			}
+
+			if !IsTerminating(caseStmt.Body) {
+				return false
+			}
+		}
+
+		return hasDefault
+	}
+
+	return false
+}
+
+// Switch is translated to IF chain and break leaves the innermost IF,
+// so break inside if of case would run the rest of the case:
+//   case 1:
+//       if a { break }
+//       b()
+// Cases of nested switch are checked on their own.
+func hasNestedBranch(statement parser.Statement, isNested bool) bool {
+	if _, status := statement.(parser.BranchStatement); status {
+		return isNested
+	} else if stmt, status := statement.(parser.BlockStatement); status {
+		return hasNestedBranch(stmt.Statements, isNested)
+	} else if stmts, status := statement.(parser.Statements); status {
+		for _, stmt := range stmts {
+			if hasNestedBranch(stmt, isNested) {
+				return true
+			}
+		}
+	} else if stmt, status := statement.(parser.IfStatement); status {
+		return hasNestedBranch(stmt.IfBody, true) || hasNestedBranch(stmt.ElseBody, true)
+	}
+
+	return false
+}
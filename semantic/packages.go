@@ -0,0 +1,61 @@
+package semantic
+
+import (
+	"../parser"
+	"strings"
+)
+
+const Fmt = "fmt"
+
+// Imported packages are remembered by their names,
+// so it can be checked later whether they were used.
+func (analyzer *Analyzer) declareImports() {
+	for _, spec := range analyzer.syntaxTree.Imports {
+		name := spec.Path[strings.LastIndex(spec.Path, "/")+1:]
+
+		if _, status := stdlib[spec.Path]; !status {
+			analyzer.errors = append(analyzer.errors, newUnsupportedPackageError(spec.Path))
+			continue
+		}
+
+		if _, status := analyzer.imports[name]; status {
+			analyzer.errors = append(analyzer.errors, newAlreadyImportedError(spec.Path))
+			continue
+		}
+
+		analyzer.imports[name] = false
+	}
+}
+
+func (analyzer *Analyzer) validateImportsUsage() {
+	for _, spec := range analyzer.syntaxTree.Imports {
+		name := spec.Path[strings.LastIndex(spec.Path, "/")+1:]
+
+		if used, status := analyzer.imports[name]; status && !used {
+			analyzer.errors = append(analyzer.errors, newUnusedImportError(spec.Path))
+			analyzer.imports[name] = true // Reported once even if imported twice
+		}
+	}
+}
+
+// Returns package name if expression refers to a package: fmt in fmt.Println.
+// Using known package without importing it is an error.
+func (analyzer *Analyzer) getPackageName(expression parser.Expression) (string, bool) {
+	ident, status := expression.(parser.Identifier)
+
+	if !status {
+		return "", false
+	}
+
+	if _, status := analyzer.imports[ident.Name]; status {
+		analyzer.imports[ident.Name] = true
+		return ident.Name, true
+	}
+
+	if _, status := stdlib[ident.Name]; status {
+		analyzer.errors = append(analyzer.errors, newNotImportedError(ident.Name))
+		return ident.Name, true
+	}
+
+	return "", false
+}
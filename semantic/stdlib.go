@@ -0,0 +1,96 @@
+package semantic
+
+import "../parser"
+
+const Math = "math"
+
+const (
+	Sqrt  = "Sqrt"
+	Abs   = "Abs"
+	Pow   = "Pow"
+	Floor = "Floor"
+	Max   = "Max"
+	Min   = "Min"
+)
+
+// Signature of a function from standard package.
+// Functions with special rules for arguments, like fmt.Printf,
+// are checked by their own validation instead of parameter types.
+type Signature struct {
+	Params   []Type
+	Result   Type
+	validate func(analyzer *Analyzer, args parser.Expressions, scope Scope)
+}
+
+// Functions of standard packages which can be translated to LWIQA
+var stdlib = map[string]map[string]Signature{
+	Math: {
+		Sqrt:  {Params: []Type{Float}, Result: Float},
+		Abs:   {Params: []Type{Float}, Result: Float},
+		Pow:   {Params: []Type{Float, Float}, Result: Float},
+		Floor: {Params: []Type{Float}, Result: Float},
+		Max:   {Params: []Type{Float, Float}, Result: Float},
+		Min:   {Params: []Type{Float, Float}, Result: Float},
+	},
+}
+
+// Validation of fmt functions goes through the analyzer back to this table,
+// so they are registered at initialization to avoid initialization cycle.
+func init() {
+	stdlib[Fmt] = map[string]Signature{
+		Print:   {Result: Void, validate: (*Analyzer).validatePrintCall},
+		Println: {Result: Void, validate: (*Analyzer).validatePrintlnCall},
+		Printf:  {Result: Void, validate: (*Analyzer).validatePrintfCall},
+		Scan:    {Result: Void, validate: (*Analyzer).validateScanCall},
+	}
+}
+
+// Calls of package functions: fmt.Println(a), math.Sqrt(x)
+func (analyzer *Analyzer) getPackageCallType(pkg string, name string, args parser.Expressions, scope Scope) Type {
+	signature, status := stdlib[pkg][name]
+
+	if !status {
+		for _, arg := range args {
+			analyzer.getExpressionType(arg, scope)
+		}
+
+		analyzer.errors = append(analyzer.errors, newNotTranslatableError(pkg+"."+name))
+		return Undefined
+	}
+
+	if signature.validate != nil {
+		signature.validate(analyzer, args, scope)
+		return signature.Result
+	}
+
+	if len(args) != len(signature.Params) {
+		analyzer.errors = append(analyzer.errors, newArgumentCountError(pkg+"."+name, len(signature.Params), len(args)))
+	}
+
+	for i, arg := range args {
+		argType := analyzer.getValueType(arg, scope)
+
+		if i >= len(signature.Params) || argType == Undefined {
+			continue
+		}
+
+		if !analyzer.isAssignable(signature.Params[i], argType, arg) {
+			analyzer.errors = append(analyzer.errors, newArgumentTypeError(pkg+"."+name, argType))
+		}
+	}
+
+	return signature.Result
+}
+
+// Functions which are called as standard functions of the target: math.Sqrt.
+// Functions of fmt are statements, so they aren't standard functions.
+func IsStandardFunction(pkg string, name string) bool {
+	_, status := stdlib[pkg][name]
+	return status && pkg != Fmt
+}
+
+// Package functions are pure if they return a value: math.Sqrt
+func isPurePackageFunction(pkg string, name string) bool {
+	signature, status := stdlib[pkg][name]
+	return status && signature.Result != Void
+}
@@ -0,0 +1,54 @@
+package semantic
+
+import "../parser"
+
+// Types of expressions and variables which identifiers refer to, found during analysis.
+// Expressions are known by their positions, see parser.ExpressionPosition.
+// Expressions which aren't from the source have no position and no type.
+type TypeInfo struct {
+	Types   map[parser.Position]Type
+	Symbols map[parser.Position]*Variable
+}
+
+func NewTypeInfo() *TypeInfo {
+	return &TypeInfo{map[parser.Position]Type{}, map[parser.Position]*Variable{}}
+}
+
+// Undefined if type of expression isn't known
+func (info *TypeInfo) TypeOf(expression parser.Expression) Type {
+	if info == nil {
+		return Undefined
+	}
+
+	if exprType, status := info.Types[parser.ExpressionPosition(expression)]; status {
+		return exprType
+	}
+
+	return Undefined
+}
+
+// Nil if identifier isn't a variable
+func (info *TypeInfo) SymbolOf(ident parser.Identifier) *Variable {
+	if info == nil {
+		return nil
+	}
+
+	return info.Symbols[ident.Position]
+}
+
+// Named type of expression is replaced with the type it was declared with
+func (info *TypeInfo) UnderlyingTypeOf(expression parser.Expression) Type {
+	return underlying(info.TypeOf(expression))
+}
+
+func (info *TypeInfo) addType(expression parser.Expression, exprType Type) {
+	if position := parser.ExpressionPosition(expression); position.Line != 0 {
+		info.Types[position] = exprType
+	}
+}
+
+func (info *TypeInfo) addSymbol(ident parser.Identifier, variable *Variable) {
+	if ident.Position.Line != 0 && variable != nil {
+		info.Symbols[ident.Position] = variable
+	}
+}
@@ -0,0 +1,244 @@
+package semantic
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Type interface {
+	String() string
+}
+
+// Basic types are equal when their names are equal
+type Basic struct {
+	Name string
+}
+
+type Array struct {
+	Length  int
+	Element Type
+}
+
+type Slice struct {
+	Element Type
+}
+
+type Map struct {
+	Key   Type
+	Value Type
+}
+
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Structs are always used by pointer, so types containing them stay comparable
+type Struct struct {
+	Fields []Field
+}
+
+// Type declared with 'type' keyword. Named types are equal only to themselves.
+type Named struct {
+	Name       string
+	Underlying Type
+}
+
+// Results of function call with several results: (Integer, String).
+// Used by pointer for the same reason as structs.
+type Tuple struct {
+	Types []Type
+}
+
+var (
+	Undefined Type = Basic{"Undefined"}
+	Int       Type = Basic{"Integer"}
+	Float     Type = Basic{"Float"}
+	String    Type = Basic{"String"}
+	Bool      Type = Basic{"Boolean"}
+
+	// Result of calls which don't return anything: delete(m, k)
+	Void Type = Basic{"Void"}
+)
+
+var types = map[int]Type{
+	0: Int,
+	1: Float,
+	2: String,
+	3: Bool,
+}
+
+// Types which can be used in the code without declaration
+var predeclaredTypes = map[string]Type{
+	"int":     Int,
+	"float64": Float,
+	"string":  String,
+	"bool":    Bool,
+}
+
+func intToType(keyCode int) Type {
+	if keyCode >= 0 && keyCode < len(types) {
+		return types[keyCode]
+	}
+
+	return Undefined
+}
+
+func (i Basic) String() string {
+	return i.Name
+}
+
+func (i Array) String() string {
+	return "[" + strconv.Itoa(i.Length) + "]" + i.Element.String()
+}
+
+func (i Slice) String() string {
+	return "[]" + i.Element.String()
+}
+
+func (i Map) String() string {
+	return "map[" + i.Key.String() + "]" + i.Value.String()
+}
+
+func (i *Struct) String() string {
+	var fields []string
+
+	for _, field := range i.Fields {
+		fields = append(fields, field.Name+" "+field.Type.String())
+	}
+
+	return "struct{" + strings.Join(fields, "; ") + "}"
+}
+
+func (i *Named) String() string {
+	return i.Name
+}
+
+func (i *Tuple) String() string {
+	var types []string
+
+	for _, typ := range i.Types {
+		types = append(types, typ.String())
+	}
+
+	return "(" + strings.Join(types, ", ") + ")"
+}
+
+// Returns nil if there's no such field
+func (i *Struct) Field(name string) *Field {
+	for index := range i.Fields {
+		if i.Fields[index].Name == name {
+			return &i.Fields[index]
+		}
+	}
+
+	return nil
+}
+
+// Named types are replaced with the types they were declared with
+func underlying(t Type) Type {
+	if named, status := t.(*Named); status {
+		return named.Underlying
+	}
+
+	return t
+}
+
+// Types are identical when they're written the same way,
+// except for named types which are identical only to themselves.
+func Identical(x Type, y Type) bool {
+	if x == y {
+		return true
+	}
+
+	switch x := x.(type) {
+	case Array:
+		if y, status := y.(Array); status {
+			return x.Length == y.Length && Identical(x.Element, y.Element)
+		}
+	case Slice:
+		if y, status := y.(Slice); status {
+			return Identical(x.Element, y.Element)
+		}
+	case Map:
+		if y, status := y.(Map); status {
+			return Identical(x.Key, y.Key) && Identical(x.Value, y.Value)
+		}
+	case *Tuple:
+		if y, status := y.(*Tuple); status && len(x.Types) == len(y.Types) {
+			for i := range x.Types {
+				if !Identical(x.Types[i], y.Types[i]) {
+					return false
+				}
+			}
+
+			return true
+		}
+	case *Struct:
+		if y, status := y.(*Struct); status && len(x.Fields) == len(y.Fields) {
+			for i := range x.Fields {
+				if x.Fields[i].Name != y.Fields[i].Name || !Identical(x.Fields[i].Type, y.Fields[i].Type) {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns Undefined if type is neither array nor slice
+func elementType(t Type) Type {
+	switch t := underlying(t).(type) {
+	case Array:
+		return t.Element
+	case Slice:
+		return t.Element
+	}
+
+	return Undefined
+}
+
+func isSlice(t Type) bool {
+	_, status := underlying(t).(Slice)
+	return status
+}
+
+func isArray(t Type) bool {
+	_, status := underlying(t).(Array)
+	return status
+}
+
+// Returns -1 if type is not an array
+func arrayLength(t Type) int {
+	if array, status := underlying(t).(Array); status {
+		return array.Length
+	}
+
+	return -1
+}
+
+func isMap(t Type) bool {
+	_, status := underlying(t).(Map)
+	return status
+}
+
+// Only comparable types can be used as map keys
+func isComparable(t Type) bool {
+	switch t := underlying(t).(type) {
+	case Slice, Map:
+		return false
+	case Array:
+		return isComparable(t.Element)
+	case *Struct:
+		for _, field := range t.Fields {
+			if !isComparable(field.Type) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
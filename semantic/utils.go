@@ -2,7 +2,10 @@ package semantic
 
 import (
 	"../parser"
+	"../printer"
+	"go/constant"
 	"sort"
+	"strconv"
 )
 
 func SortMapKeys(m Variables) []int {
@@ -20,30 +23,100 @@ func SortMapKeys(m Variables) []int {
 func isComparison(operator string) bool {
 	switch operator {
 	case parser.GetType(parser.Eq), parser.GetType(parser.Geq), parser.GetType(parser.Leq),
-		parser.GetType(parser.Greater), parser.GetType(parser.Less):
+		parser.GetType(parser.Greater), parser.GetType(parser.Less), parser.GetType(parser.Neq):
 		return true
 	default:
 		return false
 	}
 }
 
-func isNumber(exprType string) bool {
+func isNumber(exprType Type) bool {
+	exprType = underlying(exprType)
 	return exprType == Float || exprType == Int
 }
 
-func newAssignError(variable *Variable, realType string) *parser.Error {
-	message := "Cannot use type " + realType + " in variable '" +
-		variable.Name + "' of type " + variable.Type
+// plural(2, "value") -> "2 values"
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+
+	return strconv.Itoa(count) + " " + noun + "s"
+}
+
+func isBitwise(operator string) bool {
+	switch operator {
+	case parser.GetType(parser.BitAnd), parser.GetType(parser.BitOr), parser.GetType(parser.BitXor),
+		parser.GetType(parser.AndNot), parser.GetType(parser.Shl), parser.GetType(parser.Shr):
+		return true
+	default:
+		return false
+	}
+}
+
+func isShift(operator string) bool {
+	return operator == parser.GetType(parser.Shl) || operator == parser.GetType(parser.Shr)
+}
+
+// Value of integer constant: 2, -2, 1 << 3
+func constantInteger(expression parser.Expression) (int64, bool) {
+	if value := ConstantValue(expression); value != nil && value.Kind() == constant.Int {
+		return constant.Int64Val(value)
+	}
+
+	return 0, false
+}
+
+// Values are assigned to parameters and results of the same type.
+// Constants can be used for any type which can hold their values:
+// named types with the same underlying type and floats for integer constants: math.Pow(x, 2)
+// Constants which don't fit into the type can't be used even with the same type: 1 << 70
+func isAssignable(target Type, valueType Type, value parser.Expression) bool {
+	if x := ConstantValue(value); x != nil {
+		return isRepresentable(x, target)
+	}
+
+	return Identical(target, valueType)
+}
+
+// Constant written as float gets the integer type it's used with, so it's an integer
+// where it's generated and evaluated: var c int = 5.0
+func (analyzer *Analyzer) isAssignable(target Type, valueType Type, value parser.Expression) bool {
+	if !isAssignable(target, valueType, value) {
+		return false
+	}
+
+	if x := ConstantValue(value); x != nil && x.Kind() == constant.Float && underlying(target) == Int {
+		analyzer.info.addType(value, target)
+	}
+
+	return true
+}
+
+// Constant is untyped, it takes the type it's used with: 2, -2.5, 1 << 3
+func isConstant(expression parser.Expression) bool {
+	return ConstantValue(expression) != nil
+}
+
+func newOverflowError(value parser.Expression, target Type) *parser.Error {
+	msg := "Constant '" + printer.Snippet(value) + "' overflows " + target.String()
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newAssignError(variable *Variable, realType Type) *parser.Error {
+	message := "Cannot use type " + realType.String() + " in variable '" +
+		variable.Name + "' of type " + variable.Type.String()
 	return &parser.Error{Type: parser.AssignError, Message: message}
 }
 
-func newExpressionError(leftType string, rightType string) *parser.Error {
-	message := "Mismatched types: " + leftType + " and " + rightType
+func newExpressionError(leftType Type, rightType Type, expr parser.Expression) *parser.Error {
+	message := "Mismatched types: " + leftType.String() + " and " + rightType.String() +
+		" in '" + printer.Snippet(expr) + "'"
 	return &parser.Error{Type: parser.MismatchedTypesError, Message: message}
 }
 
-func newNonBoolError() *parser.Error {
-	msg := "Non-bool type used as condition"
+func newNonBoolError(condition parser.Expression) *parser.Error {
+	msg := "Non-bool type used as condition '" + printer.Snippet(condition) + "'"
 	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
 }
 
@@ -56,3 +129,286 @@ func newNotDefinedError(identifier parser.Identifier) *parser.Error {
 	msg := "Variable '" + identifier.Name + "' is not defined"
 	return &parser.Error{Type: parser.AssignError, Message: msg}
 }
+
+func newNonNameDefineError() *parser.Error {
+	msg := "Non-name on left side of ':='"
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newElementError(elementType Type, realType Type) *parser.Error {
+	message := "Cannot use type " + realType.String() + " as element of type " + elementType.String()
+	return &parser.Error{Type: parser.AssignError, Message: message}
+}
+
+func newNotIndexableError(realType Type, expr parser.Expression) *parser.Error {
+	msg := "Cannot index value of type " + realType.String() + " in '" + printer.Snippet(expr) + "'"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newNonIntegerIndexError(realType Type, expr parser.Expression) *parser.Error {
+	msg := "Non-integer index of type " + realType.String() + " in '" + printer.Snippet(expr) + "'"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newIndexOutOfRangeError(index int, length int) *parser.Error {
+	msg := "Index " + strconv.Itoa(index) + " is out of range for array of length " + strconv.Itoa(length)
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newUnknownTypeError(name string) *parser.Error {
+	msg := "Unknown type '" + name + "'"
+	return &parser.Error{Type: parser.TypeError, Message: msg}
+}
+
+func newArrayLengthError() *parser.Error {
+	msg := "Array length must be an integer constant"
+	return &parser.Error{Type: parser.TypeError, Message: msg}
+}
+
+func newNotCallableError(name string) *parser.Error {
+	msg := "Function '" + name + "' is not defined"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newNonFunctionCallError() *parser.Error {
+	msg := "Cannot call non-function expression"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newArgumentCountError(name string, expected int, real int) *parser.Error {
+	msg := "Wrong number of arguments in call to '" + name + "': expected " +
+		strconv.Itoa(expected) + ", got " + strconv.Itoa(real)
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newArgumentTypeError(name string, realType Type) *parser.Error {
+	msg := "Invalid argument of type " + realType.String() + " in call to '" + name + "'"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newFieldError(name string, fieldType Type, realType Type) *parser.Error {
+	msg := "Cannot use type " + realType.String() + " in field '" + name + "' of type " + fieldType.String()
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newNoFieldError(realType Type, name string) *parser.Error {
+	msg := "Type " + realType.String() + " has no field '" + name + "'"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newInvalidFieldNameError() *parser.Error {
+	msg := "Invalid field name in struct literal"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newDuplicateFieldError(name string) *parser.Error {
+	msg := "Field '" + name + "' is specified more than once"
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newInvalidLiteralTypeError(realType Type) *parser.Error {
+	msg := "Invalid type " + realType.String() + " for composite literal"
+	return &parser.Error{Type: parser.TypeError, Message: msg}
+}
+
+func newUnexpectedKeyError(realType Type) *parser.Error {
+	msg := "Unexpected key in literal of type " + realType.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newMixedStructLiteralError() *parser.Error {
+	msg := "Mixture of field:value and value elements in struct literal"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newStructLiteralCountError(realType Type) *parser.Error {
+	msg := "Wrong number of values in literal of type " + realType.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newTypeAlreadyDefinedError(name string) *parser.Error {
+	msg := "Type '" + name + "' is already defined"
+	return &parser.Error{Type: parser.TypeError, Message: msg}
+}
+
+func newRecursiveTypeError(name string) *parser.Error {
+	msg := "Invalid recursive type '" + name + "'"
+	return &parser.Error{Type: parser.TypeError, Message: msg}
+}
+
+func newInvalidMapKeyError(keyType Type) *parser.Error {
+	msg := "Invalid map key type " + keyType.String()
+	return &parser.Error{Type: parser.TypeError, Message: msg}
+}
+
+func newMapKeyError(keyType Type, realType Type) *parser.Error {
+	msg := "Cannot use type " + realType.String() + " as map key of type " + keyType.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newMissingKeyError(mapType Type) *parser.Error {
+	msg := "Missing key in literal of type " + mapType.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newDuplicateKeyError(key string) *parser.Error {
+	msg := "Duplicate key " + key + " in map literal"
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newAssignCountError(targets int, values int) *parser.Error {
+	msg := "Assignment mismatch: " + plural(targets, "variable") + " but " + plural(values, "value")
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newNoNewVariablesError() *parser.Error {
+	msg := "No new variables on left side of ':='"
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newVoidValueError() *parser.Error {
+	msg := "Call without result is used as value"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newUnusedResultError(name string) *parser.Error {
+	msg := "Result of '" + name + "' is not used"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newConversionError(from Type, to Type) *parser.Error {
+	msg := "Cannot convert type " + from.String() + " to " + to.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newConversionCountError(to Type, real int) *parser.Error {
+	msg := "Conversion to " + to.String() + " expects 1 argument, got " + strconv.Itoa(real)
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newTruncatedConstantError(value parser.Expression) *parser.Error {
+	msg := "Constant '" + printer.Snippet(value) + "' truncated to integer"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newUnsupportedPackageError(path string) *parser.Error {
+	msg := "Package '" + path + "' is not translatable to LWIQA"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newAlreadyImportedError(path string) *parser.Error {
+	msg := "Package '" + path + "' is already imported"
+	return &parser.Error{Type: parser.ImportError, Message: msg}
+}
+
+func newUnusedImportError(path string) *parser.Error {
+	msg := "Package '" + path + "' is imported but not used"
+	return &parser.Error{Type: parser.ImportError, Message: msg}
+}
+
+func newNotImportedError(name string) *parser.Error {
+	msg := "Package '" + name + "' is used but not imported"
+	return &parser.Error{Type: parser.ImportError, Message: msg}
+}
+
+func newPackageValueError(name string) *parser.Error {
+	msg := "Package '" + name + "' can only be used to call its functions"
+	return &parser.Error{Type: parser.ImportError, Message: msg}
+}
+
+func newNonConstantFormatError() *parser.Error {
+	msg := "Format of 'fmt.Printf' should be a string literal"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newFormatCountError(verbs int, args int) *parser.Error {
+	msg := "Format of 'fmt.Printf' has " + plural(verbs, "verb") + ", but " + plural(args, "argument") + " given"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newVerbError(verb byte, realType Type) *parser.Error {
+	msg := "Verb '%" + string(verb) + "' cannot format value of type " + realType.String()
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newVerbMissingError() *parser.Error {
+	msg := "Format of 'fmt.Printf' ends with '%' without verb"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newUnsupportedVerbError(verb string) *parser.Error {
+	msg := "Verb '" + verb + "' is not supported, only %d, %f, %s, %t and %v can be used"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newScanArgumentError() *parser.Error {
+	msg := "Arguments of 'fmt.Scan' should be addresses of variables: &a"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newNestedBranchError() *parser.Error {
+	msg := "Break inside if of switch case is not supported, it would leave only the if"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newAddressError() *parser.Error {
+	msg := "Pointers are not supported, '&' can only be used in arguments of 'fmt.Scan'"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newNotTranslatableError(name string) *parser.Error {
+	msg := "Function '" + name + "' is not translatable to LWIQA"
+	return &parser.Error{Type: parser.UnsupportedError, Message: msg}
+}
+
+func newBitwiseOperandError(operator string, realType Type) *parser.Error {
+	msg := "Operator '" + operator + "' is defined only for Integer, got " + realType.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newNegativeShiftError(count int64) *parser.Error {
+	msg := "Invalid negative shift count " + strconv.FormatInt(count, 10)
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newDivisionByZeroError(expr parser.Expression) *parser.Error {
+	msg := "Division by zero in '" + printer.Snippet(expr) + "'"
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newFunctionAlreadyDefinedError(name string) *parser.Error {
+	msg := "Function '" + name + "' is already defined"
+	return &parser.Error{Type: parser.AssignError, Message: msg}
+}
+
+func newMissingReturnError(name string) *parser.Error {
+	msg := "Missing return at the end of function '" + name + "'"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newMissingReturnValueError(name string) *parser.Error {
+	msg := "Missing return value in function '" + name + "'"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newUnexpectedReturnValueError(name string) *parser.Error {
+	msg := "Function '" + name + "' has no result, but returns a value"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newReturnTypeError(name string, expected Type, real Type) *parser.Error {
+	msg := "Cannot return type " + real.String() + " from function '" + name +
+		"' with result of type " + expected.String()
+	return &parser.Error{Type: parser.MismatchedTypesError, Message: msg}
+}
+
+func newMultipleValueError() *parser.Error {
+	msg := "Call with several results is used as single value"
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
+
+func newReturnCountError(name string, expected int, real int) *parser.Error {
+	msg := "Wrong number of return values in function '" + name + "': expected " +
+		strconv.Itoa(expected) + ", got " + strconv.Itoa(real)
+	return &parser.Error{Type: parser.CallError, Message: msg}
+}
@@ -0,0 +1,46 @@
+package translator
+
+import (
+	"../gofrontend"
+	"../lexer"
+	"../parser"
+	"sort"
+)
+
+// Front end reads the code into the syntax tree, syntax errors are in File.Errors.
+// Errors of types are returned by front ends which check them on their own.
+// The semantic analyzer runs after any front end, it gives types and variables to the generator.
+type frontend struct {
+	parse func(code string) (parser.File, parser.Errors)
+	// Tree with syntax errors is still analyzed, so semantic errors are found as well
+	recovers bool
+	// Types are checked by the front end, errors of the semantic analyzer are constructs
+	// which aren't translatable
+	checks bool
+}
+
+const defaultFrontend = "native"
+
+var frontends = map[string]frontend{
+	// Hand-written lexer and parser
+	"native": {parseNative, true, false},
+	// go/parser and go/types, their tree is lowered to the tree of the native parser
+	"go": {gofrontend.Parse, false, true},
+}
+
+func parseNative(code string) (parser.File, parser.Errors) {
+	return parser.NewParser(lexer.NewLexer(code).Tokenize()).Parse(), nil
+}
+
+// Names of all front ends in alphabetical order
+func Frontends() []string {
+	var names []string
+
+	for name := range frontends {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
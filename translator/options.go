@@ -0,0 +1,11 @@
+package translator
+
+import "../generator"
+
+// Options of the translation, zero value reads the code with the native front end
+// and optimizes it before it's generated with the default layout
+type Options struct {
+	Frontend       string // Front end which reads the code, see Frontends(), native if empty
+	NoOptimization bool   // Constant expressions and branches which are never taken are kept
+	Generator      generator.GeneratorOptions
+}
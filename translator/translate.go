@@ -1,27 +1,192 @@
 package translator
 
 import (
+	"../cfg"
 	"../generator"
 	"../lexer"
+	"../optimizer"
 	"../parser"
+	"../printer"
 	"../semantic"
+	"io"
+	"strings"
 )
 
-func Translate(code string) (genCode string) {
-	tokens := lexer.NewLexer(code).Tokenize()
-	ast := parser.NewParser(tokens).Parse()
-	_, semErr := semantic.NewAnalyzer(ast).Analyze()
-	parseErr := ast.Errors
+// Backend is one of generator.Backends(): lwiqa, pseudo
+func Translate(code string, backendName string) (genCode string) {
+	genCode, _ = TranslateWithOptions(code, backendName, Options{})
+	return
+}
 
-	if len(semErr) > 0 || len(parseErr) > 0 {
-		if len(parseErr) > 0 {
-			genCode = "Syntax errors:\n" + parseErr.String()
-		}
-		if len(semErr) > 0 {
-			genCode += "Semantic errors:\n" + semErr.String()
-		}
+// Source map is returned if it's set in options, it's nil if the code has errors
+func TranslateWithOptions(
+	code string,
+	backendName string,
+	options Options,
+) (genCode string, sourceMap *generator.SourceMap) {
+	var builder strings.Builder
+	sourceMap, _ = TranslateTo(&builder, code, backendName, options)
+
+	return builder.String(), sourceMap
+}
+
+// Generated code is written to out while it's generated, errors of the code are written the same way.
+// Error is returned only if out can't be written.
+func TranslateTo(
+	out io.Writer,
+	code string,
+	backendName string,
+	options Options,
+) (sourceMap *generator.SourceMap, err error) {
+	backend, status := generator.GetBackend(backendName)
+
+	if !status {
+		_, err = io.WriteString(out,
+			"Unknown backend '"+backendName+"', available: "+strings.Join(generator.Backends(), ", ")+"\n")
+		return
+	}
+
+	ast, analyzer, variables, errors := analyze(code, options)
+
+	if errors != "" {
+		_, err = io.WriteString(out, errors)
 	} else {
-		genCode = generator.NewGenerator(ast).Generate()
+		if !options.NoOptimization {
+			ast = optimizer.Optimize(ast)
+		}
+
+		gen := generator.NewGenerator(ast, variables, analyzer.TypeInfo(), analyzer.CallGraph(), backend, options.Generator)
+		err = gen.GenerateTo(out)
+		sourceMap = gen.SourceMap()
 	}
 	return
 }
+
+// Control-flow graphs of the functions in declaration order, they are built from the same code
+// which is translated, so optimization is applied unless it's disabled in options.
+// Errors of the code are returned the same way they are written by TranslateTo.
+func ControlFlowGraphs(code string, options Options) ([]*cfg.Graph, string) {
+	ast, _, _, errors := analyze(code, options)
+
+	if errors != "" {
+		return nil, errors
+	}
+
+	if !options.NoOptimization {
+		ast = optimizer.Optimize(ast)
+	}
+
+	return cfg.BuildAll(ast), ""
+}
+
+// Errors are empty if the code has no syntax and semantic errors.
+// Code is read by the front end of options, the native one if it's not set.
+func analyze(
+	code string,
+	options Options,
+) (ast parser.File, analyzer *semantic.Analyzer, variables semantic.Variables, errors string) {
+	name := options.Frontend
+
+	if name == "" {
+		name = defaultFrontend
+	}
+
+	frontend, status := frontends[name]
+
+	if !status {
+		errors = "Unknown front end '" + name + "', available: " + strings.Join(Frontends(), ", ") + "\n"
+		return
+	}
+
+	ast, checkErr := frontend.parse(code)
+	parseErr := ast.Errors
+
+	if len(parseErr) > 0 {
+		errors = "Syntax errors:\n" + parseErr.String()
+	}
+	if len(checkErr) > 0 {
+		errors += "Semantic errors:\n" + checkErr.String()
+	}
+	if errors != "" && !frontend.recovers {
+		return
+	}
+
+	analyzer = semantic.NewAnalyzer(ast)
+	variables, semErr := analyzer.Analyze()
+
+	if frontend.checks {
+		semErr = unsupported(semErr)
+	}
+
+	if len(semErr) > 0 {
+		errors += "Semantic errors:\n" + semErr.String()
+	}
+	return
+}
+
+// Code which is checked by the front end is valid Go,
+// so any error of the analyzer is a construct which can't be translated
+func unsupported(errors parser.Errors) parser.Errors {
+	for _, err := range errors {
+		err.Type = parser.UnsupportedError
+	}
+
+	return errors
+}
+
+// Syntax tree of the code which passes analysis, it isn't optimized so it's the code as it's written.
+// Errors of the code are returned the same way they are written by TranslateTo.
+func SyntaxTree(code string, options Options) (parser.File, string) {
+	ast, _, _, errors := analyze(code, options)
+	return ast, errors
+}
+
+// LWIQA code which can be run by the interpreter, errors of the code are returned
+// the same way they are written by TranslateTo.
+func LWIQAProgram(code string, options Options) (string, string) {
+	ast, analyzer, variables, errors := analyze(code, options)
+
+	if errors != "" {
+		return "", errors
+	}
+
+	if !options.NoOptimization {
+		ast = optimizer.Optimize(ast)
+	}
+
+	backend, _ := generator.GetBackend("lwiqa")
+	gen := generator.NewGenerator(ast, variables, analyzer.TypeInfo(), analyzer.CallGraph(), backend, options.Generator)
+
+	return gen.Generate(), ""
+}
+
+// Code written the same way as gofmt does it, only syntax of the code is checked.
+// Comments aren't in the syntax tree, so code with comments isn't formatted, they would be lost.
+// Formatted code is parsed again and it should give the same syntax tree,
+// otherwise it's returned with the error.
+func Format(code string) (string, string) {
+	lex := lexer.NewLexer(code)
+	tokens := lex.Tokenize()
+
+	if lex.HasComments() {
+		return "", "Code with comments can't be formatted, comments aren't kept\n"
+	}
+
+	ast := parser.NewParser(tokens).Parse()
+
+	if len(ast.Errors) > 0 {
+		return "", "Syntax errors:\n" + ast.Errors.String()
+	}
+
+	formatted, err := printer.File(ast)
+
+	if err != nil {
+		return formatted, "Formatted code can't be read: " + err.Error() + "\n"
+	}
+
+	if !parser.Equal(ast, parser.NewParser(lexer.NewLexer(formatted).Tokenize()).Parse()) {
+		return formatted, "Formatted code has another syntax tree\n"
+	}
+
+	return formatted, ""
+}
@@ -0,0 +1,187 @@
+package translator
+
+import (
+	"../generator"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Fixtures without syntax errors are formatted to the same syntax tree,
+// formatting them again doesn't change them
+func TestFormatFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "test*.notgo"))
+
+	if err != nil || len(files) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, file := range files {
+		code, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		formatted, errors := Format(string(code))
+
+		if strings.HasPrefix(errors, "Syntax errors") {
+			continue
+		} else if errors != "" {
+			t.Errorf("%s: %s", file, errors)
+			continue
+		}
+
+		if again, errors := Format(formatted); errors != "" || again != formatted {
+			t.Errorf("%s: formatted code changes when it's formatted again %s", file, errors)
+		}
+	}
+}
+
+func TestFormatRefusesComments(t *testing.T) {
+	for _, code := range []string{
+		"package main\n\n// Entry point\nfunc main() {\n}\n",
+		"package main\n\nfunc main() {\n\ta := 1 /* one */\n\t_ = a\n}\n",
+	} {
+		if formatted, errors := Format(code); errors == "" || formatted != "" {
+			t.Errorf("code with comments is formatted:\n%s", code)
+		}
+	}
+}
+
+// Untyped constants get the type of their context from go/types
+func TestGoFrontendConstants(t *testing.T) {
+	code := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tx := 1.5\n\tx = x*2 + -3\n\tfmt.Println(x)\n}\n"
+	translated, _ := TranslateWithOptions(code, "lwiqa", Options{Frontend: "go", NoOptimization: true})
+
+	if !strings.Contains(translated, "&x& * 2.0 + -3.0") {
+		t.Errorf("constants aren't floats:\n%s", translated)
+	}
+}
+
+// Go frontend checks types on its own, only constructs which aren't translatable are reported after it
+func TestGoFrontendUnsupported(t *testing.T) {
+	code := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\ta := 10\n\tfmt.Printf(\"%x\\n\", a)\n}\n"
+	_, errors := SyntaxTree(code, Options{Frontend: "go"})
+
+	if !strings.Contains(errors, "Verb '%x' is not supported") {
+		t.Errorf("unsupported verb isn't reported: %q", errors)
+	}
+}
+
+// Code which go/types accepts, but which can't be lowered, is rejected as unsupported
+func TestGoFrontendRejects(t *testing.T) {
+	header := "package main\n\nimport (\n\t\"fmt\"\n\t\"math\"\n)\n\nfunc two() (int, int) {\n\treturn 1, 2\n}\n\nfunc main() {\n"
+
+	for _, statement := range []string{
+		"u := uint8(200)\n\tfmt.Println(u, math.Pi)",
+		"r := rune(5)\n\tfmt.Println(r, math.Pi)",
+		"var i int64 = 3\n\tfmt.Println(i, math.Pi)",
+		"f := float32(1.5)\n\tfmt.Println(f, math.Pi)",
+		"f := math.Sqrt\n\tfmt.Println(f(4))",
+		"fmt.Println(two(), math.Pi)",
+	} {
+		if _, errors := SyntaxTree(header+"\t"+statement+"\n}\n", Options{Frontend: "go"}); !strings.Contains(errors, "\t") {
+			t.Errorf("%q isn't rejected", statement)
+		}
+	}
+}
+
+// Constant expressions are computed exactly by go/types, constants of packages are literals
+func TestGoFrontendConstantExpressions(t *testing.T) {
+	code := "package main\n\nimport (\n\t\"fmt\"\n\t\"math\"\n)\n\nfunc main() {\n\tfmt.Println(0.1+0.2, -math.Pi, 1 < 2)\n}\n"
+
+	for _, options := range []Options{{Frontend: "go"}, {Frontend: "go", NoOptimization: true}} {
+		translated, _ := TranslateWithOptions(code, "lwiqa", options)
+
+		if !strings.Contains(translated, "OUTPUTLN 0.3, -3.141592653589793, true\n") {
+			t.Errorf("constants aren't literals with %+v:\n%s", options, translated)
+		}
+	}
+}
+
+// Float constants are exact and they are integers where integers are expected
+func TestFloatConstants(t *testing.T) {
+	code := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tvar c int = 2.5 * 2\n\td := []int{2.0}\n\tf := 0.1 + 0.2\n\tfmt.Println(c, d[0], f)\n}\n"
+
+	for _, options := range []Options{{}, {NoOptimization: true}} {
+		translated, _ := TranslateWithOptions(code, "lwiqa", options)
+
+		for _, expected := range []string{" 5\n", " [2]\n", " 0.3\n"} {
+			if !strings.Contains(translated, expected) {
+				t.Errorf("%q isn't written with %+v:\n%s", expected, options, translated)
+			}
+		}
+	}
+}
+
+// Operands of different types are allowed only if one of them is a constant of the other type
+func TestMixedOperands(t *testing.T) {
+	header := "package main\n\nimport \"fmt\"\n\ntype Name string\n\ntype Age int\n\nfunc main() {\n\ti, f := 7, 1.5\n\tvar n Name = \"a\"\n\tvar a Age = 30\n"
+
+	for expr, valid := range map[string]bool{
+		"i / 2.0":    true,
+		"n == \"x\"": true,
+		"n + \"y\"":  true,
+		"a * 2":      true,
+		"i + f":      false,
+		"i < f":      false,
+		"a * i":      false,
+		"a + f":      false,
+		"int(5.0)":   true,
+		"int(5.0/2)": false,
+		"int(-2.5)":  false,
+	} {
+		code := header + "\tfmt.Println(i, f, n, a, " + expr + ")\n}\n"
+
+		if _, errors := SyntaxTree(code, Options{}); (errors == "") != valid {
+			t.Errorf("%s: valid %t, errors %q", expr, valid, errors)
+		}
+	}
+}
+
+// Break is a keyword of LWIQA, it's written in the case of other keywords
+func TestBranchKeyword(t *testing.T) {
+	code := "package main\n\nfunc main() {\n\tx := 2\n\tswitch x {\n\tcase 2:\n\t\tbreak\n\t}\n}\n"
+
+	if translated := Translate(code, "lwiqa"); !strings.Contains(translated, " BREAK\n") {
+		t.Errorf("break isn't written as BREAK:\n%s", translated)
+	}
+
+	options := Options{Generator: generator.GeneratorOptions{LowercaseKeywords: true}}
+
+	if translated, _ := TranslateWithOptions(code, "lwiqa", options); !strings.Contains(translated, " break\n") {
+		t.Errorf("break isn't written in lower case:\n%s", translated)
+	}
+}
+
+// Break inside if of case would leave only the IF in the translation, so it's rejected
+func TestNestedBreak(t *testing.T) {
+	code := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tx := 2\n\tswitch x {\n\tcase 2:\n\t\tif x > 1 {\n\t\t\tbreak\n\t\t}\n\t\tfmt.Print(\"b\")\n\t}\n}\n"
+
+	for _, frontend := range Frontends() {
+		if _, errors := SyntaxTree(code, Options{Frontend: frontend}); !strings.Contains(errors, "Break inside if") {
+			t.Errorf("break inside if isn't rejected by %s front end: %q", frontend, errors)
+		}
+	}
+}
+
+// Translation of test16 without optimization keeps every operator, it's compared with its golden file
+func TestOperatorsGolden(t *testing.T) {
+	code, err := ioutil.ReadFile(filepath.Join("..", "test16.notgo"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "test16.lwiqa"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if translated, _ := TranslateWithOptions(string(code), "lwiqa", Options{NoOptimization: true}); translated != string(golden) {
+		t.Errorf("translation of test16 differs from testdata/test16.lwiqa:\n%s", translated)
+	}
+}